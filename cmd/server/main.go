@@ -20,6 +20,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/cmd"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/donation"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
@@ -472,6 +473,34 @@ func main() {
 			cmd.WaitForCloudDeploy()
 			return
 		}
+		// Share the Postgres connection so donation sessions survive restarts.
+		if usePostgresStore {
+			donation.UsePostgresSessions(pgStoreInst.DB(), pgStoreSchema)
+		}
+		// Attach the durable usage store so statistics survive restarts.
+		if cfg.UsagePersistence.Enable {
+			var usageStore usage.Store
+			var errUsageStore error
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if usePostgresStore {
+				usageStore, errUsageStore = usage.NewPostgresStore(ctx, pgStoreInst.DB(), pgStoreSchema)
+			} else {
+				usageBase := writableBase
+				if usageBase == "" {
+					usageBase = filepath.Dir(configFilePath)
+				}
+				usageStore, errUsageStore = usage.NewFileStore(usage.ResolveStorePath(cfg.UsagePersistence.Path, usageBase))
+			}
+			if errUsageStore != nil {
+				log.Errorf("failed to initialize usage store: %v", errUsageStore)
+			} else if loaded, errLoad := usage.GetRequestStatistics().AttachStore(ctx, usageStore); errLoad != nil {
+				log.Errorf("failed to load persisted usage statistics: %v", errLoad)
+				_ = usageStore.Close()
+			} else {
+				log.Infof("usage persistence enabled, restored %d record(s)", loaded)
+			}
+			cancel()
+		}
 		// Start the main proxy service
 		managementasset.StartAutoUpdater(context.Background(), configFilePath)
 		cmd.StartService(cfg, configFilePath, password)
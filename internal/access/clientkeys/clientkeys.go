@@ -0,0 +1,53 @@
+// Package clientkeys issues client API keys, hashes them for storage in config.yaml and
+// tracks when each key was last used.
+package clientkeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// HashPrefix marks a hashed client key.
+	HashPrefix = "sha256:"
+	// SecretPrefix starts every generated client key.
+	SecretPrefix = "sk-cpa-"
+	// MetadataKeyID is the access metadata key carrying the client key identifier.
+	MetadataKeyID = "client_key_id"
+)
+
+// Hash returns the storage digest of a client key.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return HashPrefix + hex.EncodeToString(sum[:])
+}
+
+// NormalizeHash lower-cases a stored digest and adds the prefix when it is missing.
+func NormalizeHash(hash string) string {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if hash == "" || strings.HasPrefix(hash, HashPrefix) {
+		return hash
+	}
+	return HashPrefix + hash
+}
+
+// GenerateSecret returns a new random client key.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return SecretPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GenerateID returns a new client key identifier.
+func GenerateID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "ck_" + hex.EncodeToString(buf), nil
+}
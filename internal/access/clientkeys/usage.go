@@ -0,0 +1,145 @@
+package clientkeys
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	log "github.com/sirupsen/logrus"
+)
+
+// UsageFileName is the file, relative to the auth directory, holding last-used records.
+const UsageFileName = misc.ClientKeysUsageFileName
+
+// flushInterval bounds how often last-used records are written to disk.
+const flushInterval = time.Minute
+
+// LastUse records the most recent authenticated request of a client key.
+type LastUse struct {
+	At time.Time `json:"at"`
+	IP string    `json:"ip,omitempty"`
+}
+
+// Tracker keeps last-used records in memory and writes them to disk at most once per
+// flush interval, so authentication never waits on the filesystem.
+type Tracker struct {
+	mu        sync.Mutex
+	path      string
+	entries   map[string]LastUse
+	dirty     bool
+	flushing  bool
+	lastFlush time.Time
+}
+
+var defaultTracker = NewTracker()
+
+// DefaultTracker returns the tracker shared by the access middleware and management API.
+func DefaultTracker() *Tracker { return defaultTracker }
+
+// NewTracker constructs an empty tracker without persistence.
+func NewTracker() *Tracker {
+	return &Tracker{entries: make(map[string]LastUse)}
+}
+
+// SetPath enables persistence to path and loads the records already stored there.
+// Records touched before loading take precedence over stored ones.
+func (t *Tracker) SetPath(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.path == path {
+		return
+	}
+	t.path = path
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WithError(err).Warn("failed to read client key usage file")
+		}
+		return
+	}
+	var stored map[string]LastUse
+	if err = json.Unmarshal(data, &stored); err != nil {
+		log.WithError(err).Warn("failed to parse client key usage file")
+		return
+	}
+	for id, use := range stored {
+		if _, ok := t.entries[id]; !ok {
+			t.entries[id] = use
+		}
+	}
+}
+
+// Touch records a use of the key identified by id.
+func (t *Tracker) Touch(id, ip string) {
+	if id == "" {
+		return
+	}
+	now := time.Now().UTC()
+	t.mu.Lock()
+	t.entries[id] = LastUse{At: now, IP: ip}
+	t.dirty = true
+	flush := t.path != "" && !t.flushing && now.Sub(t.lastFlush) >= flushInterval
+	if flush {
+		t.flushing = true
+	}
+	t.mu.Unlock()
+	if flush {
+		go func() {
+			if err := t.Flush(); err != nil {
+				log.WithError(err).Warn("failed to write client key usage file")
+			}
+		}()
+	}
+}
+
+// Get returns the last use of the key identified by id.
+func (t *Tracker) Get(id string) (LastUse, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	use, ok := t.entries[id]
+	return use, ok
+}
+
+// Forget drops the record of a deleted key.
+func (t *Tracker) Forget(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.entries[id]; ok {
+		delete(t.entries, id)
+		t.dirty = true
+	}
+}
+
+// Flush writes pending records to disk.
+func (t *Tracker) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.flushing = false
+	if t.path == "" || !t.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(t.path), 0o700); err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, t.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	t.dirty = false
+	t.lastFlush = time.Now()
+	return nil
+}
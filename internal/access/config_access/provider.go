@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/access/clientkeys"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 )
@@ -20,11 +22,23 @@ func Register() {
 }
 
 type provider struct {
-	name string
-	keys map[string]struct{}
+	name       string
+	keys       map[string]struct{}
+	clientKeys map[string]clientKey // keyed by clientkeys.Hash of the secret
 }
 
-func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
+// clientKey is a structured client key resolved from the root configuration.
+type clientKey struct {
+	id string
+	// principal identifies the key to rate limits and usage without exposing the secret:
+	// the configured ID, or the key hash when no ID is set.
+	principal string
+	expiresAt time.Time
+	revokedAt time.Time
+	policy    *sdkaccess.ClientPolicy
+}
+
+func newProvider(cfg *sdkconfig.AccessProvider, root *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
 	name := cfg.Name
 	if name == "" {
 		name = sdkconfig.DefaultAccessProviderName
@@ -36,7 +50,45 @@ func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkacce
 		}
 		keys[key] = struct{}{}
 	}
-	return &provider{name: name, keys: keys}, nil
+	return &provider{name: name, keys: keys, clientKeys: buildClientKeys(root)}, nil
+}
+
+func buildClientKeys(root *sdkconfig.SDKConfig) map[string]clientKey {
+	if root == nil || len(root.ClientKeys) == 0 {
+		return nil
+	}
+	out := make(map[string]clientKey, len(root.ClientKeys))
+	for _, entry := range root.ClientKeys {
+		hash := clientkeys.NormalizeHash(entry.KeyHash)
+		if key := strings.TrimSpace(entry.Key); key != "" {
+			hash = clientkeys.Hash(key)
+		}
+		if hash == "" {
+			continue
+		}
+		id := strings.TrimSpace(entry.ID)
+		principal := id
+		if principal == "" {
+			principal = hash
+		}
+		if id == "" {
+			id = strings.TrimSpace(entry.Name)
+		}
+		out[hash] = clientKey{
+			id:        id,
+			principal: principal,
+			expiresAt: entry.ExpiresAt,
+			revokedAt: entry.RevokedAt,
+			policy: &sdkaccess.ClientPolicy{
+				Name:             strings.TrimSpace(entry.Name),
+				AllowedModels:    append([]string(nil), entry.AllowedModels...),
+				AllowedProviders: append([]string(nil), entry.AllowedProviders...),
+				AllowedPrefixes:  append([]string(nil), entry.AllowedPrefixes...),
+				Tags:             append([]string(nil), entry.Tags...),
+			},
+		}
+	}
+	return out
 }
 
 func (p *provider) Identifier() string {
@@ -50,7 +102,7 @@ func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.
 	if p == nil {
 		return nil, sdkaccess.ErrNotHandled
 	}
-	if len(p.keys) == 0 {
+	if len(p.keys) == 0 && len(p.clientKeys) == 0 {
 		return nil, sdkaccess.ErrNotHandled
 	}
 	authHeader := r.Header.Get("Authorization")
@@ -92,6 +144,25 @@ func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.
 				},
 			}, nil
 		}
+		if len(p.clientKeys) == 0 {
+			continue
+		}
+		if entry, ok := p.clientKeys[clientkeys.Hash(candidate.value)]; ok {
+			now := time.Now()
+			if (!entry.expiresAt.IsZero() && now.After(entry.expiresAt)) || (!entry.revokedAt.IsZero() && !now.Before(entry.revokedAt)) {
+				return nil, sdkaccess.ErrInvalidCredential
+			}
+			metadata := map[string]string{"source": candidate.source}
+			if entry.id != "" {
+				metadata[clientkeys.MetadataKeyID] = entry.id
+			}
+			sdkaccess.EncodeClientPolicy(metadata, entry.policy)
+			return &sdkaccess.Result{
+				Provider:  p.Identifier(),
+				Principal: entry.principal,
+				Metadata:  metadata,
+			}, nil
+		}
 	}
 
 	return nil, sdkaccess.ErrInvalidCredential
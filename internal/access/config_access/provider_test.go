@@ -0,0 +1,78 @@
+package configaccess
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/access/clientkeys"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestProviderAuthenticate_ClientKeys(t *testing.T) {
+	root := &sdkconfig.SDKConfig{
+		ClientKeys: []sdkconfig.ClientKey{
+			{
+				Key:              "team-key",
+				Name:             "team",
+				AllowedModels:    []string{"gemini-2.5-*"},
+				AllowedProviders: []string{"gemini-cli"},
+				AllowedPrefixes:  []string{""},
+			},
+			{Key: "old-key", ExpiresAt: time.Now().Add(-time.Hour)},
+			{ID: "ck_1", KeyHash: clientkeys.Hash("hashed-key")},
+			{ID: "ck_2", KeyHash: clientkeys.Hash("revoked-key"), RevokedAt: time.Now().Add(-time.Minute)},
+		},
+	}
+	p, err := newProvider(sdkconfig.MakeInlineAccessProvider(root), root)
+	if err != nil {
+		t.Fatalf("newProvider: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer team-key")
+	result, err := p.Authenticate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	policy := sdkaccess.ClientPolicyFromMetadata(result.Metadata)
+	if policy == nil || policy.Name != "team" {
+		t.Fatalf("policy = %+v, want name team", policy)
+	}
+	if !policy.AllowsModel("Gemini-2.5-Pro") || !policy.AllowsModel("gemini-2.5-flash-lite") || policy.AllowsModel("claude-sonnet-4") {
+		t.Fatalf("unexpected model decisions for %v", policy.AllowedModels)
+	}
+	if got := policy.FilterProviders([]string{"claude", "gemini-cli"}); len(got) != 1 || got[0] != "gemini-cli" {
+		t.Fatalf("FilterProviders = %v, want [gemini-cli]", got)
+	}
+	if !policy.AllowsPrefix("") || policy.AllowsPrefix("other") {
+		t.Fatalf("unexpected prefix decisions for %q", policy.AllowedPrefixes)
+	}
+
+	req.Header.Set("Authorization", "Bearer hashed-key")
+	result, err = p.Authenticate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Authenticate hashed key: %v", err)
+	}
+	if got := result.Metadata[clientkeys.MetadataKeyID]; got != "ck_1" {
+		t.Fatalf("client key id = %q, want ck_1", got)
+	}
+	if result.Principal != "ck_1" {
+		t.Fatalf("principal = %q, want the key ID instead of the secret", result.Principal)
+	}
+
+	req.Header.Set("Authorization", "Bearer team-key")
+	if result, err = p.Authenticate(context.Background(), req); err != nil || result.Principal != clientkeys.Hash("team-key") {
+		t.Fatalf("principal without ID = %v (%v), want the key hash", result, err)
+	}
+
+	for _, key := range []string{"old-key", "revoked-key"} {
+		req.Header.Set("Authorization", "Bearer "+key)
+		if _, err = p.Authenticate(context.Background(), req); !errors.Is(err, sdkaccess.ErrInvalidCredential) {
+			t.Fatalf("%s error = %v, want ErrInvalidCredential", key, err)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Package ratelimit enforces per-client-API-key request rates, concurrent stream caps and
+// token and cost budgets. Consumption is charged from usage records emitted by the runtime,
+// so budgets apply to what upstream providers actually reported.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+// WildcardKey selects the policy applied to keys without a dedicated entry.
+const WildcardKey = "*"
+
+// rateWindow is the sliding window used for requests-per-minute accounting.
+const rateWindow = time.Minute
+
+// streamRetryAfter is the hint returned when the concurrent stream cap is reached.
+const streamRetryAfter = time.Second
+
+var defaultLimiter = NewLimiter()
+
+func init() {
+	coreusage.RegisterPlugin(defaultLimiter)
+}
+
+// Default returns the shared limiter used by the access middleware and stream handlers.
+func Default() *Limiter { return defaultLimiter }
+
+// LimitError reports a rejected request. It satisfies the StatusCode/Headers conventions
+// used by the API handlers so it renders as a 429 with a Retry-After header.
+type LimitError struct {
+	// Reason is a human readable description of the exceeded limit.
+	Reason string
+	// RetryAfter is the earliest time the request may succeed.
+	RetryAfter time.Duration
+}
+
+// Error implements error.
+func (e *LimitError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+// StatusCode returns the HTTP status for the rejection.
+func (e *LimitError) StatusCode() int { return http.StatusTooManyRequests }
+
+// Headers returns the Retry-After header for the rejection.
+func (e *LimitError) Headers() http.Header {
+	if e == nil {
+		return nil
+	}
+	h := make(http.Header)
+	h.Set("Retry-After", strconv.Itoa(e.RetryAfterSeconds()))
+	return h
+}
+
+// RetryAfterSeconds returns RetryAfter rounded up to whole seconds (minimum 1).
+func (e *LimitError) RetryAfterSeconds() int {
+	if e == nil || e.RetryAfter <= 0 {
+		return 1
+	}
+	secs := int(math.Ceil(e.RetryAfter.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
+
+// Limiter tracks per-key consumption against configured policies.
+type Limiter struct {
+	mu       sync.Mutex
+	policies map[string]config.APIKeyLimit
+	states   map[string]*keyState
+	now      func() time.Time
+}
+
+type keyState struct {
+	requests []time.Time
+	streams  int
+
+	day         string
+	dailyTokens int64
+	dailyCost   float64
+
+	month         string
+	monthlyTokens int64
+	monthlyCost   float64
+}
+
+// NewLimiter constructs a limiter without policies; every request is allowed until SetPolicies is called.
+func NewLimiter() *Limiter {
+	return &Limiter{
+		policies: make(map[string]config.APIKeyLimit),
+		states:   make(map[string]*keyState),
+		now:      time.Now,
+	}
+}
+
+// SetPolicies replaces the active policies. Consumption already recorded is preserved.
+func (l *Limiter) SetPolicies(limits []config.APIKeyLimit) {
+	if l == nil {
+		return
+	}
+	policies := make(map[string]config.APIKeyLimit, len(limits))
+	for _, limit := range limits {
+		key := strings.TrimSpace(limit.APIKey)
+		if key == "" {
+			continue
+		}
+		limit.APIKey = key
+		limit.RequestsPerMinute = max(limit.RequestsPerMinute, 0)
+		limit.MaxConcurrentStreams = max(limit.MaxConcurrentStreams, 0)
+		limit.DailyTokenBudget = max(limit.DailyTokenBudget, 0)
+		limit.MonthlyTokenBudget = max(limit.MonthlyTokenBudget, 0)
+		limit.DailyCostBudget = max(limit.DailyCostBudget, 0)
+		limit.MonthlyCostBudget = max(limit.MonthlyCostBudget, 0)
+		policies[key] = limit
+	}
+	l.mu.Lock()
+	l.policies = policies
+	l.mu.Unlock()
+}
+
+// policyFor returns the policy for key. Callers must hold l.mu.
+func (l *Limiter) policyFor(key string) (config.APIKeyLimit, bool) {
+	if policy, ok := l.policies[key]; ok {
+		return policy, true
+	}
+	policy, ok := l.policies[WildcardKey]
+	return policy, ok
+}
+
+// stateFor returns the consumption state for key, rolling budget periods forward. Callers must hold l.mu.
+func (l *Limiter) stateFor(key string, now time.Time) *keyState {
+	st, ok := l.states[key]
+	if !ok {
+		st = &keyState{}
+		l.states[key] = st
+	}
+	utc := now.UTC()
+	if day := utc.Format("2006-01-02"); st.day != day {
+		st.day = day
+		st.dailyTokens = 0
+		st.dailyCost = 0
+	}
+	if month := utc.Format("2006-01"); st.month != month {
+		st.month = month
+		st.monthlyTokens = 0
+		st.monthlyCost = 0
+	}
+	return st
+}
+
+// Allow checks the request rate, token budgets and cost budgets for key and records the request when accepted.
+func (l *Limiter) Allow(key string) *LimitError {
+	if l == nil {
+		return nil
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	policy, ok := l.policyFor(key)
+	if !ok {
+		return nil
+	}
+	now := l.now()
+	st := l.stateFor(key, now)
+
+	if policy.DailyTokenBudget > 0 && st.dailyTokens >= policy.DailyTokenBudget {
+		return &LimitError{
+			Reason:     fmt.Sprintf("daily token budget of %d tokens exhausted for this API key", policy.DailyTokenBudget),
+			RetryAfter: nextDay(now).Sub(now),
+		}
+	}
+	if policy.MonthlyTokenBudget > 0 && st.monthlyTokens >= policy.MonthlyTokenBudget {
+		return &LimitError{
+			Reason:     fmt.Sprintf("monthly token budget of %d tokens exhausted for this API key", policy.MonthlyTokenBudget),
+			RetryAfter: nextMonth(now).Sub(now),
+		}
+	}
+	if policy.DailyCostBudget > 0 && st.dailyCost >= policy.DailyCostBudget {
+		return &LimitError{
+			Reason:     fmt.Sprintf("daily cost budget of $%.2f exhausted for this API key", policy.DailyCostBudget),
+			RetryAfter: nextDay(now).Sub(now),
+		}
+	}
+	if policy.MonthlyCostBudget > 0 && st.monthlyCost >= policy.MonthlyCostBudget {
+		return &LimitError{
+			Reason:     fmt.Sprintf("monthly cost budget of $%.2f exhausted for this API key", policy.MonthlyCostBudget),
+			RetryAfter: nextMonth(now).Sub(now),
+		}
+	}
+
+	if policy.RequestsPerMinute > 0 {
+		cutoff := now.Add(-rateWindow)
+		kept := st.requests[:0]
+		for _, ts := range st.requests {
+			if ts.After(cutoff) {
+				kept = append(kept, ts)
+			}
+		}
+		st.requests = kept
+		if len(st.requests) >= policy.RequestsPerMinute {
+			return &LimitError{
+				Reason:     fmt.Sprintf("rate limit of %d requests per minute exceeded for this API key", policy.RequestsPerMinute),
+				RetryAfter: st.requests[0].Add(rateWindow).Sub(now),
+			}
+		}
+		st.requests = append(st.requests, now)
+	}
+	return nil
+}
+
+// AcquireStream reserves a concurrent stream slot for key. The returned release function
+// must be called once the stream finishes; it is safe to call more than once.
+func (l *Limiter) AcquireStream(key string) (func(), *LimitError) {
+	noop := func() {}
+	if l == nil {
+		return noop, nil
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return noop, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	policy, ok := l.policyFor(key)
+	if !ok || policy.MaxConcurrentStreams <= 0 {
+		return noop, nil
+	}
+	st := l.stateFor(key, l.now())
+	if st.streams >= policy.MaxConcurrentStreams {
+		return noop, &LimitError{
+			Reason:     fmt.Sprintf("concurrent stream limit of %d reached for this API key", policy.MaxConcurrentStreams),
+			RetryAfter: streamRetryAfter,
+		}
+	}
+	st.streams++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			if st.streams > 0 {
+				st.streams--
+			}
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// Charge adds consumed tokens to the budgets of key.
+func (l *Limiter) Charge(key string, tokens int64) {
+	if l == nil || tokens <= 0 {
+		return
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.policyFor(key); !ok {
+		return
+	}
+	st := l.stateFor(key, l.now())
+	st.dailyTokens += tokens
+	st.monthlyTokens += tokens
+}
+
+// ChargeCost adds spend in USD to the cost budgets of key.
+func (l *Limiter) ChargeCost(key string, cost float64) {
+	if l == nil || cost <= 0 {
+		return
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.policyFor(key); !ok {
+		return
+	}
+	st := l.stateFor(key, l.now())
+	st.dailyCost += cost
+	st.monthlyCost += cost
+}
+
+// HandleUsage implements coreusage.Plugin, charging reported tokens and their priced cost to the client key.
+func (l *Limiter) HandleUsage(_ context.Context, record coreusage.Record) {
+	total := record.Detail.TotalTokens
+	if total == 0 {
+		total = record.Detail.InputTokens + record.Detail.OutputTokens + record.Detail.ReasoningTokens
+	}
+	l.Charge(record.APIKey, total)
+	l.ChargeCost(record.APIKey, usage.CostOf(record))
+}
+
+func nextDay(now time.Time) time.Time {
+	utc := now.UTC()
+	return time.Date(utc.Year(), utc.Month(), utc.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+func nextMonth(now time.Time) time.Time {
+	utc := now.UTC()
+	return time.Date(utc.Year(), utc.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
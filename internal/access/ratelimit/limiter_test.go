@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func newTestLimiter(now *time.Time, limits ...config.APIKeyLimit) *Limiter {
+	l := NewLimiter()
+	l.now = func() time.Time { return *now }
+	l.SetPolicies(limits)
+	return l
+}
+
+func TestLimiterRequestsPerMinute(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newTestLimiter(&now, config.APIKeyLimit{APIKey: "k1", RequestsPerMinute: 2})
+
+	for i := 0; i < 2; i++ {
+		if err := l.Allow("k1"); err != nil {
+			t.Fatalf("request %d rejected: %v", i, err)
+		}
+	}
+	err := l.Allow("k1")
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected LimitError, got %v", err)
+	}
+	if limitErr.StatusCode() != 429 {
+		t.Fatalf("status = %d, want 429", limitErr.StatusCode())
+	}
+	if got := limitErr.Headers().Get("Retry-After"); got != "60" {
+		t.Fatalf("Retry-After = %q, want 60", got)
+	}
+
+	now = now.Add(61 * time.Second)
+	if err := l.Allow("k1"); err != nil {
+		t.Fatalf("request after window rejected: %v", err)
+	}
+	if err := l.Allow("other"); err != nil {
+		t.Fatalf("key without policy rejected: %v", err)
+	}
+}
+
+func TestLimiterWildcardPolicyAndStreams(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newTestLimiter(&now, config.APIKeyLimit{APIKey: WildcardKey, MaxConcurrentStreams: 1})
+
+	release, err := l.AcquireStream("any")
+	if err != nil {
+		t.Fatalf("first stream rejected: %v", err)
+	}
+	if _, err := l.AcquireStream("any"); err == nil {
+		t.Fatalf("expected second concurrent stream to be rejected")
+	}
+	release()
+	release()
+	if _, err := l.AcquireStream("any"); err != nil {
+		t.Fatalf("stream after release rejected: %v", err)
+	}
+}
+
+func TestLimiterTokenBudgets(t *testing.T) {
+	now := time.Date(2025, 1, 15, 23, 0, 0, 0, time.UTC)
+	l := newTestLimiter(&now, config.APIKeyLimit{APIKey: "k1", DailyTokenBudget: 100, MonthlyTokenBudget: 150})
+
+	l.HandleUsage(context.Background(), coreusage.Record{APIKey: "k1", Detail: coreusage.Detail{TotalTokens: 100}})
+	err := l.Allow("k1")
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected daily budget rejection, got %v", err)
+	}
+	if limitErr.RetryAfter != time.Hour {
+		t.Fatalf("RetryAfter = %v, want 1h", limitErr.RetryAfter)
+	}
+
+	// The daily budget resets at midnight UTC while the monthly total carries over.
+	now = now.Add(2 * time.Hour)
+	if err := l.Allow("k1"); err != nil {
+		t.Fatalf("request on next day rejected: %v", err)
+	}
+	l.Charge("k1", 60)
+	err = l.Allow("k1")
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected monthly budget rejection, got %v", err)
+	}
+	if want := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC).Sub(now); limitErr.RetryAfter != want {
+		t.Fatalf("RetryAfter = %v, want %v", limitErr.RetryAfter, want)
+	}
+}
+
+func TestLimiterCostBudgets(t *testing.T) {
+	usage.SetPricing([]config.ModelPrice{{Model: "gpt-*", Input: 2, Output: 8}})
+	defer usage.SetPricing(nil)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newTestLimiter(&now, config.APIKeyLimit{APIKey: "k1", DailyCostBudget: 1})
+
+	// 250k input + 50k output = 0.5 + 0.4 USD
+	l.HandleUsage(context.Background(), coreusage.Record{APIKey: "k1", Model: "gpt-5", Detail: coreusage.Detail{InputTokens: 250_000, OutputTokens: 50_000}})
+	if err := l.Allow("k1"); err != nil {
+		t.Fatalf("request under cost budget rejected: %v", err)
+	}
+	l.HandleUsage(context.Background(), coreusage.Record{APIKey: "k1", Model: "gpt-5", Detail: coreusage.Detail{InputTokens: 50_000}})
+	if err := l.Allow("k1"); err == nil {
+		t.Fatalf("request over daily cost budget allowed")
+	}
+	// Unpriced models do not consume the cost budget.
+	now = now.Add(24 * time.Hour)
+	l.HandleUsage(context.Background(), coreusage.Record{APIKey: "k1", Model: "unpriced", Detail: coreusage.Detail{InputTokens: 10_000_000}})
+	if err := l.Allow("k1"); err != nil {
+		t.Fatalf("request after daily reset rejected: %v", err)
+	}
+}
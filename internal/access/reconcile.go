@@ -81,11 +81,11 @@ func ReconcileProviders(oldCfg, newCfg *config.Config, existing []sdkaccess.Prov
 	}
 
 	if len(result) == 0 {
-		if inline := sdkConfig.MakeInlineAPIKeyProvider(newCfg.APIKeys); inline != nil {
+		if inline := sdkConfig.MakeInlineAccessProvider(&newCfg.SDKConfig); inline != nil {
 			key := providerIdentifier(inline)
 			if key != "" {
 				if oldCfgProvider, ok := oldCfgMap[key]; ok {
-					if providerConfigEqual(oldCfgProvider, inline) {
+					if providerConfigEqual(oldCfgProvider, inline) && clientKeysEqual(oldCfg, newCfg) {
 						if existingProvider, okExisting := existingMap[key]; okExisting {
 							result = append(result, existingProvider)
 							finalIDs[key] = struct{}{}
@@ -176,8 +176,8 @@ func accessProviderMap(cfg *config.Config) map[string]*sdkConfig.AccessProvider
 		}
 		result[key] = providerCfg
 	}
-	if len(result) == 0 && len(cfg.APIKeys) > 0 {
-		if provider := sdkConfig.MakeInlineAPIKeyProvider(cfg.APIKeys); provider != nil {
+	if len(result) == 0 {
+		if provider := sdkConfig.MakeInlineAccessProvider(&cfg.SDKConfig); provider != nil {
 			if key := providerIdentifier(provider); key != "" {
 				result[key] = provider
 			}
@@ -197,8 +197,8 @@ func collectProviderEntries(cfg *config.Config) []*sdkConfig.AccessProvider {
 			entries = append(entries, providerCfg)
 		}
 	}
-	if len(entries) == 0 && len(cfg.APIKeys) > 0 {
-		if inline := sdkConfig.MakeInlineAPIKeyProvider(cfg.APIKeys); inline != nil {
+	if len(entries) == 0 {
+		if inline := sdkConfig.MakeInlineAccessProvider(&cfg.SDKConfig); inline != nil {
 			entries = append(entries, inline)
 		}
 	}
@@ -244,6 +244,14 @@ func providerConfigEqual(a, b *sdkConfig.AccessProvider) bool {
 	return true
 }
 
+// clientKeysEqual reports whether the client keys read by the inline provider are unchanged.
+func clientKeysEqual(oldCfg, newCfg *config.Config) bool {
+	if oldCfg == nil || newCfg == nil {
+		return oldCfg == newCfg
+	}
+	return reflect.DeepEqual(oldCfg.ClientKeys, newCfg.ClientKeys)
+}
+
 func stringSetEqual(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -0,0 +1,211 @@
+package management
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	auditLogFileName     = "management-audit.jsonl"
+	defaultAuditLogLimit = 100
+	maxAuditLogLimit     = 1000
+)
+
+// AuditEntry records one mutating management call.
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Actor    string    `json:"actor"`
+	ClientIP string    `json:"client_ip"`
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	Target   string    `json:"target,omitempty"`
+	Status   int       `json:"status"`
+	Changes  []string  `json:"changes,omitempty"`
+}
+
+// auditLog appends entries to a JSON-lines file. It never rewrites existing lines.
+type auditLog struct {
+	mu sync.Mutex
+}
+
+func (a *auditLog) append(path string, entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// auditFilter selects audit entries.
+type auditFilter struct {
+	Actor      string
+	PathPrefix string
+	Since      time.Time
+	Limit      int
+}
+
+func (f auditFilter) match(entry AuditEntry) bool {
+	if f.Actor != "" && entry.Actor != f.Actor {
+		return false
+	}
+	if f.PathPrefix != "" && !strings.HasPrefix(entry.Path, f.PathPrefix) {
+		return false
+	}
+	return f.Since.IsZero() || !entry.Time.Before(f.Since)
+}
+
+// query returns the newest entries matching filter, newest first.
+func (a *auditLog) query(path string, filter auditFilter) ([]AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []AuditEntry{}, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var matched []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		if filter.match(entry) {
+			matched = append(matched, entry)
+			if len(matched) > filter.Limit {
+				matched = matched[1:]
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]AuditEntry, 0, len(matched))
+	for i := len(matched) - 1; i >= 0; i-- {
+		out = append(out, matched[i])
+	}
+	return out, nil
+}
+
+func (h *Handler) auditLogPath() string {
+	return filepath.Join(h.logDirectory(), auditLogFileName)
+}
+
+// snapshotConfig deep-copies the current config so changes made by a handler can be summarised.
+func (h *Handler) snapshotConfig() *config.Config {
+	if h.cfg == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(h.cfg)
+	if err != nil {
+		return nil
+	}
+	var snapshot *config.Config
+	if yaml.Unmarshal(data, &snapshot) != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// recordAudit appends an audit entry for a finished mutating request.
+func (h *Handler) recordAudit(c *gin.Context, p *principal, before *config.Config) {
+	entry := AuditEntry{
+		Time:     time.Now().UTC(),
+		ClientIP: c.ClientIP(),
+		Method:   c.Request.Method,
+		Path:     c.Request.URL.Path,
+		Status:   c.Writer.Status(),
+	}
+	if p != nil {
+		entry.Actor = p.Name
+	}
+	for _, key := range []string{"name", "id", "provider"} {
+		if v := strings.TrimSpace(c.Query(key)); v != "" {
+			entry.Target = key + "=" + v
+			break
+		}
+	}
+	if before != nil && h.cfg != nil {
+		entry.Changes = diff.BuildConfigChangeDetails(before, h.cfg)
+	}
+	if err := h.audit.append(h.auditLogPath(), entry); err != nil {
+		log.WithError(err).Warn("failed to write management audit log")
+	}
+}
+
+// GetAuditLog returns recorded management changes, newest first.
+// Query parameters: limit (default 100, max 1000), actor, path (prefix) and since (RFC3339 or unix seconds).
+func (h *Handler) GetAuditLog(c *gin.Context) {
+	filter := auditFilter{
+		Actor:      strings.TrimSpace(c.Query("actor")),
+		PathPrefix: strings.TrimSpace(c.Query("path")),
+		Limit:      defaultAuditLogLimit,
+	}
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		filter.Limit = min(limit, maxAuditLogLimit)
+	}
+	if raw := strings.TrimSpace(c.Query("since")); raw != "" {
+		since, err := parseAuditTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.Since = since
+	}
+
+	entries, err := h.audit.query(h.auditLogPath(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to read audit log: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+func parseAuditTime(raw string) (time.Time, error) {
+	if ts, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(ts, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since: use RFC3339 or unix seconds")
+	}
+	return t, nil
+}
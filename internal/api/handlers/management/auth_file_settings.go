@@ -0,0 +1,189 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// authFileSettingsPatch lists the operator settings that can be changed on a credential.
+// Omitted fields are left untouched; empty strings and zero numbers reset a setting.
+type authFileSettingsPatch struct {
+	Disabled *bool   `json:"disabled"`
+	Label    *string `json:"label"`
+	Prefix   *string `json:"prefix"`
+	ProxyURL *string `json:"proxy_url"`
+	Priority *int    `json:"priority"`
+	Weight   *int    `json:"weight"`
+}
+
+// PatchAuthFile updates operator settings of a single credential. The credential is
+// identified by "id" or by file "name"; settings go in "value". Changes are written to
+// the active token store and applied to the running auth manager immediately.
+func (h *Handler) PatchAuthFile(c *gin.Context) {
+	var body struct {
+		ID    string                 `json:"id"`
+		Name  string                 `json:"name"`
+		Value *authFileSettingsPatch `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Value == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	h.patchAuthFile(c, body.ID, body.Name, body.Value)
+}
+
+// PatchAuthFileStatus enables or disables a single credential.
+func (h *Handler) PatchAuthFileStatus(c *gin.Context) {
+	var body struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Disabled *bool  `json:"disabled"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Disabled == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	h.patchAuthFile(c, body.ID, body.Name, &authFileSettingsPatch{Disabled: body.Disabled})
+}
+
+func (h *Handler) patchAuthFile(c *gin.Context, id, name string, patch *authFileSettingsPatch) {
+	if !checkDonationAdminAccess(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	auth, ok := h.findAuthFile(id, name)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "auth file not found"})
+		return
+	}
+	if isRuntimeOnlyAuth(auth) || auth.Metadata == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "runtime-only credentials cannot be modified"})
+		return
+	}
+	if err := applyAuthFileSettings(auth, patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	auth.UpdatedAt = time.Now()
+
+	// Persist the metadata itself: a token storage attached during login would otherwise
+	// take precedence and drop the new settings.
+	record := auth.Clone()
+	record.Storage = nil
+	ctx := c.Request.Context()
+	if _, err := h.saveTokenRecord(ctx, record); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save auth file: %v", err)})
+		return
+	}
+	updated, err := h.authManager.Update(ctx, auth)
+	if err != nil || updated == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to update auth: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "file": h.buildAuthFileEntry(updated)})
+}
+
+// findAuthFile resolves a credential by auth ID or by file name under the auth directory.
+func (h *Handler) findAuthFile(id, name string) (*coreauth.Auth, bool) {
+	if id = strings.TrimSpace(id); id != "" {
+		return h.authManager.GetByID(id)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, string(os.PathSeparator)) || h.cfg == nil {
+		return nil, false
+	}
+	full := filepath.Join(h.cfg.AuthDir, filepath.Base(name))
+	if !filepath.IsAbs(full) {
+		if abs, errAbs := filepath.Abs(full); errAbs == nil {
+			full = abs
+		}
+	}
+	if auth, ok := h.authManager.GetByID(h.authIDForPath(full)); ok {
+		return auth, true
+	}
+	for _, auth := range h.authManager.List() {
+		if strings.TrimSpace(authAttribute(auth, "path")) == full || auth.FileName == filepath.Base(name) {
+			return auth, true
+		}
+	}
+	return nil, false
+}
+
+// applyAuthFileSettings validates patch and applies it to both the auth fields and the
+// metadata persisted in the auth file.
+func applyAuthFileSettings(auth *coreauth.Auth, patch *authFileSettingsPatch) error {
+	prefix := ""
+	if patch.Prefix != nil {
+		prefix = strings.Trim(strings.TrimSpace(*patch.Prefix), "/")
+		if strings.Contains(prefix, "/") {
+			return fmt.Errorf("prefix must not contain '/'")
+		}
+	}
+	if patch.Weight != nil && *patch.Weight < 0 {
+		return fmt.Errorf("weight must not be negative")
+	}
+
+	if patch.Prefix != nil {
+		auth.Prefix = prefix
+		setAuthMetadata(auth, "prefix", prefix, prefix == "")
+	}
+
+	if patch.Disabled != nil {
+		disabled := *patch.Disabled
+		setAuthMetadata(auth, "disabled", true, !disabled)
+		// The primary of a multi-project Gemini credential always stays disabled; only its
+		// virtual project auths serve traffic and they follow the persisted flag on reload.
+		if authAttribute(auth, "gemini_virtual_primary") != "true" {
+			auth.Disabled = disabled
+			if disabled {
+				auth.Status = coreauth.StatusDisabled
+				auth.StatusMessage = "disabled via management API"
+			} else {
+				auth.Status = coreauth.StatusActive
+				auth.StatusMessage = ""
+			}
+		}
+	}
+	if patch.Label != nil {
+		label := strings.TrimSpace(*patch.Label)
+		setAuthMetadata(auth, "label", label, label == "")
+		if label == "" {
+			label = authEmail(auth)
+			if label == "" {
+				label = auth.Provider
+			}
+		}
+		auth.Label = label
+	}
+	if patch.ProxyURL != nil {
+		proxyURL := strings.TrimSpace(*patch.ProxyURL)
+		auth.ProxyURL = proxyURL
+		setAuthMetadata(auth, "proxy_url", proxyURL, proxyURL == "")
+	}
+	if patch.Priority != nil {
+		setAuthMetadata(auth, "priority", *patch.Priority, *patch.Priority == 0)
+	}
+	if patch.Weight != nil {
+		setAuthMetadata(auth, "weight", *patch.Weight, *patch.Weight == 0)
+	}
+	return nil
+}
+
+func setAuthMetadata(auth *coreauth.Auth, key string, value any, remove bool) {
+	if remove {
+		delete(auth.Metadata, key)
+		return
+	}
+	auth.Metadata[key] = value
+}
@@ -357,7 +357,7 @@ func (h *Handler) listAuthFilesFromDisk(c *gin.Context) {
 			continue
 		}
 		name := e.Name()
-		if !strings.HasSuffix(strings.ToLower(name), ".json") {
+		if !strings.HasSuffix(strings.ToLower(name), ".json") || misc.IsAuthDirStateFile(name) {
 			continue
 		}
 		if info, errInfo := e.Info(); errInfo == nil {
@@ -391,6 +391,9 @@ func (h *Handler) buildAuthFileEntry(auth *coreauth.Auth) gin.H {
 	if path == "" && !runtimeOnly {
 		return nil
 	}
+	if path != "" && misc.IsAuthDirStateFile(path) {
+		return nil
+	}
 	name := strings.TrimSpace(auth.FileName)
 	if name == "" {
 		name = auth.ID
@@ -409,6 +412,29 @@ func (h *Handler) buildAuthFileEntry(auth *coreauth.Auth) gin.H {
 		"runtime_only":   runtimeOnly,
 		"source":         "memory",
 		"size":           int64(0),
+		"priority":       coreauth.AuthPriority(auth),
+		"weight":         coreauth.AuthWeight(auth),
+	}
+	if auth.Prefix != "" {
+		entry["prefix"] = auth.Prefix
+	}
+	if auth.ProxyURL != "" {
+		entry["proxy_url"] = auth.ProxyURL
+	}
+	if probe, ok := h.authManager.LastProbe(auth.ID); ok {
+		lastProbe := gin.H{
+			"at":         probe.At,
+			"model":      probe.Model,
+			"success":    probe.Success,
+			"latency_ms": probe.Latency.Milliseconds(),
+		}
+		if probe.StatusCode != 0 {
+			lastProbe["status_code"] = probe.StatusCode
+		}
+		if probe.Error != "" {
+			lastProbe["error"] = probe.Error
+		}
+		entry["last_probe"] = lastProbe
 	}
 	if email := authEmail(auth); email != "" {
 		entry["email"] = email
@@ -537,6 +563,10 @@ func (h *Handler) DownloadAuthFile(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "name must end with .json"})
 		return
 	}
+	if misc.IsAuthDirStateFile(name) {
+		c.JSON(400, gin.H{"error": "name is not an auth file"})
+		return
+	}
 	full := filepath.Join(h.cfg.AuthDir, name)
 	data, err := os.ReadFile(full)
 	if err != nil {
@@ -569,6 +599,10 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 			c.JSON(400, gin.H{"error": "file must be .json"})
 			return
 		}
+		if misc.IsAuthDirStateFile(name) {
+			c.JSON(400, gin.H{"error": "name is reserved"})
+			return
+		}
 		dst := filepath.Join(h.cfg.AuthDir, name)
 		if !filepath.IsAbs(dst) {
 			if abs, errAbs := filepath.Abs(dst); errAbs == nil {
@@ -600,6 +634,10 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "name must end with .json"})
 		return
 	}
+	if misc.IsAuthDirStateFile(name) {
+		c.JSON(400, gin.H{"error": "name is reserved"})
+		return
+	}
 	data, err := io.ReadAll(c.Request.Body)
 	if err != nil {
 		c.JSON(400, gin.H{"error": "failed to read body"})
@@ -646,7 +684,7 @@ func (h *Handler) DeleteAuthFile(c *gin.Context) {
 				continue
 			}
 			name := e.Name()
-			if !strings.HasSuffix(strings.ToLower(name), ".json") {
+			if !strings.HasSuffix(strings.ToLower(name), ".json") || misc.IsAuthDirStateFile(name) {
 				continue
 			}
 			full := filepath.Join(h.cfg.AuthDir, name)
@@ -672,6 +710,10 @@ func (h *Handler) DeleteAuthFile(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "invalid name"})
 		return
 	}
+	if misc.IsAuthDirStateFile(name) {
+		c.JSON(400, gin.H{"error": "name is not an auth file"})
+		return
+	}
 	full := filepath.Join(h.cfg.AuthDir, filepath.Base(name))
 	if !filepath.IsAbs(full) {
 		if abs, errAbs := filepath.Abs(full); errAbs == nil {
@@ -759,6 +801,7 @@ func (h *Handler) registerAuthFromFile(ctx context.Context, path string, data []
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
+	auth.ApplyMetadataSettings()
 	if hasLastRefresh {
 		auth.LastRefreshedAt = lastRefresh
 	}
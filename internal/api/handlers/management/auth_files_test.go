@@ -0,0 +1,62 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestAuthFilesHandlersSkipStateFiles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	stateFiles := []string{misc.DonationBindingsFileName, misc.DonationLedgerFileName, misc.DonationSessionsFileName, misc.ClientKeysUsageFileName}
+	for _, name := range append([]string{"codex-user.json"}, stateFiles...) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(`{"type":"codex"}`), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	h := &Handler{cfg: &config.Config{AuthDir: dir}, tokenStore: sdkAuth.NewFileTokenStore()}
+	serve := func(method, target string, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(method, target, nil)
+		handle(c)
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/auth-files", h.ListAuthFiles)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "codex-user.json") {
+		t.Fatalf("list = %d %s", rec.Code, rec.Body.String())
+	}
+	for _, name := range stateFiles {
+		if strings.Contains(rec.Body.String(), name) {
+			t.Fatalf("list exposes %s: %s", name, rec.Body.String())
+		}
+		if rec = serve(http.MethodGet, "/auth-files/download?name="+name, h.DownloadAuthFile); rec.Code != http.StatusBadRequest {
+			t.Fatalf("download %s = %d, want 400", name, rec.Code)
+		}
+	}
+
+	h.authManager = coreauth.NewManager(nil, nil, nil)
+	if rec = serve(http.MethodDelete, "/auth-files?all=true", h.DeleteAuthFile); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"deleted":1`) {
+		t.Fatalf("delete all = %d %s", rec.Code, rec.Body.String())
+	}
+	for _, name := range stateFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("delete all removed %s: %v", name, err)
+		}
+		if rec = serve(http.MethodDelete, "/auth-files?name="+name, h.DeleteAuthFile); rec.Code != http.StatusBadRequest {
+			t.Fatalf("delete %s = %d, want 400", name, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/access/clientkeys"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// clientKeyView is the management representation of a client key. Secrets and hashes are
+// never returned, except the plaintext key right after it was created or rotated.
+type clientKeyView struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name,omitempty"`
+	Key              string     `json:"key,omitempty"`
+	Hashed           bool       `json:"hashed"`
+	AllowedModels    []string   `json:"allowed-models,omitempty"`
+	AllowedProviders []string   `json:"allowed-providers,omitempty"`
+	AllowedPrefixes  []string   `json:"allowed-prefixes,omitempty"`
+	Tags             []string   `json:"tags,omitempty"`
+	CreatedAt        *time.Time `json:"created-at,omitempty"`
+	ExpiresAt        *time.Time `json:"expires-at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked-at,omitempty"`
+	LastUsedAt       *time.Time `json:"last-used-at,omitempty"`
+	LastUsedIP       string     `json:"last-used-ip,omitempty"`
+	Status           string     `json:"status"`
+}
+
+// clientKeySettings lists the client key fields that can be set on create and update.
+// Omitted fields are left untouched; "clear-expiry" removes the expiry.
+type clientKeySettings struct {
+	Name             *string    `json:"name"`
+	AllowedModels    *[]string  `json:"allowed-models"`
+	AllowedProviders *[]string  `json:"allowed-providers"`
+	AllowedPrefixes  *[]string  `json:"allowed-prefixes"`
+	Tags             *[]string  `json:"tags"`
+	ExpiresAt        *time.Time `json:"expires-at"`
+	ClearExpiry      bool       `json:"clear-expiry"`
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func clientKeyID(entry config.ClientKey) string {
+	if id := strings.TrimSpace(entry.ID); id != "" {
+		return id
+	}
+	return strings.TrimSpace(entry.Name)
+}
+
+func buildClientKeyView(entry config.ClientKey, now time.Time) clientKeyView {
+	view := clientKeyView{
+		ID:               clientKeyID(entry),
+		Name:             entry.Name,
+		Hashed:           strings.TrimSpace(entry.Key) == "",
+		AllowedModels:    entry.AllowedModels,
+		AllowedProviders: entry.AllowedProviders,
+		AllowedPrefixes:  entry.AllowedPrefixes,
+		Tags:             entry.Tags,
+		CreatedAt:        optionalTime(entry.CreatedAt),
+		ExpiresAt:        optionalTime(entry.ExpiresAt),
+		RevokedAt:        optionalTime(entry.RevokedAt),
+		Status:           "active",
+	}
+	switch {
+	case !entry.RevokedAt.IsZero() && !now.Before(entry.RevokedAt):
+		view.Status = "revoked"
+	case !entry.ExpiresAt.IsZero() && now.After(entry.ExpiresAt):
+		view.Status = "expired"
+	}
+	if use, ok := clientkeys.DefaultTracker().Get(view.ID); ok {
+		view.LastUsedAt = optionalTime(use.At)
+		view.LastUsedIP = use.IP
+	}
+	return view
+}
+
+// findClientKey returns the index of the client key with the given id, or -1.
+func (h *Handler) findClientKey(id string) int {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return -1
+	}
+	for i := range h.cfg.ClientKeys {
+		if clientKeyID(h.cfg.ClientKeys[i]) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func applyClientKeySettings(entry *config.ClientKey, settings clientKeySettings) {
+	if settings.Name != nil {
+		entry.Name = strings.TrimSpace(*settings.Name)
+	}
+	if settings.AllowedModels != nil {
+		entry.AllowedModels = normalizeClientKeyList(*settings.AllowedModels, false)
+	}
+	if settings.AllowedProviders != nil {
+		entry.AllowedProviders = normalizeClientKeyList(*settings.AllowedProviders, false)
+	}
+	if settings.AllowedPrefixes != nil {
+		// "" is meaningful here: it selects unprefixed credentials.
+		entry.AllowedPrefixes = normalizeClientKeyList(*settings.AllowedPrefixes, true)
+	}
+	if settings.Tags != nil {
+		entry.Tags = normalizeClientKeyList(*settings.Tags, false)
+	}
+	if settings.ExpiresAt != nil {
+		entry.ExpiresAt = settings.ExpiresAt.UTC()
+	}
+	if settings.ClearExpiry {
+		entry.ExpiresAt = time.Time{}
+	}
+}
+
+func normalizeClientKeyList(values []string, keepEmpty bool) []string {
+	out := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" && !keepEmpty {
+			continue
+		}
+		if _, dup := seen[v]; dup {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// GetClientKeys lists client keys with their status and last use.
+func (h *Handler) GetClientKeys(c *gin.Context) {
+	now := time.Now()
+	views := make([]clientKeyView, 0, len(h.cfg.ClientKeys))
+	for _, entry := range h.cfg.ClientKeys {
+		views = append(views, buildClientKeyView(entry, now))
+	}
+	c.JSON(http.StatusOK, gin.H{"client-keys": views})
+}
+
+// CreateClientKey issues a new client key. The generated secret is returned once and only
+// its hash is stored. Supplying "key" imports an existing secret instead, which also
+// converts a plaintext api-keys entry into a hashed one.
+func (h *Handler) CreateClientKey(c *gin.Context) {
+	var body struct {
+		clientKeySettings
+		Key string `json:"key"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	secret := strings.TrimSpace(body.Key)
+	generated := secret == ""
+	if generated {
+		var err error
+		if secret, err = clientkeys.GenerateSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to generate key: %v", err)})
+			return
+		}
+	}
+	hash := clientkeys.Hash(secret)
+	for _, existing := range h.cfg.ClientKeys {
+		if clientkeys.NormalizeHash(existing.KeyHash) == hash || (existing.Key != "" && clientkeys.Hash(existing.Key) == hash) {
+			c.JSON(http.StatusConflict, gin.H{"error": "client key already exists"})
+			return
+		}
+	}
+	id, err := clientkeys.GenerateID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to generate id: %v", err)})
+		return
+	}
+	entry := config.ClientKey{ID: id, KeyHash: hash, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	applyClientKeySettings(&entry, body.clientKeySettings)
+	if entry.Name != "" && h.findClientKey(entry.Name) >= 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "client key name already in use"})
+		return
+	}
+	h.cfg.ClientKeys = append(h.cfg.ClientKeys, entry)
+	if !generated && len(h.cfg.APIKeys) > 0 {
+		h.cfg.APIKeys = removeString(h.cfg.APIKeys, secret)
+		h.cfg.Access.Providers = nil
+	}
+	if err = h.saveConfig(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save config: %v", err)})
+		return
+	}
+	view := buildClientKeyView(entry, time.Now())
+	if generated {
+		view.Key = secret
+	}
+	c.JSON(http.StatusCreated, view)
+}
+
+// PatchClientKey updates the name, restrictions, tags or expiry of a client key.
+func (h *Handler) PatchClientKey(c *gin.Context) {
+	var body struct {
+		ID    string             `json:"id"`
+		Value *clientKeySettings `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Value == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	idx := h.findClientKey(body.ID)
+	if idx < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client key not found"})
+		return
+	}
+	entry := h.cfg.ClientKeys[idx]
+	applyClientKeySettings(&entry, *body.Value)
+	if entry.ID == "" && entry.Name != h.cfg.ClientKeys[idx].Name {
+		// Keys without an ID are addressed by name; give them a stable ID before renaming.
+		id, err := clientkeys.GenerateID()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to generate id: %v", err)})
+			return
+		}
+		entry.ID = id
+	}
+	h.cfg.ClientKeys[idx] = entry
+	if err := h.saveConfig(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save config: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, buildClientKeyView(entry, time.Now()))
+}
+
+// RotateClientKey replaces the secret of a client key, keeping its settings. The new secret
+// is returned once; the previous one stops working immediately.
+func (h *Handler) RotateClientKey(c *gin.Context) {
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	idx := h.findClientKey(body.ID)
+	if idx < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client key not found"})
+		return
+	}
+	secret, err := clientkeys.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to generate key: %v", err)})
+		return
+	}
+	entry := h.cfg.ClientKeys[idx]
+	if entry.ID == "" {
+		if entry.ID, err = clientkeys.GenerateID(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to generate id: %v", err)})
+			return
+		}
+	}
+	entry.Key = ""
+	entry.KeyHash = clientkeys.Hash(secret)
+	entry.RevokedAt = time.Time{}
+	h.cfg.ClientKeys[idx] = entry
+	if err = h.saveConfig(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save config: %v", err)})
+		return
+	}
+	view := buildClientKeyView(entry, time.Now())
+	view.Key = secret
+	c.JSON(http.StatusOK, view)
+}
+
+// RevokeClientKey disables a client key while keeping its record for auditing.
+func (h *Handler) RevokeClientKey(c *gin.Context) {
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	idx := h.findClientKey(body.ID)
+	if idx < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client key not found"})
+		return
+	}
+	entry := &h.cfg.ClientKeys[idx]
+	if entry.RevokedAt.IsZero() {
+		entry.RevokedAt = time.Now().UTC().Truncate(time.Second)
+	}
+	if err := h.saveConfig(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save config: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, buildClientKeyView(*entry, time.Now()))
+}
+
+// DeleteClientKey removes a client key record entirely. Query parameter: id.
+func (h *Handler) DeleteClientKey(c *gin.Context) {
+	id := strings.TrimSpace(c.Query("id"))
+	idx := h.findClientKey(id)
+	if idx < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client key not found"})
+		return
+	}
+	h.cfg.ClientKeys = append(h.cfg.ClientKeys[:idx], h.cfg.ClientKeys[idx+1:]...)
+	clientkeys.DefaultTracker().Forget(id)
+	h.persist(c)
+}
+
+func removeString(values []string, target string) []string {
+	out := values[:0]
+	for _, v := range values {
+		if v != target {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -40,12 +41,15 @@ type Handler struct {
 	allowRemoteOverride bool
 	envSecret           string
 	logDir              string
+	tokenMatches        sync.Map // successful bcrypt token comparisons
+	audit               auditLog
 }
 
 // NewHandler creates a new management handler instance.
 func NewHandler(cfg *config.Config, configFilePath string, manager *coreauth.Manager) *Handler {
 	envSecret, _ := os.LookupEnv("MANAGEMENT_PASSWORD")
 	envSecret = strings.TrimSpace(envSecret)
+	warnPlaintextTokens(cfg)
 
 	return &Handler{
 		cfg:                 cfg,
@@ -65,7 +69,12 @@ func NewHandlerWithoutConfigFilePath(cfg *config.Config, manager *coreauth.Manag
 }
 
 // SetConfig updates the in-memory config reference when the server hot-reloads.
-func (h *Handler) SetConfig(cfg *config.Config) { h.cfg = cfg }
+func (h *Handler) SetConfig(cfg *config.Config) {
+	if cfg != nil && (h.cfg == nil || !reflect.DeepEqual(h.cfg.RemoteManagement.Tokens, cfg.RemoteManagement.Tokens)) {
+		warnPlaintextTokens(cfg)
+	}
+	h.cfg = cfg
+}
 
 // SetAuthManager updates the auth manager reference used by management endpoints.
 func (h *Handler) SetAuthManager(manager *coreauth.Manager) { h.authManager = manager }
@@ -92,6 +101,8 @@ func (h *Handler) SetLogDirectory(dir string) {
 // Middleware enforces access control for management endpoints.
 // All requests (local and remote) require a valid management key.
 // Additionally, remote access requires allow-remote-management=true.
+// Named management tokens are limited to their scopes, and every mutating call
+// is recorded in the audit log.
 func (h *Handler) Middleware() gin.HandlerFunc {
 	const maxFailures = 5
 	const banDuration = 30 * time.Minute
@@ -107,10 +118,12 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 		var (
 			allowRemote bool
 			secretHash  string
+			tokens      []config.ManagementToken
 		)
 		if cfg != nil {
 			allowRemote = cfg.RemoteManagement.AllowRemote
 			secretHash = cfg.RemoteManagement.SecretKey
+			tokens = cfg.RemoteManagement.Tokens
 		}
 		if h.allowRemoteOverride {
 			allowRemote = true
@@ -156,7 +169,7 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 				h.attemptsMu.Unlock()
 			}
 		}
-		if secretHash == "" && envSecret == "" {
+		if secretHash == "" && envSecret == "" && len(tokens) == 0 {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "remote management key not set"})
 			return
 		}
@@ -186,26 +199,22 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 		if localClient {
 			if lp := h.localPassword; lp != "" {
 				if subtle.ConstantTimeCompare([]byte(provided), []byte(lp)) == 1 {
-					c.Next()
+					h.serveAuthorized(c, adminPrincipal("local-password"))
 					return
 				}
 			}
 		}
 
-		if envSecret != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(envSecret)) == 1 {
-			if !localClient {
-				h.attemptsMu.Lock()
-				if ai := h.failedAttempts[clientIP]; ai != nil {
-					ai.count = 0
-					ai.blockedUntil = time.Time{}
-				}
-				h.attemptsMu.Unlock()
-			}
-			c.Next()
-			return
+		var p *principal
+		switch {
+		case envSecret != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(envSecret)) == 1:
+			p = adminPrincipal("management-password")
+		case secretHash != "" && bcrypt.CompareHashAndPassword([]byte(secretHash), []byte(provided)) == nil:
+			p = adminPrincipal("secret-key")
+		default:
+			p = h.matchManagementToken(tokens, provided)
 		}
-
-		if secretHash == "" || bcrypt.CompareHashAndPassword([]byte(secretHash), []byte(provided)) != nil {
+		if p == nil {
 			if !localClient {
 				fail()
 			}
@@ -222,16 +231,31 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 			h.attemptsMu.Unlock()
 		}
 
+		h.serveAuthorized(c, p)
+	}
+}
+
+// serveAuthorized checks the principal's scopes, runs the handler chain and audits mutating calls.
+func (h *Handler) serveAuthorized(c *gin.Context, p *principal) {
+	if !p.allows(c.Request.Method, c.Request.URL.Path) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient scope", "token": p.Name})
+		return
+	}
+	c.Set(principalContextKey, p)
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
 		c.Next()
+		return
 	}
+	before := h.snapshotConfig()
+	c.Next()
+	h.recordAudit(c, p, before)
 }
 
 // persist saves the current in-memory config to disk.
 func (h *Handler) persist(c *gin.Context) bool {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	// Preserve comments when writing
-	if err := config.SaveConfigPreserveComments(h.configFilePath, h.cfg); err != nil {
+	if err := h.saveConfig(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save config: %v", err)})
 		return false
 	}
@@ -239,6 +263,13 @@ func (h *Handler) persist(c *gin.Context) bool {
 	return true
 }
 
+// saveConfig writes the current config to disk, preserving comments.
+func (h *Handler) saveConfig() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return config.SaveConfigPreserveComments(h.configFilePath, h.cfg)
+}
+
 // Helper methods for simple types
 func (h *Handler) updateBoolField(c *gin.Context, set func(bool)) {
 	var body struct {
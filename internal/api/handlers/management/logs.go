@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 )
 
@@ -297,6 +298,82 @@ func (h *Handler) GetRequestLogByID(c *gin.Context) {
 	c.FileAttachment(fullPath, matchedFile)
 }
 
+// SearchRequestLogs lists indexed request logs, newest first. Query parameters: "from" and "to"
+// (RFC3339 or YYYY-MM-DD, "to" exclusive), "client-key", "model", "status" (a code such as 429 or
+// a class such as 4xx), "q" for a case-insensitive free-text match on the log contents, and
+// "offset"/"limit" for pagination (default 50, at most 500). Files are fetched through
+// /request-log-by-id/:id.
+func (h *Handler) SearchRequestLogs(c *gin.Context) {
+	if h == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "handler unavailable"})
+		return
+	}
+	if h.cfg == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "configuration unavailable"})
+		return
+	}
+
+	dir := h.logDirectory()
+	if strings.TrimSpace(dir) == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "log directory not configured"})
+		return
+	}
+
+	from, errFrom := parseUsageTime(c.Query("from"))
+	if errFrom != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	to, errTo := parseUsageTime(c.Query("to"))
+	if errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+	offset, errOffset := parseOffset(c.Query("offset"))
+	if errOffset != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+		return
+	}
+	limit, errLimit := parseLimit(c.Query("limit"))
+	if errLimit != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid limit: %v", errLimit)})
+		return
+	}
+
+	query := logging.RequestLogQuery{
+		From:      from,
+		To:        to,
+		ClientKey: strings.TrimSpace(c.Query("client-key")),
+		Model:     strings.TrimSpace(c.Query("model")),
+		Status:    c.Query("status"),
+		Text:      c.Query("q"),
+		Offset:    offset,
+		Limit:     limit,
+	}
+	entries, total, err := logging.RequestLogIndexFor(dir).Search(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"total":   total,
+		"offset":  offset,
+		"entries": entries,
+	})
+}
+
+func parseOffset(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("must be a non-negative integer")
+	}
+	return value, nil
+}
+
 // DownloadRequestErrorLog downloads a specific error request log file by name.
 func (h *Handler) DownloadRequestErrorLog(c *gin.Context) {
 	if h == nil {
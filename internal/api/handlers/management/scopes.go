@@ -0,0 +1,199 @@
+package management
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Management token scopes.
+const (
+	// ScopeReadOnly allows GET requests except secret-bearing reads, raw token downloads and
+	// login flows.
+	ScopeReadOnly = "read-only"
+	// ScopeUsageOnly allows reading usage statistics.
+	ScopeUsageOnly = "usage-only"
+	// ScopeCredentialsAdmin allows managing auth files and provider logins.
+	ScopeCredentialsAdmin = "credentials-admin"
+	// ScopeConfigAdmin allows changing configuration, except replacing config.yaml wholesale and
+	// reading back secrets.
+	ScopeConfigAdmin = "config-admin"
+	// ScopeAdmin allows everything; the secret key and local password carry it implicitly.
+	ScopeAdmin = "admin"
+)
+
+const (
+	managementPathPrefix = "/v0/management"
+	principalContextKey  = "managementPrincipal"
+)
+
+// principal is the identity behind an authenticated management request.
+type principal struct {
+	Name   string
+	Scopes []string
+}
+
+// adminPrincipal returns a principal with full access.
+func adminPrincipal(name string) *principal {
+	return &principal{Name: name, Scopes: []string{ScopeAdmin}}
+}
+
+// managementArea classifies a management path.
+type managementArea int
+
+const (
+	areaConfig managementArea = iota
+	areaUsage
+	areaCredentials
+)
+
+func classifyManagementPath(path string) managementArea {
+	path = strings.TrimPrefix(path, managementPathPrefix)
+	switch {
+	case path == "/usage" || strings.HasPrefix(path, "/usage/"):
+		return areaUsage
+	case path == "/auth-files" || strings.HasPrefix(path, "/auth-files/"),
+		strings.HasPrefix(path, "/vertex/"),
+		strings.HasSuffix(path, "-auth-url"),
+		path == "/oauth-callback",
+		path == "/get-auth-status",
+		path == "/api-call":
+		return areaCredentials
+	default:
+		return areaConfig
+	}
+}
+
+// secretReadPaths return management keys, client keys or upstream credentials verbatim, so
+// only admin principals may read them; any scoped token could otherwise escalate to admin.
+var secretReadPaths = map[string]struct{}{
+	"/config":                    {},
+	"/config.yaml":               {},
+	"/api-keys":                  {},
+	"/client-keys":               {},
+	"/gemini-api-key":            {},
+	"/claude-api-key":            {},
+	"/codex-api-key":             {},
+	"/openai-compatibility":      {},
+	"/ampcode":                   {},
+	"/ampcode/upstream-api-key":  {},
+	"/ampcode/upstream-api-keys": {},
+}
+
+// secretRead reports whether a read request returns stored secrets.
+func secretRead(path string) bool {
+	_, ok := secretReadPaths[strings.TrimPrefix(path, managementPathPrefix)]
+	return ok
+}
+
+// sensitiveRead reports whether a read request exposes secrets or starts a login flow.
+func sensitiveRead(path string) bool {
+	trimmed := strings.TrimPrefix(path, managementPathPrefix)
+	return trimmed == "/auth-files/download" || strings.HasSuffix(trimmed, "-auth-url") || secretRead(path)
+}
+
+// allows reports whether the principal may perform method on the management path.
+func (p *principal) allows(method, path string) bool {
+	if p == nil {
+		return false
+	}
+	read := method == http.MethodGet || method == http.MethodHead
+	area := classifyManagementPath(path)
+	for _, scope := range p.Scopes {
+		switch strings.ToLower(strings.TrimSpace(scope)) {
+		case ScopeAdmin, "*":
+			return true
+		case ScopeReadOnly:
+			if read && !sensitiveRead(path) {
+				return true
+			}
+		case ScopeUsageOnly:
+			if read && area == areaUsage {
+				return true
+			}
+		case ScopeCredentialsAdmin:
+			if area == areaCredentials {
+				return true
+			}
+		case ScopeConfigAdmin:
+			replacesConfig := !read && strings.TrimPrefix(path, managementPathPrefix) == "/config.yaml"
+			if (area == areaConfig || area == areaUsage) && !replacesConfig && !(read && secretRead(path)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchManagementToken returns the configured token matching provided, if any. Successful
+// bcrypt comparisons are cached so hashed tokens stay cheap on subsequent requests.
+func (h *Handler) matchManagementToken(tokens []config.ManagementToken, provided string) *principal {
+	if len(tokens) == 0 || provided == "" {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(provided))
+	digest := hex.EncodeToString(sum[:])
+	for _, token := range tokens {
+		key := strings.TrimSpace(token.Key)
+		if key == "" {
+			continue
+		}
+		matched := false
+		if isBcryptHash(key) {
+			cacheKey := digest + "\x00" + key
+			if _, ok := h.tokenMatches.Load(cacheKey); ok {
+				matched = true
+			} else if bcrypt.CompareHashAndPassword([]byte(key), []byte(provided)) == nil {
+				h.tokenMatches.Store(cacheKey, struct{}{})
+				matched = true
+			}
+		} else {
+			matched = subtle.ConstantTimeCompare([]byte(key), []byte(provided)) == 1
+		}
+		if matched {
+			name := strings.TrimSpace(token.Name)
+			if name == "" {
+				name = "token:" + digest[:8]
+			}
+			return &principal{Name: name, Scopes: append([]string(nil), token.Scopes...)}
+		}
+	}
+	return nil
+}
+
+// warnPlaintextTokens logs every management token whose key is stored in plaintext. Unlike
+// secret-key, token keys are not rewritten to bcrypt on load.
+func warnPlaintextTokens(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	for _, token := range cfg.RemoteManagement.Tokens {
+		if key := strings.TrimSpace(token.Key); key != "" && !isBcryptHash(key) {
+			log.Warnf("management token %q stores its key in plaintext; replace it with a bcrypt hash", token.Name)
+		}
+	}
+}
+
+func isBcryptHash(s string) bool {
+	return len(s) > 4 && (s[:4] == "$2a$" || s[:4] == "$2b$" || s[:4] == "$2y$")
+}
+
+// currentPrincipal returns the principal stored by the middleware, if any.
+func currentPrincipal(c *gin.Context) *principal {
+	if c == nil {
+		return nil
+	}
+	if v, ok := c.Get(principalContextKey); ok {
+		if p, okP := v.(*principal); okP {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package management
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPrincipalAllows(t *testing.T) {
+	cases := []struct {
+		scope  string
+		method string
+		path   string
+		want   bool
+	}{
+		{ScopeReadOnly, http.MethodGet, "/v0/management/debug", true},
+		{ScopeReadOnly, http.MethodGet, "/v0/management/config", false},
+		{ScopeReadOnly, http.MethodGet, "/v0/management/config.yaml", false},
+		{ScopeReadOnly, http.MethodGet, "/v0/management/claude-api-key", false},
+		{ScopeReadOnly, http.MethodGet, "/v0/management/auth-files/download", false},
+		{ScopeReadOnly, http.MethodGet, "/v0/management/codex-auth-url", false},
+		{ScopeReadOnly, http.MethodPut, "/v0/management/debug", false},
+		{ScopeUsageOnly, http.MethodGet, "/v0/management/usage/export", true},
+		{ScopeUsageOnly, http.MethodGet, "/v0/management/config", false},
+		{ScopeCredentialsAdmin, http.MethodPatch, "/v0/management/auth-files", true},
+		{ScopeCredentialsAdmin, http.MethodPut, "/v0/management/debug", false},
+		{ScopeConfigAdmin, http.MethodPut, "/v0/management/debug", true},
+		{ScopeConfigAdmin, http.MethodPut, "/v0/management/config.yaml", false},
+		{ScopeConfigAdmin, http.MethodGet, "/v0/management/config", false},
+		{ScopeConfigAdmin, http.MethodGet, "/v0/management/client-keys", false},
+		{ScopeConfigAdmin, http.MethodPatch, "/v0/management/client-keys", true},
+		{ScopeAdmin, http.MethodGet, "/v0/management/config", true},
+		{ScopeConfigAdmin, http.MethodDelete, "/v0/management/auth-files", false},
+		{ScopeAdmin, http.MethodPut, "/v0/management/config.yaml", true},
+	}
+	for _, tc := range cases {
+		p := &principal{Name: "t", Scopes: []string{tc.scope}}
+		if got := p.allows(tc.method, tc.path); got != tc.want {
+			t.Fatalf("%s %s %s: allows() = %t, want %t", tc.scope, tc.method, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestMatchManagementToken(t *testing.T) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte("hashed-secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+	tokens := []config.ManagementToken{
+		{Name: "dashboard", Key: "plain-secret", Scopes: []string{ScopeReadOnly}},
+		{Name: "ops", Key: string(hashed), Scopes: []string{ScopeCredentialsAdmin}},
+	}
+	h := &Handler{}
+
+	if p := h.matchManagementToken(tokens, "plain-secret"); p == nil || p.Name != "dashboard" {
+		t.Fatalf("plaintext token matched %+v, want dashboard", p)
+	}
+	for i := 0; i < 2; i++ {
+		if p := h.matchManagementToken(tokens, "hashed-secret"); p == nil || p.Name != "ops" {
+			t.Fatalf("hashed token matched %+v, want ops", p)
+		}
+	}
+	if p := h.matchManagementToken(tokens, "wrong"); p != nil {
+		t.Fatalf("unknown token matched %+v", p)
+	}
+}
+
+func TestAuditLogQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), auditLogFileName)
+	var audit auditLog
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, actor := range []string{"ops", "dashboard", "ops", "ops"} {
+		entry := AuditEntry{Time: base.Add(time.Duration(i) * time.Minute), Actor: actor, Method: http.MethodPut, Path: "/v0/management/debug", Status: 200}
+		if err := audit.append(path, entry); err != nil {
+			t.Fatalf("append() error = %v", err)
+		}
+	}
+
+	entries, err := audit.query(path, auditFilter{Actor: "ops", Since: base.Add(time.Minute), Limit: 1})
+	if err != nil {
+		t.Fatalf("query() error = %v", err)
+	}
+	if len(entries) != 1 || !entries[0].Time.Equal(base.Add(3*time.Minute)) {
+		t.Fatalf("query() = %+v, want only the newest ops entry", entries)
+	}
+}
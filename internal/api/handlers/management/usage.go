@@ -2,7 +2,9 @@ package management
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -20,11 +22,33 @@ type usageImportPayload struct {
 	Usage   usage.StatisticsSnapshot `json:"usage"`
 }
 
-// GetUsageStatistics returns the in-memory request statistics snapshot.
+// GetUsageStatistics returns the request statistics snapshot.
+// Optional "from" and "to" query parameters (RFC3339 or YYYY-MM-DD, "to" exclusive) restrict the
+// snapshot to a time range and are served from the durable usage store when one is attached.
 func (h *Handler) GetUsageStatistics(c *gin.Context) {
+	from, errFrom := parseUsageTime(c.Query("from"))
+	if errFrom != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	to, errTo := parseUsageTime(c.Query("to"))
+	if errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+
 	var snapshot usage.StatisticsSnapshot
 	if h != nil && h.usageStats != nil {
-		snapshot = h.usageStats.Snapshot()
+		if from.IsZero() && to.IsZero() {
+			snapshot = h.usageStats.Snapshot()
+		} else {
+			var err error
+			snapshot, err = h.usageStats.SnapshotRange(c.Request.Context(), from, to)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to query usage: %v", err)})
+				return
+			}
+		}
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"usage":           snapshot,
@@ -45,7 +69,7 @@ func (h *Handler) ExportUsageStatistics(c *gin.Context) {
 	})
 }
 
-// ImportUsageStatistics merges a previously exported usage snapshot into memory.
+// ImportUsageStatistics merges a previously exported usage snapshot into memory and the attached usage store.
 func (h *Handler) ImportUsageStatistics(c *gin.Context) {
 	if h == nil || h.usageStats == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "usage statistics unavailable"})
@@ -77,3 +101,14 @@ func (h *Handler) ImportUsageStatistics(c *gin.Context) {
 		"failed_requests": snapshot.FailureCount,
 	})
 }
+
+func parseUsageTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := time.Parse(time.RFC3339, raw); err == nil {
+		return ts, nil
+	}
+	return time.ParseInLocation("2006-01-02", raw, time.UTC)
+}
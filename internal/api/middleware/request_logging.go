@@ -6,8 +6,10 @@ package middleware
 import (
 	"bytes"
 	"io"
+	"math/rand"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
@@ -50,6 +52,9 @@ func RequestLoggingMiddleware(logger logging.RequestLogger) gin.HandlerFunc {
 		if !logger.IsEnabled() {
 			wrapper.logOnErrorOnly = true
 		}
+		if sampler := logging.DefaultRequestLogSampler(); sampler.Active() {
+			wrapper.sample = newRequestLogSampleFunc(c, sampler, requestInfo)
+		}
 		c.Writer = wrapper
 
 		// Process the request
@@ -106,6 +111,28 @@ func captureRequestInfo(c *gin.Context) (*RequestInfo, error) {
 	}, nil
 }
 
+// newRequestLogSampleFunc binds the sampling decision to the request. The random draw is made
+// once so the early check for streams and the final check agree. Before the request finishes
+// its latency is unknown and latency conditions are assumed to hold.
+func newRequestLogSampleFunc(c *gin.Context, sampler *logging.RequestLogSampler, info *RequestInfo) func(status int, final bool) bool {
+	start := time.Now()
+	draw := rand.Float64() * 100
+	model := logging.RequestLogModel(info.URL, info.Body)
+	return func(status int, final bool) bool {
+		latency := time.Duration(-1)
+		if final {
+			latency = time.Since(start)
+		}
+		return sampler.Sample(logging.RequestLogSample{
+			ClientKey: logging.ClientKeyName(c),
+			Model:     model,
+			Status:    status,
+			Latency:   latency,
+			Draw:      draw,
+		})
+	}
+}
+
 // shouldLogRequest determines whether the request should be logged.
 // It skips management endpoints to avoid leaking secrets but allows
 // all other routes, including module-provided ones, to honor request-log.
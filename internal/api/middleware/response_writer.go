@@ -36,6 +36,7 @@ type ResponseWriterWrapper struct {
 	statusCode     int                        // statusCode stores the HTTP status code of the response.
 	headers        map[string][]string        // headers stores the response headers.
 	logOnErrorOnly bool                       // logOnErrorOnly enables logging only when an error response is detected.
+	sample         func(int, bool) bool       // sample applies request-log sampling to a status; nil logs every request.
 }
 
 // NewResponseWriterWrapper creates and initializes a new ResponseWriterWrapper.
@@ -144,7 +145,7 @@ func (w *ResponseWriterWrapper) WriteHeader(statusCode int) {
 	w.isStreaming = w.detectStreaming(contentType)
 
 	// If streaming, initialize streaming log writer
-	if w.isStreaming && w.logger.IsEnabled() {
+	if w.isStreaming && w.logger.IsEnabled() && (w.sample == nil || w.sample(statusCode, false)) {
 		streamWriter, err := w.logger.LogStreamingRequest(
 			w.requestInfo.URL,
 			w.requestInfo.Method,
@@ -268,6 +269,10 @@ func (w *ResponseWriterWrapper) Finalize(c *gin.Context) error {
 	if !w.logger.IsEnabled() && !forceLog {
 		return nil
 	}
+	if w.logger.IsEnabled() && w.sample != nil && !w.sample(finalStatusCode, true) {
+		w.discardStream()
+		return nil
+	}
 
 	if w.isStreaming && w.streamWriter != nil {
 		if w.chunkChannel != nil {
@@ -280,6 +285,12 @@ func (w *ResponseWriterWrapper) Finalize(c *gin.Context) error {
 			w.streamDone = nil
 		}
 
+		if metaWriter, ok := w.streamWriter.(interface {
+			SetMetadata(logging.RequestLogMetadata)
+		}); ok {
+			metaWriter.SetMetadata(requestLogMetadata(c))
+		}
+
 		// Write API Request and Response to the streaming log before closing
 		apiRequest := w.extractAPIRequest(c)
 		if len(apiRequest) > 0 {
@@ -297,7 +308,33 @@ func (w *ResponseWriterWrapper) Finalize(c *gin.Context) error {
 		return nil
 	}
 
-	return w.logRequest(finalStatusCode, w.cloneHeaders(), w.body.Bytes(), w.extractAPIRequest(c), w.extractAPIResponse(c), slicesAPIResponseError, forceLog)
+	return w.logRequest(finalStatusCode, w.cloneHeaders(), w.body.Bytes(), w.extractAPIRequest(c), w.extractAPIResponse(c), slicesAPIResponseError, forceLog, requestLogMetadata(c))
+}
+
+// requestLogMetadata collects the request details recorded in the request log index.
+func requestLogMetadata(c *gin.Context) logging.RequestLogMetadata {
+	return logging.RequestLogMetadata{ClientKey: logging.ClientKeyName(c)}
+}
+
+// discardStream stops a streaming log that was started for a request that is not sampled.
+func (w *ResponseWriterWrapper) discardStream() {
+	if w.streamWriter == nil {
+		return
+	}
+	if w.chunkChannel != nil {
+		close(w.chunkChannel)
+		w.chunkChannel = nil
+	}
+	if w.streamDone != nil {
+		<-w.streamDone
+		w.streamDone = nil
+	}
+	if discarder, ok := w.streamWriter.(interface{ Discard() error }); ok {
+		_ = discarder.Discard()
+	} else {
+		_ = w.streamWriter.Close()
+	}
+	w.streamWriter = nil
 }
 
 func (w *ResponseWriterWrapper) cloneHeaders() map[string][]string {
@@ -337,7 +374,7 @@ func (w *ResponseWriterWrapper) extractAPIResponse(c *gin.Context) []byte {
 	return data
 }
 
-func (w *ResponseWriterWrapper) logRequest(statusCode int, headers map[string][]string, body []byte, apiRequestBody, apiResponseBody []byte, apiResponseErrors []*interfaces.ErrorMessage, forceLog bool) error {
+func (w *ResponseWriterWrapper) logRequest(statusCode int, headers map[string][]string, body []byte, apiRequestBody, apiResponseBody []byte, apiResponseErrors []*interfaces.ErrorMessage, forceLog bool, meta logging.RequestLogMetadata) error {
 	if w.requestInfo == nil {
 		return nil
 	}
@@ -347,6 +384,26 @@ func (w *ResponseWriterWrapper) logRequest(statusCode int, headers map[string][]
 		requestBody = w.requestInfo.Body
 	}
 
+	if loggerWithMetadata, ok := w.logger.(interface {
+		LogRequestWithMetadata(string, string, map[string][]string, []byte, int, map[string][]string, []byte, []byte, []byte, []*interfaces.ErrorMessage, bool, string, logging.RequestLogMetadata) error
+	}); ok {
+		return loggerWithMetadata.LogRequestWithMetadata(
+			w.requestInfo.URL,
+			w.requestInfo.Method,
+			w.requestInfo.Headers,
+			requestBody,
+			statusCode,
+			headers,
+			body,
+			apiRequestBody,
+			apiResponseBody,
+			apiResponseErrors,
+			forceLog,
+			w.requestInfo.RequestID,
+			meta,
+		)
+	}
+
 	if loggerWithOptions, ok := w.logger.(interface {
 		LogRequestWithOptions(string, string, map[string][]string, []byte, int, map[string][]string, []byte, []byte, []byte, []*interfaces.ErrorMessage, bool, string) error
 	}); ok {
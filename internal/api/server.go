@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,14 +20,20 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/access"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/access/clientkeys"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/access/ratelimit"
 	managementHandlers "github.com/router-for-me/CLIProxyAPI/v6/internal/api/handlers/management"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/middleware"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules"
 	ampmodule "github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules/amp"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/donation"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/metrics"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/notify"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
@@ -36,6 +43,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers/openai"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/sjson"
 	"gopkg.in/yaml.v3"
 )
 
@@ -148,6 +156,12 @@ type Server struct {
 	wsAuthChanged func(bool, bool)
 	wsAuthEnabled atomic.Bool
 
+	// metricsRoutes tracks registered Prometheus metrics paths; metricsPath holds the active one.
+	metricsRouteMu sync.Mutex
+	metricsRoutes  map[string]struct{}
+	metricsPath    atomic.Value
+	metricsEnabled atomic.Bool
+
 	// management handler
 	mgmt *managementHandlers.Handler
 
@@ -204,6 +218,8 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 
 	// Add middleware
 	engine.Use(logging.GinLogrusLogger())
+	engine.Use(tracing.Middleware())
+	engine.Use(logging.AccessLogMiddleware())
 	engine.Use(logging.GinLogrusRecovery())
 	for _, mw := range optionState.extraMiddleware {
 		engine.Use(mw)
@@ -222,6 +238,14 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 			if setter, ok := requestLogger.(interface{ SetEnabled(bool) }); ok {
 				toggle = setter.SetEnabled
 			}
+			if setter, ok := requestLogger.(interface {
+				SetRedaction(config.RequestLogRedactionConfig)
+			}); ok {
+				setter.SetRedaction(cfg.RequestLogRedaction)
+			}
+			if setter, ok := requestLogger.(interface{ SetMaxBodyBytes(int) }); ok {
+				setter.SetMaxBodyBytes(cfg.RequestLogSampling.MaxBodyBytes)
+			}
 		}
 	}
 
@@ -247,11 +271,21 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 		currentPath:         wd,
 		envManagementSecret: envManagementSecret,
 		wsRoutes:            make(map[string]struct{}),
+		metricsRoutes:       make(map[string]struct{}),
 	}
 	s.wsAuthEnabled.Store(cfg.WebsocketAuth)
 	// Save initial YAML snapshot
 	s.oldConfigYaml, _ = yaml.Marshal(cfg)
 	s.applyAccessConfig(nil, cfg)
+	clientkeys.DefaultTracker().SetPath(clientKeyUsagePath(cfg))
+	ratelimit.Default().SetPolicies(cfg.APIKeyLimits)
+	usage.SetPricing(cfg.Pricing)
+	cache.DefaultResponseCache().Configure(cfg.ResponseCache)
+	notify.DefaultNotifier().Configure(cfg.Notifications)
+	logging.DefaultRequestLogSampler().Configure(cfg.RequestLogSampling)
+	if err := tracing.Configure(context.Background(), cfg.Tracing); err != nil {
+		log.Errorf("failed to configure tracing: %v", err)
+	}
 	if authManager != nil {
 		authManager.SetRetryConfig(cfg.RequestRetry, time.Duration(cfg.MaxRetryInterval)*time.Second)
 	}
@@ -271,6 +305,7 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 
 	// Setup routes
 	s.setupRoutes()
+	s.applyMetricsConfig(cfg)
 
 	// Initialize and register donation module
 	donationMod, err := donation.NewDonationModule(cfg)
@@ -299,7 +334,7 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 	}
 
 	// Register management routes when configuration or environment secrets are available.
-	hasManagementSecret := cfg.RemoteManagement.SecretKey != "" || envManagementSecret
+	hasManagementSecret := cfg.RemoteManagement.SecretKey != "" || len(cfg.RemoteManagement.Tokens) > 0 || envManagementSecret
 	s.managementRoutesEnabled.Store(hasManagementSecret)
 	if hasManagementSecret {
 		s.registerManagementRoutes()
@@ -335,6 +370,9 @@ func (s *Server) setupRoutes() {
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
 		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
 		v1.POST("/completions", openaiHandlers.Completions)
+		v1.POST("/embeddings", openaiHandlers.Embeddings)
+		v1.POST("/images/generations", openaiHandlers.ImageGenerations)
+		v1.POST("/images/edits", openaiHandlers.ImageEdits)
 		v1.POST("/messages", claudeCodeHandlers.ClaudeMessages)
 		v1.POST("/messages/count_tokens", claudeCodeHandlers.ClaudeCountTokens)
 		v1.POST("/responses", openaiResponsesHandlers.Responses)
@@ -495,6 +533,7 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigYAML)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
 		mgmt.GET("/latest-version", s.mgmt.GetLatestVersion)
+		mgmt.GET("/audit-log", s.mgmt.GetAuditLog)
 
 		mgmt.GET("/debug", s.mgmt.GetDebug)
 		mgmt.PUT("/debug", s.mgmt.PutDebug)
@@ -528,6 +567,13 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.PATCH("/api-keys", s.mgmt.PatchAPIKeys)
 		mgmt.DELETE("/api-keys", s.mgmt.DeleteAPIKeys)
 
+		mgmt.GET("/client-keys", s.mgmt.GetClientKeys)
+		mgmt.POST("/client-keys", s.mgmt.CreateClientKey)
+		mgmt.PATCH("/client-keys", s.mgmt.PatchClientKey)
+		mgmt.DELETE("/client-keys", s.mgmt.DeleteClientKey)
+		mgmt.POST("/client-keys/rotate", s.mgmt.RotateClientKey)
+		mgmt.POST("/client-keys/revoke", s.mgmt.RevokeClientKey)
+
 		mgmt.GET("/gemini-api-key", s.mgmt.GetGeminiKeys)
 		mgmt.PUT("/gemini-api-key", s.mgmt.PutGeminiKeys)
 		mgmt.PATCH("/gemini-api-key", s.mgmt.PatchGeminiKey)
@@ -538,6 +584,7 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/request-error-logs", s.mgmt.GetRequestErrorLogs)
 		mgmt.GET("/request-error-logs/:name", s.mgmt.DownloadRequestErrorLog)
 		mgmt.GET("/request-log-by-id/:id", s.mgmt.GetRequestLogByID)
+		mgmt.GET("/request-logs", s.mgmt.SearchRequestLogs)
 		mgmt.GET("/request-log", s.mgmt.GetRequestLog)
 		mgmt.PUT("/request-log", s.mgmt.PutRequestLog)
 		mgmt.PATCH("/request-log", s.mgmt.PutRequestLog)
@@ -601,6 +648,8 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/auth-files/download", s.mgmt.DownloadAuthFile)
 		mgmt.POST("/auth-files", s.mgmt.UploadAuthFile)
 		mgmt.DELETE("/auth-files", s.mgmt.DeleteAuthFile)
+		mgmt.PATCH("/auth-files", s.mgmt.PatchAuthFile)
+		mgmt.PATCH("/auth-files/status", s.mgmt.PatchAuthFileStatus)
 		mgmt.POST("/vertex/import", s.mgmt.ImportVertexCredential)
 
 		mgmt.GET("/anthropic-auth-url", s.mgmt.RequestAnthropicToken)
@@ -615,6 +664,46 @@ func (s *Server) registerManagementRoutes() {
 	}
 }
 
+// applyMetricsConfig toggles metrics collection and ensures the configured metrics path is routed.
+// Gin cannot remove routes, so previously registered paths stay attached but answer 404
+// once they are no longer the active path.
+func (s *Server) applyMetricsConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	path := cfg.Metrics.Path
+	if path == "" {
+		path = config.DefaultMetricsPath
+	}
+	metrics.SetEnabled(cfg.Metrics.Enable)
+	s.metricsEnabled.Store(cfg.Metrics.Enable)
+	s.metricsPath.Store(path)
+	if !cfg.Metrics.Enable {
+		return
+	}
+
+	s.metricsRouteMu.Lock()
+	defer s.metricsRouteMu.Unlock()
+	if _, exists := s.metricsRoutes[path]; exists {
+		return
+	}
+	s.metricsRoutes[path] = struct{}{}
+	s.engine.GET(path, s.serveMetrics)
+}
+
+func (s *Server) serveMetrics(c *gin.Context) {
+	active, _ := s.metricsPath.Load().(string)
+	if !s.metricsEnabled.Load() || c.FullPath() != active {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := metrics.DefaultCollector().Write(c.Writer); err != nil {
+		log.Errorf("failed to write metrics: %v", err)
+	}
+}
+
 func (s *Server) managementAvailabilityMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !s.managementRoutesEnabled.Load() {
@@ -803,6 +892,13 @@ func (s *Server) Stop(ctx context.Context) error {
 	if err := s.server.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown HTTP server: %v", err)
 	}
+	s.donationModule.Close()
+	if err := clientkeys.DefaultTracker().Flush(); err != nil {
+		log.Warnf("failed to write client key usage: %v", err)
+	}
+	if err := tracing.Shutdown(ctx); err != nil {
+		log.Warnf("failed to flush traces: %v", err)
+	}
 
 	log.Debug("API server stopped")
 	return nil
@@ -868,7 +964,21 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
-	if oldCfg == nil || oldCfg.LoggingToFile != cfg.LoggingToFile || oldCfg.LogsMaxTotalSizeMB != cfg.LogsMaxTotalSizeMB {
+	if s.requestLogger != nil && (oldCfg == nil || !reflect.DeepEqual(oldCfg.RequestLogRedaction, cfg.RequestLogRedaction)) {
+		if setter, ok := s.requestLogger.(interface {
+			SetRedaction(config.RequestLogRedactionConfig)
+		}); ok {
+			setter.SetRedaction(cfg.RequestLogRedaction)
+		}
+	}
+
+	if s.requestLogger != nil && (oldCfg == nil || oldCfg.RequestLogSampling.MaxBodyBytes != cfg.RequestLogSampling.MaxBodyBytes) {
+		if setter, ok := s.requestLogger.(interface{ SetMaxBodyBytes(int) }); ok {
+			setter.SetMaxBodyBytes(cfg.RequestLogSampling.MaxBodyBytes)
+		}
+	}
+
+	if oldCfg == nil || oldCfg.LoggingToFile != cfg.LoggingToFile || oldCfg.LogsMaxTotalSizeMB != cfg.LogsMaxTotalSizeMB || oldCfg.AccessLog != cfg.AccessLog {
 		if err := logging.ConfigureLogOutput(cfg); err != nil {
 			log.Errorf("failed to reconfigure log output: %v", err)
 		} else {
@@ -881,6 +991,9 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 				if oldCfg.LogsMaxTotalSizeMB != cfg.LogsMaxTotalSizeMB {
 					log.Debugf("logs_max_total_size_mb updated from %d to %d", oldCfg.LogsMaxTotalSizeMB, cfg.LogsMaxTotalSizeMB)
 				}
+				if oldCfg.AccessLog != cfg.AccessLog {
+					log.Debugf("access_log updated (enable %t -> %t)", oldCfg.AccessLog.Enable, cfg.AccessLog.Enable)
+				}
 			}
 		}
 	}
@@ -894,6 +1007,13 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
+	if oldCfg == nil || oldCfg.Metrics != cfg.Metrics {
+		s.applyMetricsConfig(cfg)
+		if oldCfg != nil {
+			log.Debugf("metrics updated from enable=%t path=%s to enable=%t path=%s", oldCfg.Metrics.Enable, oldCfg.Metrics.Path, cfg.Metrics.Enable, cfg.Metrics.Path)
+		}
+	}
+
 	if oldCfg == nil || oldCfg.DisableCooling != cfg.DisableCooling {
 		auth.SetQuotaCooldownDisabled(cfg.DisableCooling)
 		if oldCfg != nil {
@@ -918,9 +1038,9 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 
 	prevSecretEmpty := true
 	if oldCfg != nil {
-		prevSecretEmpty = oldCfg.RemoteManagement.SecretKey == ""
+		prevSecretEmpty = oldCfg.RemoteManagement.SecretKey == "" && len(oldCfg.RemoteManagement.Tokens) == 0
 	}
-	newSecretEmpty := cfg.RemoteManagement.SecretKey == ""
+	newSecretEmpty := cfg.RemoteManagement.SecretKey == "" && len(cfg.RemoteManagement.Tokens) == 0
 	if s.envManagementSecret {
 		s.registerManagementRoutes()
 		if s.managementRoutesEnabled.CompareAndSwap(false, true) {
@@ -949,6 +1069,15 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 	}
 
 	s.applyAccessConfig(oldCfg, cfg)
+	clientkeys.DefaultTracker().SetPath(clientKeyUsagePath(cfg))
+	ratelimit.Default().SetPolicies(cfg.APIKeyLimits)
+	usage.SetPricing(cfg.Pricing)
+	cache.DefaultResponseCache().Configure(cfg.ResponseCache)
+	notify.DefaultNotifier().Configure(cfg.Notifications)
+	logging.DefaultRequestLogSampler().Configure(cfg.RequestLogSampling)
+	if err := tracing.Configure(context.Background(), cfg.Tracing); err != nil {
+		log.Errorf("failed to configure tracing: %v", err)
+	}
 	s.cfg = cfg
 	s.wsAuthEnabled.Store(cfg.WebsocketAuth)
 	if oldCfg != nil && s.wsAuthChanged != nil && oldCfg.WebsocketAuth != cfg.WebsocketAuth {
@@ -1029,6 +1158,11 @@ func AuthMiddleware(manager *sdkaccess.Manager) gin.HandlerFunc {
 				c.Set("accessProvider", result.Provider)
 				if len(result.Metadata) > 0 {
 					c.Set("accessMetadata", result.Metadata)
+					clientkeys.DefaultTracker().Touch(result.Metadata[clientkeys.MetadataKeyID], c.ClientIP())
+				}
+				if limitErr := ratelimit.Default().Allow(result.Principal); limitErr != nil {
+					abortWithLimitError(c, limitErr)
+					return
 				}
 			}
 			c.Next()
@@ -1046,3 +1180,40 @@ func AuthMiddleware(manager *sdkaccess.Manager) gin.HandlerFunc {
 		}
 	}
 }
+
+// clientKeyUsagePath returns where client key last-used records are stored, or "" when
+// no auth directory is configured.
+func clientKeyUsagePath(cfg *config.Config) string {
+	if cfg == nil || strings.TrimSpace(cfg.AuthDir) == "" {
+		return ""
+	}
+	return filepath.Join(cfg.AuthDir, clientkeys.UsageFileName)
+}
+
+// abortWithLimitError rejects a request that exceeded its client key policy with a 429 and Retry-After.
+func abortWithLimitError(c *gin.Context, err *ratelimit.LimitError) {
+	for key, values := range err.Headers() {
+		for _, value := range values {
+			c.Header(key, value)
+		}
+	}
+	c.Data(http.StatusTooManyRequests, "application/json", limitErrorBody(c.Request.URL.Path, err.Error()))
+	c.Abort()
+}
+
+// limitErrorBody builds the 429 body in the error shape of the API the path belongs to, so
+// Claude and Gemini clients recognise the rate limit.
+func limitErrorBody(path, message string) []byte {
+	switch {
+	case strings.Contains(path, "/v1/messages"):
+		body := []byte(`{"type":"error","error":{"type":"rate_limit_error","message":""}}`)
+		body, _ = sjson.SetBytes(body, "error.message", message)
+		return body
+	case strings.Contains(path, "/v1beta/models") || strings.HasPrefix(path, "/v1internal"):
+		body := []byte(`{"error":{"code":429,"message":"","status":"RESOURCE_EXHAUSTED"}}`)
+		body, _ = sjson.SetBytes(body, "error.message", message)
+		return body
+	default:
+		return handlers.BuildErrorResponseBody(http.StatusTooManyRequests, message)
+	}
+}
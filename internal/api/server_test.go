@@ -7,12 +7,15 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	gin "github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/access/ratelimit"
 	proxyconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
 )
 
 func newTestServer(t *testing.T) *Server {
@@ -109,3 +112,39 @@ func TestAmpProviderModelRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestAbortWithLimitErrorUsesNativeErrorShape(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCases := []struct {
+		name  string
+		path  string
+		field string
+		want  string
+	}{
+		{name: "openai", path: "/v1/chat/completions", field: "error.type", want: "rate_limit_error"},
+		{name: "claude", path: "/v1/messages", field: "type", want: "error"},
+		{name: "claude count tokens", path: "/v1/messages/count_tokens", field: "error.type", want: "rate_limit_error"},
+		{name: "amp claude", path: "/api/provider/anthropic/v1/messages", field: "error.type", want: "rate_limit_error"},
+		{name: "gemini", path: "/v1beta/models/gemini-2.5-pro:generateContent", field: "error.status", want: "RESOURCE_EXHAUSTED"},
+		{name: "gemini cli", path: "/v1internal:streamGenerateContent", field: "error.code", want: "429"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPost, tc.path, nil)
+			abortWithLimitError(c, &ratelimit.LimitError{Reason: "requests per minute exceeded", RetryAfter: time.Minute})
+
+			if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "60" {
+				t.Fatalf("status = %d, Retry-After = %q", rr.Code, rr.Header().Get("Retry-After"))
+			}
+			body := rr.Body.Bytes()
+			if got := gjson.GetBytes(body, tc.field).String(); got != tc.want {
+				t.Fatalf("%s = %q, want %q; body=%s", tc.field, got, tc.want, body)
+			}
+			if got := gjson.GetBytes(body, "error.message").String(); got != "requests per minute exceeded" {
+				t.Fatalf("error.message = %q; body=%s", got, body)
+			}
+		})
+	}
+}
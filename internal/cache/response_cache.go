@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+const (
+	// DefaultResponseCacheTTL is used when response-cache.ttl-seconds is not set.
+	DefaultResponseCacheTTL = 5 * time.Minute
+
+	// DefaultResponseCacheMaxEntries is used when response-cache.max-entries is not set.
+	DefaultResponseCacheMaxEntries = 1000
+
+	// DefaultResponseCacheMaxSizeMB is used when response-cache.max-size-mb is not set.
+	DefaultResponseCacheMaxSizeMB = 64
+)
+
+// responseEntry is a cached response body and its expiry.
+type responseEntry struct {
+	key     string
+	body    []byte
+	expires time.Time
+}
+
+// ResponseCache is a size-bounded LRU cache of successful non-streaming responses.
+type ResponseCache struct {
+	mu         sync.Mutex
+	enabled    bool
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int64
+	size       int64
+	order      *list.List
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+var defaultResponseCache = NewResponseCache(config.ResponseCacheConfig{})
+
+// DefaultResponseCache returns the shared response cache used by the API handlers.
+func DefaultResponseCache() *ResponseCache { return defaultResponseCache }
+
+// NewResponseCache constructs a response cache with the given settings.
+func NewResponseCache(cfg config.ResponseCacheConfig) *ResponseCache {
+	c := &ResponseCache{
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	c.Configure(cfg)
+	return c
+}
+
+// Configure applies new settings, evicting entries that no longer fit. Disabling the cache
+// drops every entry.
+func (c *ResponseCache) Configure(cfg config.ResponseCacheConfig) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled = cfg.Enabled
+	c.ttl = DefaultResponseCacheTTL
+	if cfg.TTLSeconds > 0 {
+		c.ttl = time.Duration(cfg.TTLSeconds) * time.Second
+	}
+	c.maxEntries = DefaultResponseCacheMaxEntries
+	if cfg.MaxEntries > 0 {
+		c.maxEntries = cfg.MaxEntries
+	}
+	maxSizeMB := DefaultResponseCacheMaxSizeMB
+	if cfg.MaxSizeMB > 0 {
+		maxSizeMB = cfg.MaxSizeMB
+	}
+	c.maxBytes = int64(maxSizeMB) << 20
+	if !c.enabled {
+		c.order.Init()
+		c.entries = make(map[string]*list.Element)
+		c.size = 0
+		return
+	}
+	c.evictLocked()
+}
+
+// Enabled reports whether the cache is switched on.
+func (c *ResponseCache) Enabled() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enabled
+}
+
+// Get returns a copy of the cached response for key if it is present and fresh.
+func (c *ResponseCache) Get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.enabled {
+		return nil, false
+	}
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*responseEntry)
+	if !c.clock().Before(entry.expires) {
+		c.removeLocked(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return bytes.Clone(entry.body), true
+}
+
+// Set stores body under key. Bodies larger than the whole size budget are not cached.
+func (c *ResponseCache) Set(key string, body []byte) {
+	if c == nil || key == "" || len(body) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.enabled || int64(len(body)) > c.maxBytes {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.removeLocked(elem)
+	}
+	entry := &responseEntry{key: key, body: bytes.Clone(body), expires: c.clock().Add(c.ttl)}
+	c.entries[key] = c.order.PushFront(entry)
+	c.size += int64(len(entry.body))
+	c.evictLocked()
+}
+
+// Len returns the number of cached responses.
+func (c *ResponseCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// evictLocked drops the least recently used entries until both limits hold.
+func (c *ResponseCache) evictLocked() {
+	for c.order.Len() > c.maxEntries || c.size > c.maxBytes {
+		oldest := c.order.Back()
+		if oldest == nil {
+			return
+		}
+		c.removeLocked(oldest)
+	}
+}
+
+func (c *ResponseCache) removeLocked(elem *list.Element) {
+	entry := elem.Value.(*responseEntry)
+	c.order.Remove(elem)
+	delete(c.entries, entry.key)
+	c.size -= int64(len(entry.body))
+}
+
+func (c *ResponseCache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// ResponseCacheKey derives the cache key for a request from the authenticated client principal,
+// its source format, requested model, alt parameter and payload. Including the principal keeps
+// clients from reading each other's responses. JSON payloads are normalized so key order and
+// whitespace do not affect the key.
+func ResponseCacheKey(principal, sourceFormat, model, alt string, payload []byte) string {
+	h := sha256.New()
+	for _, part := range []string{principal, sourceFormat, model, alt} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(normalizeJSON(payload))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeJSON re-encodes payload with sorted object keys and no insignificant whitespace.
+// Invalid JSON is returned unchanged.
+func normalizeJSON(payload []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return payload
+	}
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return payload
+	}
+	return normalized
+}
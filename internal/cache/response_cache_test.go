@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestResponseCacheKey_NormalizesPayload(t *testing.T) {
+	a := ResponseCacheKey("client-a", "openai", "gpt-5", "", []byte(`{"model":"gpt-5","temperature":0,"messages":[{"role":"user","content":"hi"}]}`))
+	b := ResponseCacheKey("client-a", "openai", "gpt-5", "", []byte("{\n  \"temperature\": 0,\n  \"messages\": [{\"content\":\"hi\",\"role\":\"user\"}],\n  \"model\": \"gpt-5\"\n}"))
+	if a != b {
+		t.Fatalf("keys differ for equivalent payloads: %s != %s", a, b)
+	}
+	if c := ResponseCacheKey("client-a", "claude", "gpt-5", "", []byte(`{"model":"gpt-5","temperature":0,"messages":[{"role":"user","content":"hi"}]}`)); c == a {
+		t.Fatalf("key should depend on the source format")
+	}
+	if d := ResponseCacheKey("client-b", "openai", "gpt-5", "", []byte(`{"model":"gpt-5","temperature":0,"messages":[{"role":"user","content":"hi"}]}`)); d == a {
+		t.Fatalf("key should depend on the client principal")
+	}
+}
+
+func TestResponseCache_ExpiresEntries(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := NewResponseCache(config.ResponseCacheConfig{Enabled: true, TTLSeconds: 10})
+	c.now = func() time.Time { return now }
+
+	c.Set("k", []byte("v"))
+	if got, ok := c.Get("k"); !ok || string(got) != "v" {
+		t.Fatalf("Get() = %q, %t; want cached value", got, ok)
+	}
+	now = now.Add(11 * time.Second)
+	if _, ok := c.Get("k"); ok {
+		t.Fatalf("Get() returned an expired entry")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("Len() = %d, want expired entry removed", c.Len())
+	}
+}
+
+func TestResponseCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewResponseCache(config.ResponseCacheConfig{Enabled: true, MaxEntries: 2})
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+	c.Get("a")
+	c.Set("c", []byte("3"))
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("least recently used entry was not evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Fatalf("recently used entry was evicted")
+	}
+
+	c.Configure(config.ResponseCacheConfig{Enabled: false})
+	if c.Len() != 0 {
+		t.Fatalf("Len() = %d after disabling, want 0", c.Len())
+	}
+	c.Set("d", []byte("4"))
+	if _, ok := c.Get("d"); ok {
+		t.Fatalf("disabled cache stored an entry")
+	}
+}
@@ -18,6 +18,9 @@ import (
 
 const DefaultPanelGitHubRepository = "https://github.com/router-for-me/Cli-Proxy-API-Management-Center"
 
+// DefaultMetricsPath is the route serving Prometheus metrics when metrics.path is empty.
+const DefaultMetricsPath = "/metrics"
+
 // Config represents the application's configuration, loaded from a YAML file.
 type Config struct {
 	SDKConfig `yaml:",inline"`
@@ -49,9 +52,30 @@ type Config struct {
 	// When exceeded, the oldest log files are deleted until within the limit. Set to 0 to disable.
 	LogsMaxTotalSizeMB int `yaml:"logs-max-total-size-mb" json:"logs-max-total-size-mb"`
 
+	// AccessLog configures the JSON-lines access log written next to the application logs.
+	AccessLog AccessLogConfig `yaml:"access-log" json:"access-log"`
+
+	// RequestLogRedaction controls what is scrubbed from request logs before they are written.
+	RequestLogRedaction RequestLogRedactionConfig `yaml:"request-log-redaction" json:"request-log-redaction"`
+
+	// RequestLogSampling limits which requests are written while request-log is enabled.
+	RequestLogSampling RequestLogSamplingConfig `yaml:"request-log-sampling" json:"request-log-sampling"`
+
 	// UsageStatisticsEnabled toggles in-memory usage aggregation; when false, usage data is discarded.
 	UsageStatisticsEnabled bool `yaml:"usage-statistics-enabled" json:"usage-statistics-enabled"`
 
+	// UsagePersistence configures the durable usage statistics store.
+	UsagePersistence UsagePersistenceConfig `yaml:"usage-persistence" json:"usage-persistence"`
+
+	// Pricing defines per-model token prices used to report spend and enforce cost budgets.
+	Pricing []ModelPrice `yaml:"pricing,omitempty" json:"pricing,omitempty"`
+
+	// Metrics configures the Prometheus metrics endpoint.
+	Metrics MetricsConfig `yaml:"metrics" json:"metrics"`
+
+	// Tracing configures OpenTelemetry span export.
+	Tracing TracingConfig `yaml:"tracing" json:"tracing"`
+
 	// DisableCooling disables quota cooldown scheduling when true.
 	DisableCooling bool `yaml:"disable-cooling" json:"disable-cooling"`
 
@@ -99,6 +123,24 @@ type Config struct {
 	// gemini-api-key, codex-api-key, claude-api-key, openai-compatibility, vertex-api-key, and ampcode.
 	OAuthModelMappings map[string][]ModelNameMapping `yaml:"oauth-model-mappings,omitempty" json:"oauth-model-mappings,omitempty"`
 
+	// ModelFallbacks defines cross-provider fallback chains tried in order when the requested model
+	// is not found upstream or every credential serving it is cooling down.
+	ModelFallbacks []ModelFallback `yaml:"model-fallbacks,omitempty" json:"model-fallbacks,omitempty"`
+
+	// HealthProbe configures scheduled probes that detect broken credentials before user traffic does.
+	HealthProbe HealthProbeConfig `yaml:"health-probe" json:"health-probe"`
+
+	// ModelDiscovery configures periodic discovery of upstream model catalogs for API-key credentials.
+	ModelDiscovery ModelDiscoveryConfig `yaml:"model-discovery" json:"model-discovery"`
+
+	// ModelCatalog is the path of a YAML or JSON file that adds models and overrides built-in
+	// model metadata and thinking ranges. Relative paths resolve against the config file directory.
+	// The file is watched and reloaded on change.
+	ModelCatalog string `yaml:"model-catalog,omitempty" json:"model-catalog,omitempty"`
+
+	// Notifications configures webhook delivery of credential and quota events.
+	Notifications NotificationConfig `yaml:"notifications" json:"notifications"`
+
 	// Payload defines default and override rules for provider payload parameters.
 	Payload PayloadConfig `yaml:"payload" json:"payload"`
 
@@ -135,6 +177,18 @@ type DonationConfig struct {
 	AdminLinuxDoIDs []int `yaml:"admin-linux-do-ids" json:"admin-linux-do-ids"`
 	// AdminLinuxDoUsernames is the list of Linux Do usernames that have admin privileges.
 	AdminLinuxDoUsernames []string `yaml:"admin-linux-do-usernames" json:"admin-linux-do-usernames"`
+	// CallbackSecret is the HMAC-SHA256 secret used to verify payment provider callbacks.
+	// When empty, donations can only be verified by an admin.
+	CallbackSecret string `yaml:"callback-secret" json:"-"`
+	// MaxPendingPerUser caps unverified donations per user. Default: 1.
+	MaxPendingPerUser int `yaml:"max-pending-per-user" json:"max-pending-per-user"`
+	// DailyLimitPerUser caps donations a user may report within 24 hours, excluding failed ones. Default: 1.
+	DailyLimitPerUser int `yaml:"daily-limit-per-user" json:"daily-limit-per-user"`
+	// SessionStore selects where login sessions are kept: "auto" (default), "file", "postgres" or "memory".
+	// "auto" uses Postgres when the Postgres token store is active and a file in the auth directory otherwise.
+	SessionStore string `yaml:"session-store,omitempty" json:"session-store,omitempty"`
+	// SessionTTLHours is how long a login session stays valid. Default: 24.
+	SessionTTLHours int `yaml:"session-ttl-hours,omitempty" json:"session-ttl-hours,omitempty"`
 }
 
 // TLSConfig holds HTTPS server settings.
@@ -158,6 +212,155 @@ type RemoteManagement struct {
 	// PanelGitHubRepository overrides the GitHub repository used to fetch the management panel asset.
 	// Accepts either a repository URL (https://github.com/org/repo) or an API releases endpoint.
 	PanelGitHubRepository string `yaml:"panel-github-repository"`
+	// Tokens are additional named management keys restricted to a set of scopes.
+	Tokens []ManagementToken `yaml:"tokens,omitempty"`
+}
+
+// ManagementToken is a named management key. Requests authenticated with it may only reach
+// the endpoints its scopes allow, and are attributed to Name in the audit log.
+type ManagementToken struct {
+	// Name identifies the token holder in the audit log.
+	Name string `yaml:"name"`
+	// Key is the token value (plaintext or bcrypt hashed).
+	Key string `yaml:"key"`
+	// Scopes lists granted scopes: "read-only", "usage-only", "credentials-admin",
+	// "config-admin" or "admin".
+	Scopes []string `yaml:"scopes"`
+}
+
+// UsagePersistenceConfig controls where usage records are stored durably.
+// When the Postgres token store is active its connection is reused; otherwise a local file is used.
+type UsagePersistenceConfig struct {
+	// Enable toggles durable usage persistence.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Path is the file store location. Relative paths resolve against the writable base
+	// directory (or the config directory). Default: usage/usage.jsonl
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+}
+
+// ModelPrice sets the USD price per million tokens for models matching Model.
+// Cached and reasoning rates fall back to the input and output rates when unset.
+type ModelPrice struct {
+	// Model is the model name or wildcard pattern (e.g., "gpt-*", "*-5", "gemini-*-pro").
+	// An exact name takes precedence over patterns; otherwise the first matching entry wins.
+	Model string `yaml:"model" json:"model"`
+	// Input is the price per million uncached input tokens.
+	Input float64 `yaml:"input" json:"input"`
+	// Output is the price per million output tokens.
+	Output float64 `yaml:"output" json:"output"`
+	// CachedInput is the price per million cached input tokens.
+	CachedInput *float64 `yaml:"cached-input,omitempty" json:"cached-input,omitempty"`
+	// Reasoning is the price per million reasoning tokens. They are billed at this rate instead of
+	// the output rate, whether the provider counts them as output or reports them separately.
+	Reasoning *float64 `yaml:"reasoning,omitempty" json:"reasoning,omitempty"`
+}
+
+// MetricsConfig holds Prometheus metrics endpoint settings.
+type MetricsConfig struct {
+	// Enable toggles the metrics endpoint.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Path is the HTTP route serving the metrics. Default: /metrics
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+}
+
+// TracingConfig controls OpenTelemetry tracing exported over OTLP.
+type TracingConfig struct {
+	// Enable toggles span export.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Protocol selects the OTLP transport: "http" (default) or "grpc".
+	Protocol string `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+	// Endpoint is the collector address as host:port or a full URL. When empty the standard
+	// OTEL_EXPORTER_OTLP_* environment variables apply.
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	// Insecure disables TLS towards the collector.
+	Insecure bool `yaml:"insecure,omitempty" json:"insecure,omitempty"`
+	// Headers are sent with every export request, e.g. for collector authentication.
+	Headers map[string]string `yaml:"headers,omitempty" json:"-"`
+	// ServiceName is reported as service.name. Default: cli-proxy-api
+	ServiceName string `yaml:"service-name,omitempty" json:"service-name,omitempty"`
+	// SampleRatio is the fraction of new traces recorded, between 0 and 1. Values outside
+	// that range record every trace. Traces started by a sampled client are always recorded.
+	SampleRatio float64 `yaml:"sample-ratio,omitempty" json:"sample-ratio,omitempty"`
+}
+
+// AccessLogConfig controls the structured access log. Each request is written as one JSON
+// object per line to access.log in the logs directory, rotated like main.log.
+type AccessLogConfig struct {
+	// Enable toggles the access log.
+	Enable bool `yaml:"enable" json:"enable"`
+	// MaxSizeMB is the size at which the file is rotated. Default: 10
+	MaxSizeMB int `yaml:"max-size-mb,omitempty" json:"max-size-mb,omitempty"`
+	// MaxBackups is the number of rotated files to keep; 0 keeps all of them.
+	MaxBackups int `yaml:"max-backups,omitempty" json:"max-backups,omitempty"`
+	// MaxAgeDays removes rotated files older than this many days; 0 disables age-based removal.
+	MaxAgeDays int `yaml:"max-age-days,omitempty" json:"max-age-days,omitempty"`
+	// Compress gzips rotated files.
+	Compress bool `yaml:"compress,omitempty" json:"compress,omitempty"`
+}
+
+// RequestLogRedactionConfig selects the request log content replaced with placeholders before
+// anything reaches the logs directory. It applies to request and response bodies, headers and
+// the upstream request/response sections.
+type RequestLogRedactionConfig struct {
+	// Prompts redacts message text: content, text, prompt, system, instructions, thinking and
+	// string-valued input fields.
+	Prompts bool `yaml:"prompts" json:"prompts"`
+	// ToolArguments redacts tool call arguments and tool_use inputs.
+	ToolArguments bool `yaml:"tool-arguments" json:"tool-arguments"`
+	// AuthHeaders fully redacts credential headers such as Authorization, X-Api-Key and Cookie
+	// instead of the default partial masking.
+	AuthHeaders bool `yaml:"auth-headers" json:"auth-headers"`
+	// Headers lists additional header names to redact, case-insensitively.
+	Headers []string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	// Fields lists additional JSON field names whose values are redacted wherever they appear.
+	Fields []string `yaml:"fields,omitempty" json:"fields,omitempty"`
+}
+
+// RequestLogSamplingConfig narrows request logging from every request to the requests matching
+// at least one rule, and caps the size of each logged body. It only applies while request-log is
+// enabled; with request-log disabled, error responses are still written as error logs.
+type RequestLogSamplingConfig struct {
+	// Rules select the requests to log. When empty, every request is logged.
+	Rules []RequestLogSampleRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+	// MaxBodyBytes truncates each logged body and upstream request/response section to this
+	// many bytes, followed by a truncation marker. 0 disables truncation.
+	MaxBodyBytes int `yaml:"max-body-bytes,omitempty" json:"max-body-bytes,omitempty"`
+}
+
+// RequestLogSampleRule matches requests for request logging. Every condition that is set must
+// hold for the rule to match.
+type RequestLogSampleRule struct {
+	// Percentage is the share of matching requests logged, between 0 and 100. Default: 100
+	Percentage float64 `yaml:"percentage,omitempty" json:"percentage,omitempty"`
+	// ClientKeys restricts the rule to these client key names or IDs.
+	ClientKeys []string `yaml:"client-keys,omitempty" json:"client-keys,omitempty"`
+	// Models restricts the rule to requested models; '*' matches any run of characters.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+	// MinLatencyMs restricts the rule to requests that took at least this long.
+	MinLatencyMs int64 `yaml:"min-latency-ms,omitempty" json:"min-latency-ms,omitempty"`
+	// Statuses restricts the rule to these response codes ("429") or classes ("5xx").
+	Statuses []string `yaml:"statuses,omitempty" json:"statuses,omitempty"`
+}
+
+// ModelDiscoveryConfig configures periodic listing of upstream models for API-key credentials
+// (claude-api-key, gemini-api-key, codex-api-key with a base-url, and openai-compatibility).
+// Discovered models are registered next to the built-in or configured models of each credential.
+type ModelDiscoveryConfig struct {
+	// Enable turns discovery on.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// IntervalSeconds is the time between discovery rounds. Defaults to 3600; the minimum is 60.
+	IntervalSeconds int `yaml:"interval-seconds,omitempty" json:"interval-seconds,omitempty"`
+
+	// Providers limits discovery to the listed providers ("claude", "gemini", "codex" or
+	// "openai-compatibility"). Empty means all of them.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// Include keeps only discovered models matching one of these wildcard patterns. Empty keeps all.
+	Include []string `yaml:"include,omitempty" json:"include,omitempty"`
+
+	// Exclude drops discovered models matching any of these wildcard patterns.
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
 }
 
 // QuotaExceeded defines the behavior when API quota limits are exceeded.
@@ -173,8 +376,22 @@ type QuotaExceeded struct {
 // RoutingConfig configures how credentials are selected for requests.
 type RoutingConfig struct {
 	// Strategy selects the credential selection strategy.
-	// Supported values: "round-robin" (default), "fill-first".
+	// Supported values: "round-robin" (default), "fill-first", "weighted" (per-credential weight),
+	// "least-in-flight" (fewest concurrent executions), "latency" (lowest recent success latency)
+	// and "sticky" (conversation affinity for upstream prompt caching).
 	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// Affinity tunes the "sticky" strategy.
+	Affinity RoutingAffinityConfig `yaml:"affinity,omitempty" json:"affinity,omitempty"`
+}
+
+// RoutingAffinityConfig configures how the sticky strategy recognises conversations.
+type RoutingAffinityConfig struct {
+	// Header names the client header carrying an explicit conversation key. Defaults to "X-Session-Id".
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+
+	// MaxEntries bounds the remembered conversation bindings. Defaults to 10000.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
 }
 
 // ModelNameMapping defines a model ID rename mapping for a specific channel.
@@ -184,6 +401,72 @@ type ModelNameMapping struct {
 	Alias string `yaml:"alias" json:"alias"`
 }
 
+// ModelFallback declares the models tried, in order, when Model cannot be served.
+type ModelFallback struct {
+	Model     string   `yaml:"model" json:"model"`
+	Fallbacks []string `yaml:"fallbacks" json:"fallbacks"`
+}
+
+// HealthProbeConfig controls the background prober that sends a minimal request through
+// every enabled credential and feeds the outcome into its model state.
+type HealthProbeConfig struct {
+	// Enable turns the prober on. Default is false.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// IntervalSeconds is the time between probe rounds. <= 0 uses the default of 900 seconds.
+	IntervalSeconds int `yaml:"interval-seconds,omitempty" json:"interval-seconds,omitempty"`
+
+	// TimeoutSeconds bounds a single probe. <= 0 uses the default of 30 seconds.
+	TimeoutSeconds int `yaml:"timeout-seconds,omitempty" json:"timeout-seconds,omitempty"`
+
+	// Concurrency caps how many credentials are probed at once. <= 0 uses the default of 4.
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// Mode selects the probe call: "generate" (default) sends a one-token completion,
+	// "count-tokens" only counts tokens and is cheaper where the provider supports it. Providers
+	// that count tokens locally (Codex, Qwen, iFlow, OpenAI-compatible) are probed with generate.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// Models overrides the probed model per provider. Credentials of other providers are
+	// probed with the first model they register.
+	Models []HealthProbeModel `yaml:"models,omitempty" json:"models,omitempty"`
+}
+
+// HealthProbeModel pins the model probed for a provider.
+type HealthProbeModel struct {
+	Provider string `yaml:"provider" json:"provider"`
+	Model    string `yaml:"model" json:"model"`
+}
+
+// NotificationConfig controls webhook notifications for credential and quota events.
+type NotificationConfig struct {
+	// RefreshFailureThreshold is the number of consecutive refresh failures that triggers a
+	// refresh_failed event. <= 0 uses the default of 3.
+	RefreshFailureThreshold int `yaml:"refresh-failure-threshold,omitempty" json:"refresh-failure-threshold,omitempty"`
+
+	// Webhooks lists the endpoints receiving events.
+	Webhooks []WebhookConfig `yaml:"webhooks,omitempty" json:"webhooks,omitempty"`
+}
+
+// WebhookConfig describes one webhook endpoint.
+type WebhookConfig struct {
+	// URL receives a JSON POST per event.
+	URL string `yaml:"url" json:"url"`
+
+	// Secret signs each delivery with HMAC-SHA256. Empty disables signing.
+	Secret string `yaml:"secret,omitempty" json:"secret,omitempty"`
+
+	// Events limits delivery to these event types. Empty delivers every event.
+	Events []string `yaml:"events,omitempty" json:"events,omitempty"`
+
+	// MaxRetries is the number of retries after a failed delivery. < 0 disables retries;
+	// 0 uses the default of 3.
+	MaxRetries int `yaml:"max-retries,omitempty" json:"max-retries,omitempty"`
+
+	// TimeoutSeconds bounds a single delivery attempt. <= 0 uses the default of 10 seconds.
+	TimeoutSeconds int `yaml:"timeout-seconds,omitempty" json:"timeout-seconds,omitempty"`
+}
+
 // AmpModelMapping defines a model name mapping for Amp CLI requests.
 // When Amp requests a model that isn't available locally, this mapping
 // allows routing to an alternative model that IS available.
@@ -281,6 +564,9 @@ type ClaudeKey struct {
 	// ProxyURL overrides the global proxy setting for this API key if provided.
 	ProxyURL string `yaml:"proxy-url" json:"proxy-url"`
 
+	// Weight sets this credential's share of traffic under the weighted routing strategy (default 1).
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
 	// Models defines upstream model names and aliases for request routing.
 	Models []ClaudeModel `yaml:"models" json:"models"`
 
@@ -319,6 +605,9 @@ type CodexKey struct {
 	// ProxyURL overrides the global proxy setting for this API key if provided.
 	ProxyURL string `yaml:"proxy-url" json:"proxy-url"`
 
+	// Weight sets this credential's share of traffic under the weighted routing strategy (default 1).
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
 	// Models defines upstream model names and aliases for request routing.
 	Models []CodexModel `yaml:"models" json:"models"`
 
@@ -356,6 +645,9 @@ type GeminiKey struct {
 	// ProxyURL optionally overrides the global proxy for this API key.
 	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
 
+	// Weight sets this credential's share of traffic under the weighted routing strategy (default 1).
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
 	// Models defines upstream model names and aliases for request routing.
 	Models []GeminiModel `yaml:"models,omitempty" json:"models,omitempty"`
 
@@ -407,6 +699,9 @@ type OpenAICompatibilityAPIKey struct {
 
 	// ProxyURL overrides the global proxy setting for this API key if provided.
 	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// Weight sets this credential's share of traffic under the weighted routing strategy (default 1).
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
 }
 
 // OpenAICompatibilityModel represents a model configuration for OpenAI compatibility,
@@ -508,6 +803,8 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		cfg.LogsMaxTotalSizeMB = 0
 	}
 
+	cfg.Metrics.Path = normalizeMetricsPath(cfg.Metrics.Path)
+
 	// Sync request authentication providers with inline API keys for backwards compatibility.
 	syncInlineAccessProvider(&cfg)
 
@@ -677,6 +974,17 @@ func (cfg *Config) SanitizeGeminiKeys() {
 	cfg.GeminiKey = out
 }
 
+func normalizeMetricsPath(path string) string {
+	trimmed := strings.TrimSpace(path)
+	if trimmed == "" {
+		return DefaultMetricsPath
+	}
+	if !strings.HasPrefix(trimmed, "/") {
+		trimmed = "/" + trimmed
+	}
+	return trimmed
+}
+
 func normalizeModelPrefix(prefix string) string {
 	trimmed := strings.TrimSpace(prefix)
 	trimmed = strings.Trim(trimmed, "/")
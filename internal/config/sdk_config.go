@@ -4,6 +4,8 @@
 // debug settings, proxy configuration, and API keys.
 package config
 
+import "time"
+
 // SDKConfig represents the application's configuration, loaded from a YAML file.
 type SDKConfig struct {
 	// ProxyURL is the URL of an optional proxy server to use for outbound requests.
@@ -23,8 +25,36 @@ type SDKConfig struct {
 	// Access holds request authentication provider configuration.
 	Access AccessConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
 
+	// APIKeyLimits defines per-client-key throughput and token budget policies.
+	// An entry with api-key "*" applies to every key without a dedicated entry.
+	APIKeyLimits []APIKeyLimit `yaml:"api-key-limits,omitempty" json:"api-key-limits,omitempty"`
+
+	// ClientKeys are client API keys with a name and usage restrictions. They are accepted in
+	// addition to the bare keys listed in APIKeys.
+	ClientKeys []ClientKey `yaml:"client-keys,omitempty" json:"client-keys,omitempty"`
+
 	// Streaming configures server-side streaming behavior (keep-alives and safe bootstrap retries).
 	Streaming StreamingConfig `yaml:"streaming" json:"streaming"`
+
+	// ResponseCache configures the opt-in cache for identical non-streaming requests.
+	ResponseCache ResponseCacheConfig `yaml:"response-cache" json:"response-cache"`
+}
+
+// ResponseCacheConfig controls caching of successful non-streaming responses.
+// Requests are keyed on the client API key, source API format, requested model and normalized
+// payload, so clients never share cached responses.
+type ResponseCacheConfig struct {
+	// Enabled turns the cache on. Default is false.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// TTLSeconds is how long a cached response is served. <= 0 uses the default of 300 seconds.
+	TTLSeconds int `yaml:"ttl-seconds,omitempty" json:"ttl-seconds,omitempty"`
+
+	// MaxEntries caps the number of cached responses. <= 0 uses the default of 1000.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+
+	// MaxSizeMB caps the total size of cached response bodies. <= 0 uses the default of 64 MB.
+	MaxSizeMB int `yaml:"max-size-mb,omitempty" json:"max-size-mb,omitempty"`
 }
 
 // StreamingConfig holds server streaming behavior configuration.
@@ -39,6 +69,70 @@ type StreamingConfig struct {
 	BootstrapRetries int `yaml:"bootstrap-retries,omitempty" json:"bootstrap-retries,omitempty"`
 }
 
+// ClientKey is a client API key carrying a name and restrictions on what it may use.
+// Empty allow-lists place no restriction.
+type ClientKey struct {
+	// ID identifies keys issued through the management API.
+	ID string `yaml:"id,omitempty" json:"id,omitempty"`
+
+	// Key is the client API key in plaintext. Prefer KeyHash for new keys.
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+
+	// KeyHash is the "sha256:<hex>" digest of the client API key.
+	KeyHash string `yaml:"key-hash,omitempty" json:"key-hash,omitempty"`
+
+	// Name identifies the key holder in logs and usage statistics.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// AllowedModels lists model names or wildcard patterns (e.g., "gemini-*") the key may call.
+	AllowedModels []string `yaml:"allowed-models,omitempty" json:"allowed-models,omitempty"`
+
+	// AllowedProviders lists provider identifiers (e.g., "gemini-cli", "claude") the key may use.
+	AllowedProviders []string `yaml:"allowed-providers,omitempty" json:"allowed-providers,omitempty"`
+
+	// AllowedPrefixes lists credential prefixes the key may route to. Unprefixed credentials
+	// are reachable only when the list contains "".
+	AllowedPrefixes []string `yaml:"allowed-prefixes,omitempty" json:"allowed-prefixes,omitempty"`
+
+	// ExpiresAt rejects the key after this instant. Zero means the key never expires.
+	ExpiresAt time.Time `yaml:"expires-at,omitempty" json:"expires-at,omitempty"`
+
+	// Tags are free-form labels for the key.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// CreatedAt records when the key was issued.
+	CreatedAt time.Time `yaml:"created-at,omitempty" json:"created-at,omitempty"`
+
+	// RevokedAt rejects the key from this instant on. Zero means the key is active.
+	RevokedAt time.Time `yaml:"revoked-at,omitempty" json:"revoked-at,omitempty"`
+}
+
+// APIKeyLimit describes the rate limit, token budget and cost budget policy for one client API key.
+// Zero values disable the corresponding limit.
+type APIKeyLimit struct {
+	// APIKey is the api-keys entry the policy applies to. Structured client-keys are referenced by
+	// their id, or by their key-hash when they have no id. "*" matches every key without its own entry.
+	APIKey string `yaml:"api-key" json:"api-key"`
+
+	// RequestsPerMinute caps requests accepted within any sliding 60-second window.
+	RequestsPerMinute int `yaml:"requests-per-minute,omitempty" json:"requests-per-minute,omitempty"`
+
+	// MaxConcurrentStreams caps simultaneously open streaming responses.
+	MaxConcurrentStreams int `yaml:"max-concurrent-streams,omitempty" json:"max-concurrent-streams,omitempty"`
+
+	// DailyTokenBudget caps total tokens consumed per UTC calendar day.
+	DailyTokenBudget int64 `yaml:"daily-token-budget,omitempty" json:"daily-token-budget,omitempty"`
+
+	// MonthlyTokenBudget caps total tokens consumed per UTC calendar month.
+	MonthlyTokenBudget int64 `yaml:"monthly-token-budget,omitempty" json:"monthly-token-budget,omitempty"`
+
+	// DailyCostBudget caps spend in USD per UTC calendar day, priced with the pricing table.
+	DailyCostBudget float64 `yaml:"daily-cost-budget,omitempty" json:"daily-cost-budget,omitempty"`
+
+	// MonthlyCostBudget caps spend in USD per UTC calendar month, priced with the pricing table.
+	MonthlyCostBudget float64 `yaml:"monthly-cost-budget,omitempty" json:"monthly-cost-budget,omitempty"`
+}
+
 // AccessConfig groups request authentication providers.
 type AccessConfig struct {
 	// Providers lists configured authentication providers.
@@ -87,6 +181,24 @@ func (c *SDKConfig) ConfigAPIKeyProvider() *AccessProvider {
 	return nil
 }
 
+// MakeInlineAccessProvider constructs the inline provider serving the top-level api-keys and
+// client-keys. It returns nil when neither is configured.
+func MakeInlineAccessProvider(cfg *SDKConfig) *AccessProvider {
+	if cfg == nil {
+		return nil
+	}
+	if provider := MakeInlineAPIKeyProvider(cfg.APIKeys); provider != nil {
+		return provider
+	}
+	if len(cfg.ClientKeys) == 0 {
+		return nil
+	}
+	return &AccessProvider{
+		Name: DefaultAccessProviderName,
+		Type: AccessProviderTypeConfigAPIKey,
+	}
+}
+
 // MakeInlineAPIKeyProvider constructs an inline API key provider configuration.
 // It returns nil when no keys are supplied.
 func MakeInlineAPIKeyProvider(keys []string) *AccessProvider {
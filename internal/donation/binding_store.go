@@ -6,9 +6,12 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 )
 
-const bindingsFileName = "bindings.json"
+// BindingsFileName is the file, relative to the auth directory, holding account bindings.
+const BindingsFileName = misc.DonationBindingsFileName
 
 // BindingStore manages user bindings with JSON file persistence.
 type BindingStore struct {
@@ -24,7 +27,7 @@ type bindingsFile struct {
 
 // NewBindingStore creates a new binding store with the given base directory.
 func NewBindingStore(baseDir string) (*BindingStore, error) {
-	filePath := filepath.Join(baseDir, bindingsFileName)
+	filePath := filepath.Join(baseDir, BindingsFileName)
 	store := &BindingStore{
 		filePath: filePath,
 		bindings: make(map[int]*UserBinding),
@@ -2,10 +2,16 @@ package donation
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,34 +22,56 @@ import (
 type DonationHandler struct {
 	linuxDoService *LinuxDoConnectService
 	newAPIService  *NewAPIService
-	sessionStore   *SessionStore
+	sessionStore   SessionStore
 	bindingStore   *BindingStore
+	ledgerStore    *LedgerStore
 	roleService    *RoleService
 	logger         *DonationLogger
 	quotaAmount    int64
+	policy         DonationPolicy
+}
+
+// DonationPolicy controls how donation confirmations are verified and limited.
+type DonationPolicy struct {
+	// CallbackSecret verifies payment provider callbacks; empty disables the callback endpoint.
+	CallbackSecret string
+	// MaxPendingPerUser caps unverified donations per user.
+	MaxPendingPerUser int
+	// DailyLimitPerUser caps donations a user may report within 24 hours, excluding failed ones.
+	DailyLimitPerUser int
 }
 
 // NewDonationHandler creates a new donation handler with all dependencies.
 func NewDonationHandler(
 	linuxDoService *LinuxDoConnectService,
 	newAPIService *NewAPIService,
-	sessionStore *SessionStore,
+	sessionStore SessionStore,
 	bindingStore *BindingStore,
+	ledgerStore *LedgerStore,
 	roleService *RoleService,
 	logger *DonationLogger,
 	quotaAmount int64,
+	policy DonationPolicy,
 ) *DonationHandler {
 	if quotaAmount <= 0 {
 		quotaAmount = 2000000 // Default $20
 	}
+	if policy.MaxPendingPerUser <= 0 {
+		policy.MaxPendingPerUser = 1
+	}
+	if policy.DailyLimitPerUser <= 0 {
+		policy.DailyLimitPerUser = 1
+	}
 	return &DonationHandler{
 		linuxDoService: linuxDoService,
 		newAPIService:  newAPIService,
 		sessionStore:   sessionStore,
 		bindingStore:   bindingStore,
+		ledgerStore:    ledgerStore,
 		roleService:    roleService,
 		logger:         logger,
 		quotaAmount:    quotaAmount,
+		policy:         policy,
 	}
 }
 
@@ -337,7 +365,9 @@ func (h *DonationHandler) HandleDonatePage(c *gin.Context) {
 	})
 }
 
-// HandleDonateConfirm handles POST /donate/confirm - confirms donation and adds quota.
+// HandleDonateConfirm handles POST /donate/confirm - records a pending donation in the ledger.
+// The request must carry an Idempotency-Key header (or idempotency_key field) and a payment
+// reference. Quota is only added after the donation is verified by an admin or the payment callback.
 func (h *DonationHandler) HandleDonateConfirm(c *gin.Context) {
 	session := GetSessionFromContext(c)
 	if session == nil {
@@ -354,40 +384,338 @@ func (h *DonationHandler) HandleDonateConfirm(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	// Parse request
+	var req struct {
+		PaymentReference string `json:"payment_reference"`
+		IdempotencyKey   string `json:"idempotency_key"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "invalid request body",
+		})
+		return
+	}
+	idempotencyKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if idempotencyKey == "" {
+		idempotencyKey = strings.TrimSpace(req.IdempotencyKey)
+	}
+	paymentReference := strings.TrimSpace(req.PaymentReference)
+	if idempotencyKey == "" || paymentReference == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Idempotency-Key and payment_reference are required",
+		})
+		return
+	}
+
+	// The ledger checks the payment reference and per-user limits under its lock; replayed
+	// confirmations return the original entry without re-checking them.
+	record, created, err := h.ledgerStore.CreatePending(&DonationRecord{
+		IdempotencyKey:   idempotencyKey,
+		LinuxDoID:        session.LinuxDoID,
+		Username:         session.Username,
+		NewAPIUserID:     session.NewAPIUserID,
+		QuotaAmount:      h.quotaAmount,
+		PaymentReference: paymentReference,
+	}, DonationLimits{MaxPending: h.policy.MaxPendingPerUser, Daily: h.policy.DailyLimitPerUser})
+	switch {
+	case errors.Is(err, ErrDuplicatePaymentReference):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "duplicate_payment",
+			"message": "this payment reference has already been submitted",
+		})
+		return
+	case errors.Is(err, ErrPendingLimit):
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":   "pending_limit",
+			"message": "you already have a donation awaiting verification",
+		})
+		return
+	case errors.Is(err, ErrDailyLimit):
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":   "daily_limit",
+			"message": "daily donation limit reached, please try again later",
+		})
+		return
+	case err != nil:
+		log.WithError(err).Error("failed to record donation")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "ledger_failed",
+			"message": "failed to record donation",
+		})
+		return
+	}
+	if !created {
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "donation": record})
+		return
+	}
+
+	h.logger.LogDonationStatus(record)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":   "pending",
+		"message":  "donation recorded, quota will be added once the payment is verified",
+		"donation": record,
+	})
+}
+
+// HandleDonationHistory handles GET /donate/history - returns the current user's donations.
+func (h *DonationHandler) HandleDonationHistory(c *gin.Context) {
+	session := GetSessionFromContext(c)
+	if session == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"donations": h.ledgerStore.ListByUser(session.LinuxDoID)})
+}
+
+// HandleAdminListDonations handles GET /donate/admin/donations - lists the ledger, optionally filtered by ?status=.
+func (h *DonationHandler) HandleAdminListDonations(c *gin.Context) {
+	status := DonationStatus(strings.ToLower(strings.TrimSpace(c.Query("status"))))
+	c.JSON(http.StatusOK, gin.H{"donations": h.ledgerStore.List(status)})
+}
+
+// HandleAdminApproveDonation handles POST /donate/admin/donations/:id/approve - verifies the payment and credits quota.
+// Failed donations may be approved again to retry crediting.
+func (h *DonationHandler) HandleAdminApproveDonation(c *gin.Context) {
+	session := GetSessionFromContext(c)
+	if session == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	record, err := h.ledgerStore.Transition(c.Param("id"), []DonationStatus{DonationPending, DonationFailed}, DonationVerified, func(r *DonationRecord) {
+		r.VerifiedBy = session.Username
+		r.FailureReason = ""
+	})
+	if err != nil {
+		h.writeLedgerError(c, record, err)
+		return
+	}
+	h.logger.LogDonationStatus(record)
+
+	h.creditDonation(c, record)
+}
+
+// HandleAdminRejectDonation handles POST /donate/admin/donations/:id/reject - marks a pending donation as failed.
+func (h *DonationHandler) HandleAdminRejectDonation(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&req)
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		reason = "rejected by administrator"
+	}
+
+	record, err := h.ledgerStore.Transition(c.Param("id"), []DonationStatus{DonationPending}, DonationFailed, func(r *DonationRecord) {
+		r.FailureReason = reason
+	})
+	if err != nil {
+		h.writeLedgerError(c, record, err)
+		return
+	}
+	h.logger.LogDonationStatus(record)
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "donation": record})
+}
 
-	// Add quota
-	err := h.newAPIService.AddQuota(ctx, session.NewAPIUserID, h.quotaAmount)
+// HandleAdminListSessions handles GET /donate/admin/sessions - lists active login sessions.
+func (h *DonationHandler) HandleAdminListSessions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"sessions": h.sessionStore.List()})
+}
+
+// HandleAdminRevokeSession handles DELETE /donate/admin/sessions/:key - revokes one session.
+func (h *DonationHandler) HandleAdminRevokeSession(c *gin.Context) {
+	key := strings.TrimSpace(c.Param("key"))
+	if !h.sessionStore.Revoke(key) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+		return
+	}
+	h.logger.LogSessionRevoked(adminID(c), key, 1)
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "revoked": 1})
+}
+
+// HandleAdminRevokeUserSessions handles DELETE /donate/admin/users/:linux_do_id/sessions -
+// signs a user out everywhere.
+func (h *DonationHandler) HandleAdminRevokeUserSessions(c *gin.Context) {
+	linuxDoID, err := strconv.Atoi(c.Param("linux_do_id"))
+	if err != nil || linuxDoID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "message": "invalid linux_do_id"})
+		return
+	}
+
+	revoked := h.sessionStore.RevokeUser(linuxDoID)
+	h.logger.LogSessionRevoked(adminID(c), "user:"+strconv.Itoa(linuxDoID), revoked)
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "revoked": revoked})
+}
+
+// adminID returns the Linux Do ID of the admin making the request.
+func adminID(c *gin.Context) int {
+	if session := GetSessionFromContext(c); session != nil {
+		return session.LinuxDoID
+	}
+	return 0
+}
+
+// HandlePaymentCallback handles POST /donate/callback - payment provider notification.
+// The body must be signed with the configured callback secret: the X-Donation-Signature header
+// carries the hex encoded HMAC-SHA256 of the raw body. Repeated callbacks are idempotent.
+func (h *DonationHandler) HandlePaymentCallback(c *gin.Context) {
+	if h.policy.CallbackSecret == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 64<<10))
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "message": "failed to read body"})
+		return
+	}
+	if !verifyCallbackSignature(h.policy.CallbackSecret, body, c.GetHeader(CallbackSignatureHeader)) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_signature"})
+		return
+	}
+
+	var req struct {
+		DonationID       string `json:"donation_id"`
+		PaymentReference string `json:"payment_reference"`
+		Status           string `json:"status"`
+		Reason           string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "message": "invalid JSON body"})
+		return
+	}
+
+	var record *DonationRecord
+	if req.DonationID != "" {
+		record = h.ledgerStore.Get(req.DonationID)
+	} else {
+		record = h.ledgerStore.GetByPaymentReference(strings.TrimSpace(req.PaymentReference))
+	}
+	if record == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "donation_not_found"})
+		return
+	}
+
+	switch strings.ToLower(strings.TrimSpace(req.Status)) {
+	case "paid", "succeeded", "success":
+		verified, errTransition := h.ledgerStore.Transition(record.ID, []DonationStatus{DonationPending}, DonationVerified, func(r *DonationRecord) {
+			r.VerifiedBy = "payment-callback"
+		})
+		if errTransition != nil {
+			if errors.Is(errTransition, ErrDonationStateConflict) && verified != nil && verified.Status != DonationFailed {
+				// Already processed; acknowledge so the provider stops retrying.
+				c.JSON(http.StatusOK, gin.H{"status": "ok", "donation": verified})
+				return
+			}
+			h.writeLedgerError(c, verified, errTransition)
+			return
+		}
+		h.logger.LogDonationStatus(verified)
+		h.creditDonation(c, verified)
+	case "failed", "refunded", "cancelled", "canceled":
+		reason := strings.TrimSpace(req.Reason)
+		if reason == "" {
+			reason = "payment " + strings.ToLower(strings.TrimSpace(req.Status))
+		}
+		failed, errTransition := h.ledgerStore.Transition(record.ID, []DonationStatus{DonationPending}, DonationFailed, func(r *DonationRecord) {
+			r.FailureReason = reason
+		})
+		if errTransition != nil {
+			h.writeLedgerError(c, failed, errTransition)
+			return
+		}
+		h.logger.LogDonationStatus(failed)
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "donation": failed})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "message": "unknown payment status"})
+	}
+}
+
+// creditDonation adds quota for a verified donation and records the outcome in the ledger.
+func (h *DonationHandler) creditDonation(c *gin.Context, record *DonationRecord) {
+	ctx := context.Background()
+
+	if err := h.newAPIService.AddQuota(ctx, record.NewAPIUserID, record.QuotaAmount); err != nil {
 		log.WithError(err).WithFields(log.Fields{
-			"user_id":      session.LinuxDoID,
-			"newapi_id":    session.NewAPIUserID,
-			"quota_amount": h.quotaAmount,
+			"donation_id":  record.ID,
+			"user_id":      record.LinuxDoID,
+			"newapi_id":    record.NewAPIUserID,
+			"quota_amount": record.QuotaAmount,
 		}).Error("failed to add quota")
-		h.logger.LogDonationError(session.LinuxDoID, session.NewAPIUserID, h.quotaAmount, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "quota_failed",
-			"message": "failed to add quota, please contact administrator",
+		h.logger.LogDonationError(record.LinuxDoID, record.NewAPIUserID, record.QuotaAmount, err)
+		failed, errTransition := h.ledgerStore.Transition(record.ID, []DonationStatus{DonationVerified}, DonationFailed, func(r *DonationRecord) {
+			r.FailureReason = "failed to add quota: " + filterSensitive(err.Error())
+		})
+		if errTransition != nil {
+			log.WithError(errTransition).WithField("donation_id", record.ID).Error("failed to record donation failure")
+			failed = record
+		}
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":    "quota_failed",
+			"message":  "failed to add quota, the donation can be approved again to retry",
+			"donation": failed,
 		})
 		return
 	}
 
-	// Log successful donation
-	h.logger.LogDonation(session.LinuxDoID, session.NewAPIUserID, h.quotaAmount)
+	credited, err := h.ledgerStore.Transition(record.ID, []DonationStatus{DonationVerified}, DonationCredited, func(r *DonationRecord) {
+		r.CreditedAt = r.UpdatedAt
+	})
+	if err != nil {
+		// The quota was added; surface the ledger problem without asking anyone to retry.
+		log.WithError(err).WithField("donation_id", record.ID).Error("quota added but ledger update failed")
+		credited = record
+	}
 
-	log.WithFields(log.Fields{
-		"linux_do_id":    session.LinuxDoID,
-		"newapi_user_id": session.NewAPIUserID,
-		"quota_amount":   h.quotaAmount,
-	}).Info("donation processed successfully")
+	// Log successful donation
+	h.logger.LogDonation(record.LinuxDoID, record.NewAPIUserID, record.QuotaAmount)
 
 	c.JSON(http.StatusOK, gin.H{
-		"status":       "ok",
-		"message":      "donation successful, quota added",
-		"quota_amount": h.quotaAmount,
+		"status":   "ok",
+		"message":  "donation verified, quota added",
+		"donation": credited,
 	})
 }
 
+// writeLedgerError renders a ledger lookup or state transition error.
+func (h *DonationHandler) writeLedgerError(c *gin.Context, record *DonationRecord, err error) {
+	switch {
+	case errors.Is(err, ErrDonationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "donation_not_found"})
+	case errors.Is(err, ErrDonationStateConflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "invalid_state",
+			"message":  "donation is " + string(record.Status),
+			"donation": record,
+		})
+	default:
+		log.WithError(err).Error("failed to update donation ledger")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "ledger_failed",
+			"message": "failed to update donation ledger",
+		})
+	}
+}
+
+// verifyCallbackSignature checks the hex encoded HMAC-SHA256 signature of body.
+func verifyCallbackSignature(secret string, body []byte, signature string) bool {
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+	provided, err := hex.DecodeString(signature)
+	if err != nil || len(provided) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(provided, mac.Sum(nil))
+}
+
 // HandleStatus handles GET /status - returns current user status (public endpoint).
 func (h *DonationHandler) HandleStatus(c *gin.Context) {
 	session := GetSessionFromContext(c)
@@ -0,0 +1,306 @@
+package donation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+)
+
+// LedgerFileName is the file, relative to the auth directory, holding the donation ledger.
+const LedgerFileName = misc.DonationLedgerFileName
+
+var (
+	// ErrDonationNotFound is returned when a ledger entry does not exist.
+	ErrDonationNotFound = errors.New("donation not found")
+	// ErrDonationStateConflict is returned when a ledger entry is not in the expected state.
+	ErrDonationStateConflict = errors.New("donation is not in the expected state")
+	// ErrDuplicatePaymentReference is returned when a payment reference already backs a donation.
+	ErrDuplicatePaymentReference = errors.New("payment reference has already been submitted")
+	// ErrPendingLimit is returned when a user has too many donations awaiting verification.
+	ErrPendingLimit = errors.New("pending donation limit reached")
+	// ErrDailyLimit is returned when a user reached the daily donation limit.
+	ErrDailyLimit = errors.New("daily donation limit reached")
+)
+
+// DonationLimits bounds the donations a user may submit. Zero or negative values disable a check.
+type DonationLimits struct {
+	// MaxPending is the number of pending or verified donations a user may have at once.
+	MaxPending int
+	// Daily is the number of donations a user may submit within 24 hours.
+	Daily int
+}
+
+// LedgerStore records donation confirmations with JSON file persistence.
+type LedgerStore struct {
+	mu       sync.RWMutex
+	filePath string
+	records  map[string]*DonationRecord // keyed by record ID
+}
+
+// ledgerFile represents the JSON file structure.
+type ledgerFile struct {
+	Donations []*DonationRecord `json:"donations"`
+}
+
+// NewLedgerStore creates a new donation ledger with the given base directory.
+func NewLedgerStore(baseDir string) (*LedgerStore, error) {
+	store := &LedgerStore{
+		filePath: filepath.Join(baseDir, LedgerFileName),
+		records:  make(map[string]*DonationRecord),
+	}
+
+	// Load existing ledger if file exists
+	if err := store.load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// load reads the ledger from the JSON file.
+func (s *LedgerStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+
+	var file ledgerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	s.records = make(map[string]*DonationRecord)
+	for _, record := range file.Donations {
+		if record != nil && record.ID != "" {
+			s.records[record.ID] = record
+		}
+	}
+
+	return nil
+}
+
+// save writes the ledger to the JSON file. The file is replaced atomically so a crash
+// never leaves a truncated ledger behind.
+func (s *LedgerStore) save() error {
+	// Ensure directory exists
+	dir := filepath.Dir(s.filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file := ledgerFile{Donations: s.sortedLocked(func(*DonationRecord) bool { return true })}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.filePath)
+}
+
+// sortedLocked returns copies of the records matching keep, newest first.
+func (s *LedgerStore) sortedLocked(keep func(*DonationRecord) bool) []*DonationRecord {
+	out := make([]*DonationRecord, 0, len(s.records))
+	for _, record := range s.records {
+		if keep(record) {
+			copied := *record
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID > out[j].ID
+		}
+		return out[i].CreatedAt.After(out[j].CreatedAt)
+	})
+	return out
+}
+
+// CreatePending records a new pending donation. When the user already submitted a donation with
+// the same idempotency key, the existing entry is returned and created is false. Otherwise the
+// payment reference and limits are checked under the ledger lock, so concurrent submissions cannot
+// both pass: ErrDuplicatePaymentReference, ErrPendingLimit or ErrDailyLimit is returned.
+func (s *LedgerStore) CreatePending(record *DonationRecord, limits DonationLimits) (stored *DonationRecord, created bool, err error) {
+	if record == nil {
+		return nil, false, fmt.Errorf("donation record is nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing := s.findByIdempotencyKeyLocked(record.LinuxDoID, record.IdempotencyKey); existing != nil {
+		return existing, false, nil
+	}
+	if record.PaymentReference != "" {
+		for _, existing := range s.records {
+			if existing.PaymentReference == record.PaymentReference && existing.Status != DonationFailed {
+				return nil, false, ErrDuplicatePaymentReference
+			}
+		}
+	}
+	if limits.MaxPending > 0 && s.countForUserLocked(record.LinuxDoID, time.Time{}, DonationPending, DonationVerified) >= limits.MaxPending {
+		return nil, false, ErrPendingLimit
+	}
+	since := time.Now().Add(-24 * time.Hour)
+	if limits.Daily > 0 && s.countForUserLocked(record.LinuxDoID, since, DonationPending, DonationVerified, DonationCredited) >= limits.Daily {
+		return nil, false, ErrDailyLimit
+	}
+
+	if record.ID == "" {
+		id, errID := generateState()
+		if errID != nil {
+			return nil, false, errID
+		}
+		record.ID = id
+	}
+	now := time.Now().UTC()
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = now
+	}
+	record.UpdatedAt = now
+	record.Status = DonationPending
+
+	entry := *record
+	s.records[entry.ID] = &entry
+	if err := s.save(); err != nil {
+		delete(s.records, entry.ID)
+		return nil, false, err
+	}
+	copied := entry
+	return &copied, true, nil
+}
+
+// Transition moves a donation from one of the allowed states to the next state, applying update
+// to the entry before it is persisted. It fails with ErrDonationStateConflict when the donation
+// is in any other state, which makes concurrent approvals and repeated callbacks safe.
+func (s *LedgerStore) Transition(id string, from []DonationStatus, to DonationStatus, update func(*DonationRecord)) (*DonationRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[id]
+	if !ok {
+		return nil, ErrDonationNotFound
+	}
+	allowed := false
+	for _, status := range from {
+		if record.Status == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		copied := *record
+		return &copied, ErrDonationStateConflict
+	}
+
+	previous := *record
+	record.Status = to
+	record.UpdatedAt = time.Now().UTC()
+	if update != nil {
+		update(record)
+	}
+	if err := s.save(); err != nil {
+		*record = previous
+		return nil, err
+	}
+	copied := *record
+	return &copied, nil
+}
+
+// FindByIdempotencyKey returns the user's donation submitted with key. Returns nil if none exists.
+func (s *LedgerStore) FindByIdempotencyKey(linuxDoID int, key string) *DonationRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.findByIdempotencyKeyLocked(linuxDoID, key)
+}
+
+func (s *LedgerStore) findByIdempotencyKeyLocked(linuxDoID int, key string) *DonationRecord {
+	for _, record := range s.records {
+		if record.LinuxDoID == linuxDoID && record.IdempotencyKey == key {
+			copied := *record
+			return &copied
+		}
+	}
+	return nil
+}
+
+// Get retrieves a donation by ID. Returns nil if it does not exist.
+func (s *LedgerStore) Get(id string) *DonationRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.records[id]
+	if !ok {
+		return nil
+	}
+	copied := *record
+	return &copied
+}
+
+// GetByPaymentReference retrieves the newest donation carrying the given payment reference.
+// Returns nil if none exists.
+func (s *LedgerStore) GetByPaymentReference(reference string) *DonationRecord {
+	if reference == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := s.sortedLocked(func(record *DonationRecord) bool { return record.PaymentReference == reference })
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0]
+}
+
+// ListByUser returns the donation history of a Linux Do user, newest first.
+func (s *LedgerStore) ListByUser(linuxDoID int) []*DonationRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedLocked(func(record *DonationRecord) bool { return record.LinuxDoID == linuxDoID })
+}
+
+// List returns all donations, optionally filtered by status, newest first.
+func (s *LedgerStore) List(status DonationStatus) []*DonationRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedLocked(func(record *DonationRecord) bool { return status == "" || record.Status == status })
+}
+
+// CountForUser returns how many of a user's donations created at or after since are in one of the
+// given states.
+func (s *LedgerStore) CountForUser(linuxDoID int, since time.Time, statuses ...DonationStatus) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.countForUserLocked(linuxDoID, since, statuses...)
+}
+
+func (s *LedgerStore) countForUserLocked(linuxDoID int, since time.Time, statuses ...DonationStatus) int {
+	count := 0
+	for _, record := range s.records {
+		if record.LinuxDoID != linuxDoID || record.CreatedAt.Before(since) {
+			continue
+		}
+		for _, status := range statuses {
+			if record.Status == status {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,132 @@
+package donation
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLedgerStoreIdempotentCreateAndTransitions(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLedgerStore(dir)
+	if err != nil {
+		t.Fatalf("NewLedgerStore() error = %v", err)
+	}
+
+	first, created, err := store.CreatePending(&DonationRecord{IdempotencyKey: "k1", LinuxDoID: 7, NewAPIUserID: 70, QuotaAmount: 100, PaymentReference: "order-1"}, DonationLimits{})
+	if err != nil || !created {
+		t.Fatalf("CreatePending() = %v, %t, %v; want created", first, created, err)
+	}
+	replay, created, err := store.CreatePending(&DonationRecord{IdempotencyKey: "k1", LinuxDoID: 7, NewAPIUserID: 70, QuotaAmount: 100}, DonationLimits{})
+	if err != nil || created || replay.ID != first.ID {
+		t.Fatalf("replayed CreatePending() = %v, %t, %v; want existing entry %s", replay, created, err, first.ID)
+	}
+
+	verified, err := store.Transition(first.ID, []DonationStatus{DonationPending}, DonationVerified, func(r *DonationRecord) { r.VerifiedBy = "admin" })
+	if err != nil || verified.Status != DonationVerified {
+		t.Fatalf("Transition(pending->verified) = %v, %v", verified, err)
+	}
+	if _, err = store.Transition(first.ID, []DonationStatus{DonationPending}, DonationVerified, nil); !errors.Is(err, ErrDonationStateConflict) {
+		t.Fatalf("second approval error = %v, want ErrDonationStateConflict", err)
+	}
+	if _, err = store.Transition("missing", []DonationStatus{DonationPending}, DonationVerified, nil); !errors.Is(err, ErrDonationNotFound) {
+		t.Fatalf("missing donation error = %v, want ErrDonationNotFound", err)
+	}
+
+	reloaded, err := NewLedgerStore(dir)
+	if err != nil {
+		t.Fatalf("reload NewLedgerStore() error = %v", err)
+	}
+	got := reloaded.Get(first.ID)
+	if got == nil || got.Status != DonationVerified || got.VerifiedBy != "admin" {
+		t.Fatalf("reloaded donation = %+v, want verified by admin", got)
+	}
+	if n := reloaded.CountForUser(7, time.Now().Add(-time.Hour), DonationVerified); n != 1 {
+		t.Fatalf("CountForUser() = %d, want 1", n)
+	}
+	if ref := reloaded.GetByPaymentReference("order-1"); ref == nil || ref.ID != first.ID {
+		t.Fatalf("GetByPaymentReference() = %v, want %s", ref, first.ID)
+	}
+}
+
+func TestLedgerStoreCreatePendingEnforcesLimitsConcurrently(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference func(i int) string
+		limits    DonationLimits
+		wantErr   error
+	}{
+		{
+			name:      "same payment reference",
+			reference: func(int) string { return "order-shared" },
+			limits:    DonationLimits{MaxPending: 100, Daily: 100},
+			wantErr:   ErrDuplicatePaymentReference,
+		},
+		{
+			name:      "pending limit",
+			reference: func(i int) string { return fmt.Sprintf("order-%d", i) },
+			limits:    DonationLimits{MaxPending: 1, Daily: 100},
+			wantErr:   ErrPendingLimit,
+		},
+		{
+			name:      "daily limit",
+			reference: func(i int) string { return fmt.Sprintf("order-%d", i) },
+			limits:    DonationLimits{MaxPending: 100, Daily: 1},
+			wantErr:   ErrDailyLimit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewLedgerStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewLedgerStore() error = %v", err)
+			}
+			const submits = 8
+			errs := make([]error, submits)
+			var wg sync.WaitGroup
+			for i := 0; i < submits; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, _, errs[i] = store.CreatePending(&DonationRecord{IdempotencyKey: fmt.Sprintf("k%d", i), LinuxDoID: 7, PaymentReference: tt.reference(i)}, tt.limits)
+				}(i)
+			}
+			wg.Wait()
+
+			created := 0
+			for _, errCreate := range errs {
+				switch {
+				case errCreate == nil:
+					created++
+				case !errors.Is(errCreate, tt.wantErr):
+					t.Fatalf("CreatePending() error = %v, want %v", errCreate, tt.wantErr)
+				}
+			}
+			if created != 1 || len(store.List("")) != 1 {
+				t.Fatalf("created %d donations (%d stored), want exactly one", created, len(store.List("")))
+			}
+		})
+	}
+}
+
+func TestVerifyCallbackSignature(t *testing.T) {
+	body := []byte(`{"donation_id":"abc","status":"paid"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	if !verifyCallbackSignature("secret", body, signature) || !verifyCallbackSignature("secret", body, "sha256="+signature) {
+		t.Fatalf("verifyCallbackSignature() rejected a valid signature")
+	}
+	if verifyCallbackSignature("secret", []byte(`{"donation_id":"abc","status":"paid "}`), signature) {
+		t.Fatalf("verifyCallbackSignature() accepted a tampered body")
+	}
+	if verifyCallbackSignature("other", body, signature) || verifyCallbackSignature("secret", body, "") {
+		t.Fatalf("verifyCallbackSignature() accepted an invalid signature")
+	}
+}
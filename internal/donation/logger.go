@@ -67,6 +67,22 @@ func (l *DonationLogger) LogDonationError(linuxDoID, newAPIUserID int, quotaAmou
 	}).Error("donation failed")
 }
 
+// LogDonationStatus logs a donation ledger state change.
+func (l *DonationLogger) LogDonationStatus(record *DonationRecord) {
+	if record == nil {
+		return
+	}
+	l.logger.WithFields(log.Fields{
+		"event":          "donation_" + string(record.Status),
+		"donation_id":    record.ID,
+		"linux_do_id":    record.LinuxDoID,
+		"newapi_user_id": record.NewAPIUserID,
+		"quota_amount":   record.QuotaAmount,
+		"verified_by":    record.VerifiedBy,
+		"timestamp":      time.Now().UTC().Format(time.RFC3339),
+	}).Info("donation status changed")
+}
+
 // LogBinding logs a user binding event.
 func (l *DonationLogger) LogBinding(linuxDoID, newAPIUserID int) {
 	l.logger.WithFields(log.Fields{
@@ -97,6 +113,17 @@ func (l *DonationLogger) LogLogout(linuxDoID int) {
 	}).Info("user logged out")
 }
 
+// LogSessionRevoked logs an administrator revoking login sessions.
+func (l *DonationLogger) LogSessionRevoked(adminLinuxDoID int, target string, count int) {
+	l.logger.WithFields(log.Fields{
+		"event":     "session_revoked",
+		"admin_id":  adminLinuxDoID,
+		"target":    target,
+		"count":     count,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	}).Info("sessions revoked")
+}
+
 // LogError logs an error with context.
 func (l *DonationLogger) LogError(operation string, err error, context map[string]interface{}) {
 	fields := log.Fields{
@@ -15,12 +15,14 @@ const (
 	RoleUser = "user"
 	// RoleAdmin is the role for administrators.
 	RoleAdmin = "admin"
+	// CallbackSignatureHeader carries the HMAC-SHA256 signature of payment callbacks.
+	CallbackSignatureHeader = "X-Donation-Signature"
 )
 
 // AuthMiddleware creates a middleware that validates session tokens.
 // It reads the session_id from cookies and validates it against the session store.
 // If valid, the session is stored in the context for downstream handlers.
-func AuthMiddleware(sessionStore *SessionStore) gin.HandlerFunc {
+func AuthMiddleware(sessionStore SessionStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get session ID from cookie
 		sessionID, err := c.Cookie(SessionCookieName)
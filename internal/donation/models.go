@@ -62,3 +62,47 @@ type NewAPIUser struct {
 	// Quota is the user's current quota balance.
 	Quota int64 `json:"quota"`
 }
+
+// DonationStatus is the lifecycle state of a donation ledger entry.
+type DonationStatus string
+
+const (
+	// DonationPending means the user reported a donation that has not been verified yet.
+	DonationPending DonationStatus = "pending"
+	// DonationVerified means an admin or the payment callback confirmed the payment.
+	DonationVerified DonationStatus = "verified"
+	// DonationCredited means the quota was added to the user's new-api account.
+	DonationCredited DonationStatus = "credited"
+	// DonationFailed means the donation was rejected or crediting the quota failed.
+	DonationFailed DonationStatus = "failed"
+)
+
+// DonationRecord is a single entry in the donation ledger.
+type DonationRecord struct {
+	// ID is the unique ledger entry identifier.
+	ID string `json:"id"`
+	// IdempotencyKey is the client supplied key that deduplicates confirmations per user.
+	IdempotencyKey string `json:"idempotency_key"`
+	// LinuxDoID is the donating user's Linux Do platform ID.
+	LinuxDoID int `json:"linux_do_id"`
+	// Username is the donating user's Linux Do login name.
+	Username string `json:"username"`
+	// NewAPIUserID is the new-api account credited for the donation.
+	NewAPIUserID int `json:"newapi_user_id"`
+	// QuotaAmount is the quota credited once the donation is verified.
+	QuotaAmount int64 `json:"quota_amount"`
+	// PaymentReference is the payment proof supplied by the user (order or transaction number).
+	PaymentReference string `json:"payment_reference,omitempty"`
+	// Status is the current lifecycle state.
+	Status DonationStatus `json:"status"`
+	// VerifiedBy records who verified the payment: an admin username or "payment-callback".
+	VerifiedBy string `json:"verified_by,omitempty"`
+	// FailureReason explains why the donation was rejected or could not be credited.
+	FailureReason string `json:"failure_reason,omitempty"`
+	// CreatedAt is when the donation was reported.
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is when the entry last changed state.
+	UpdatedAt time.Time `json:"updated_at"`
+	// CreditedAt is when the quota was added (zero until credited).
+	CreditedAt time.Time `json:"credited_at,omitempty"`
+}
@@ -1,6 +1,11 @@
 package donation
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	log "github.com/sirupsen/logrus"
@@ -8,14 +13,16 @@ import (
 
 // DonationModule holds all donation-related services and handlers.
 type DonationModule struct {
-	handler       *DonationHandler
-	sessionStore  *SessionStore
-	bindingStore  *BindingStore
-	roleService   *RoleService
-	linuxDoSvc    *LinuxDoConnectService
-	newAPISvc     *NewAPIService
-	logger        *DonationLogger
-	isConfigured  bool
+	handler      *DonationHandler
+	sessionStore SessionStore
+	bindingStore *BindingStore
+	ledgerStore  *LedgerStore
+	roleService  *RoleService
+	linuxDoSvc   *LinuxDoConnectService
+	newAPISvc    *NewAPIService
+	logger       *DonationLogger
+	stopSweeper  context.CancelFunc
+	isConfigured bool
 }
 
 // NewDonationModule creates a new donation module with all dependencies.
@@ -27,13 +34,21 @@ func NewDonationModule(cfg *config.Config) (*DonationModule, error) {
 	}
 
 	// Initialize services
-	sessionStore := NewSessionStore()
-	
+	sessionStore, err := newSessionStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	bindingStore, err := NewBindingStore(cfg.AuthDir)
 	if err != nil {
 		return nil, err
 	}
 
+	ledgerStore, err := NewLedgerStore(cfg.AuthDir)
+	if err != nil {
+		return nil, err
+	}
+
 	roleService := NewRoleService(cfg.Donation.AdminLinuxDoIDs, cfg.Donation.AdminLinuxDoUsernames)
 	linuxDoSvc := NewLinuxDoConnectService(cfg.LinuxDoConnect)
 	newAPISvc := NewNewAPIService()
@@ -49,23 +64,86 @@ func NewDonationModule(cfg *config.Config) (*DonationModule, error) {
 		newAPISvc,
 		sessionStore,
 		bindingStore,
+		ledgerStore,
 		roleService,
 		logger,
 		quotaAmount,
+		DonationPolicy{
+			CallbackSecret:    cfg.Donation.CallbackSecret,
+			MaxPendingPerUser: cfg.Donation.MaxPendingPerUser,
+			DailyLimitPerUser: cfg.Donation.DailyLimitPerUser,
+		},
 	)
 
+	sweepCtx, stopSweeper := context.WithCancel(context.Background())
+	StartSessionSweeper(sweepCtx, sessionStore, SessionSweepInterval)
+
 	return &DonationModule{
 		handler:      handler,
 		sessionStore: sessionStore,
 		bindingStore: bindingStore,
+		ledgerStore:  ledgerStore,
 		roleService:  roleService,
 		linuxDoSvc:   linuxDoSvc,
 		newAPISvc:    newAPISvc,
 		logger:       logger,
+		stopSweeper:  stopSweeper,
 		isConfigured: true,
 	}, nil
 }
 
+// newSessionStore creates the session store selected by the donation configuration.
+func newSessionStore(cfg *config.Config) (SessionStore, error) {
+	ttl := SessionTTL
+	if cfg.Donation.SessionTTLHours > 0 {
+		ttl = time.Duration(cfg.Donation.SessionTTLHours) * time.Hour
+	}
+
+	db, schema := registeredSessionDB()
+	kind := strings.ToLower(strings.TrimSpace(cfg.Donation.SessionStore))
+	switch kind {
+	case "", "auto":
+		if db == nil {
+			kind = "file"
+		} else {
+			kind = "postgres"
+		}
+	}
+
+	switch kind {
+	case "memory":
+		return NewSessionStoreWithTTL(ttl), nil
+	case "file":
+		store, err := NewFileSessionStore(cfg.AuthDir, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("donation: load sessions: %w", err)
+		}
+		log.Info("Donation sessions persisted to the auth directory")
+		return store, nil
+	case "postgres":
+		if db == nil {
+			return nil, fmt.Errorf("donation: session-store %q requires the Postgres token store", kind)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		store, err := NewPostgresSessionStore(ctx, db, schema, ttl)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Donation sessions persisted to Postgres")
+		return store, nil
+	default:
+		return nil, fmt.Errorf("donation: unknown session-store %q", cfg.Donation.SessionStore)
+	}
+}
+
+// Close stops background session maintenance.
+func (m *DonationModule) Close() {
+	if m != nil && m.stopSweeper != nil {
+		m.stopSweeper()
+	}
+}
+
 // IsConfigured returns true if the donation module is properly configured.
 func (m *DonationModule) IsConfigured() bool {
 	return m != nil && m.isConfigured
@@ -87,7 +165,7 @@ func (m *DonationModule) RegisterRoutes(engine *gin.Engine) {
 
 	// Protected routes (auth required)
 	authMiddleware := AuthMiddleware(m.sessionStore)
-	
+
 	// Bind routes
 	bind := engine.Group("/bind")
 	bind.Use(authMiddleware)
@@ -102,8 +180,23 @@ func (m *DonationModule) RegisterRoutes(engine *gin.Engine) {
 	{
 		donate.GET("", m.handler.HandleDonatePage)
 		donate.POST("/confirm", m.handler.HandleDonateConfirm)
+		donate.GET("/history", m.handler.HandleDonationHistory)
 	}
 
+	// Donation verification routes (admin only)
+	donateAdmin := donate.Group("/admin", AdminOnlyMiddleware())
+	{
+		donateAdmin.GET("/donations", m.handler.HandleAdminListDonations)
+		donateAdmin.POST("/donations/:id/approve", m.handler.HandleAdminApproveDonation)
+		donateAdmin.POST("/donations/:id/reject", m.handler.HandleAdminRejectDonation)
+		donateAdmin.GET("/sessions", m.handler.HandleAdminListSessions)
+		donateAdmin.DELETE("/sessions/:key", m.handler.HandleAdminRevokeSession)
+		donateAdmin.DELETE("/users/:linux_do_id/sessions", m.handler.HandleAdminRevokeUserSessions)
+	}
+
+	// Payment provider callback (authenticated by HMAC signature)
+	engine.POST("/donate/callback", m.handler.HandlePaymentCallback)
+
 	// Logout route
 	engine.POST("/logout", authMiddleware, m.handler.HandleLogout)
 
@@ -135,7 +228,7 @@ func (m *DonationModule) optionalAuthMiddleware() gin.HandlerFunc {
 }
 
 // GetSessionStore returns the session store for use in other middleware.
-func (m *DonationModule) GetSessionStore() *SessionStore {
+func (m *DonationModule) GetSessionStore() SessionStore {
 	return m.sessionStore
 }
 
@@ -1,10 +1,15 @@
 package donation
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"sort"
 	"sync"
 	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
 const (
@@ -12,27 +17,133 @@ const (
 	SessionTTL = 24 * time.Hour
 	// TokenLength is the length of generated session tokens in bytes.
 	TokenLength = 32
+	// SessionSweepInterval is how often expired sessions are removed from persistent stores.
+	SessionSweepInterval = 10 * time.Minute
 )
 
-// SessionStore manages user sessions in memory.
-type SessionStore struct {
-	sessions sync.Map
+// SessionStore persists donation site sessions. Implementations key sessions by the SHA-256
+// of the session token so tokens never appear in storage or admin listings.
+type SessionStore interface {
+	// Create creates a new session for the given user and role.
+	Create(user *LinuxDoUser, role string) (*Session, error)
+	// Get retrieves a session by its token. Returns nil if it doesn't exist or has expired.
+	Get(sessionID string) *Session
+	// Update updates an existing session. Returns false if the session doesn't exist.
+	Update(session *Session) bool
+	// Delete removes a session by its token.
+	Delete(sessionID string)
+	// Cleanup removes all expired sessions.
+	Cleanup()
+	// List returns all active sessions without their tokens.
+	List() []SessionInfo
+	// Revoke removes the session identified by its key. Returns false if it doesn't exist.
+	Revoke(key string) bool
+	// RevokeUser removes every session of a Linux Do user and returns how many were removed.
+	RevokeUser(linuxDoID int) int
+}
+
+// SessionInfo describes a session for administrators without exposing its token.
+type SessionInfo struct {
+	// Key identifies the session for revocation (SHA-256 of the token).
+	Key string `json:"key"`
+	// LinuxDoID is the user's Linux Do platform ID.
+	LinuxDoID int `json:"linux_do_id"`
+	// Username is the user's login name from Linux Do.
+	Username string `json:"username"`
+	// Role is the user's role, either "user" or "admin".
+	Role string `json:"role"`
+	// NewAPIUserID is the bound new-api user ID (0 if not bound).
+	NewAPIUserID int `json:"newapi_user_id,omitempty"`
+	// CreatedAt is when the session was created.
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is when the session expires.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SessionKey returns the storage key of a session token.
+func SessionKey(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionInfo converts a stored session into its admin view.
+func sessionInfo(key string, session *Session) SessionInfo {
+	return SessionInfo{
+		Key:          key,
+		LinuxDoID:    session.LinuxDoID,
+		Username:     session.Username,
+		Role:         session.Role,
+		NewAPIUserID: session.NewAPIUserID,
+		CreatedAt:    session.CreatedAt,
+		ExpiresAt:    session.ExpiresAt,
+	}
+}
+
+// sortSessionInfos orders sessions newest first.
+func sortSessionInfos(infos []SessionInfo) {
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt.After(infos[j].CreatedAt) })
+}
+
+// newSession builds a session with a fresh token for the given user and role.
+func newSession(user *LinuxDoUser, role string, ttl time.Duration) (*Session, error) {
+	token, err := GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Session{
+		ID:        token,
+		LinuxDoID: user.ID,
+		Username:  user.Username,
+		Role:      role,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}, nil
+}
+
+// StartSessionSweeper periodically removes expired sessions until ctx is cancelled.
+func StartSessionSweeper(ctx context.Context, store SessionStore, interval time.Duration) {
+	if store == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = SessionSweepInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				store.Cleanup()
+			}
+		}
+	}()
+	log.Debugf("donation session sweeper started (interval %s)", interval)
+}
+
+// MemorySessionStore manages user sessions in memory. Sessions are lost on restart.
+type MemorySessionStore struct {
+	sessions sync.Map // keyed by SessionKey(token)
 	ttl      time.Duration
 }
 
-// NewSessionStore creates a new session store with the default TTL.
-func NewSessionStore() *SessionStore {
-	return &SessionStore{
+// NewSessionStore creates a new in-memory session store with the default TTL.
+func NewSessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
 		ttl: SessionTTL,
 	}
 }
 
-// NewSessionStoreWithTTL creates a new session store with a custom TTL.
-func NewSessionStoreWithTTL(ttl time.Duration) *SessionStore {
+// NewSessionStoreWithTTL creates a new in-memory session store with a custom TTL.
+func NewSessionStoreWithTTL(ttl time.Duration) *MemorySessionStore {
 	if ttl <= 0 {
 		ttl = SessionTTL
 	}
-	return &SessionStore{
+	return &MemorySessionStore{
 		ttl: ttl,
 	}
 }
@@ -49,30 +160,21 @@ func GenerateToken() (string, error) {
 
 // Create creates a new session for the given user and role.
 // Returns the created session with a generated token.
-func (s *SessionStore) Create(user *LinuxDoUser, role string) (*Session, error) {
-	token, err := GenerateToken()
+func (s *MemorySessionStore) Create(user *LinuxDoUser, role string) (*Session, error) {
+	session, err := newSession(user, role, s.ttl)
 	if err != nil {
 		return nil, err
 	}
 
-	now := time.Now()
-	session := &Session{
-		ID:        token,
-		LinuxDoID: user.ID,
-		Username:  user.Username,
-		Role:      role,
-		CreatedAt: now,
-		ExpiresAt: now.Add(s.ttl),
-	}
-
-	s.sessions.Store(token, session)
+	s.sessions.Store(SessionKey(session.ID), session)
 	return session, nil
 }
 
 // Get retrieves a session by its token.
 // Returns nil if the session doesn't exist or has expired.
-func (s *SessionStore) Get(sessionID string) *Session {
-	value, ok := s.sessions.Load(sessionID)
+func (s *MemorySessionStore) Get(sessionID string) *Session {
+	key := SessionKey(sessionID)
+	value, ok := s.sessions.Load(key)
 	if !ok {
 		return nil
 	}
@@ -84,7 +186,7 @@ func (s *SessionStore) Get(sessionID string) *Session {
 
 	// Check if session has expired
 	if session.IsExpired() {
-		s.sessions.Delete(sessionID)
+		s.sessions.Delete(key)
 		return nil
 	}
 
@@ -93,28 +195,29 @@ func (s *SessionStore) Get(sessionID string) *Session {
 
 // Update updates an existing session.
 // Returns false if the session doesn't exist.
-func (s *SessionStore) Update(session *Session) bool {
+func (s *MemorySessionStore) Update(session *Session) bool {
 	if session == nil || session.ID == "" {
 		return false
 	}
 
-	_, exists := s.sessions.Load(session.ID)
+	key := SessionKey(session.ID)
+	_, exists := s.sessions.Load(key)
 	if !exists {
 		return false
 	}
 
-	s.sessions.Store(session.ID, session)
+	s.sessions.Store(key, session)
 	return true
 }
 
 // Delete removes a session by its token.
-func (s *SessionStore) Delete(sessionID string) {
-	s.sessions.Delete(sessionID)
+func (s *MemorySessionStore) Delete(sessionID string) {
+	s.sessions.Delete(SessionKey(sessionID))
 }
 
 // Cleanup removes all expired sessions.
 // This can be called periodically to free memory.
-func (s *SessionStore) Cleanup() {
+func (s *MemorySessionStore) Cleanup() {
 	now := time.Now()
 	s.sessions.Range(func(key, value interface{}) bool {
 		if session, ok := value.(*Session); ok {
@@ -125,3 +228,35 @@ func (s *SessionStore) Cleanup() {
 		return true
 	})
 }
+
+// List returns all active sessions.
+func (s *MemorySessionStore) List() []SessionInfo {
+	infos := make([]SessionInfo, 0)
+	s.sessions.Range(func(key, value interface{}) bool {
+		if session, ok := value.(*Session); ok && !session.IsExpired() {
+			infos = append(infos, sessionInfo(key.(string), session))
+		}
+		return true
+	})
+	sortSessionInfos(infos)
+	return infos
+}
+
+// Revoke removes the session identified by key.
+func (s *MemorySessionStore) Revoke(key string) bool {
+	_, existed := s.sessions.LoadAndDelete(key)
+	return existed
+}
+
+// RevokeUser removes every session of a Linux Do user.
+func (s *MemorySessionStore) RevokeUser(linuxDoID int) int {
+	removed := 0
+	s.sessions.Range(func(key, value interface{}) bool {
+		if session, ok := value.(*Session); ok && session.LinuxDoID == linuxDoID {
+			s.sessions.Delete(key)
+			removed++
+		}
+		return true
+	})
+	return removed
+}
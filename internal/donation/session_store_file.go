@@ -0,0 +1,230 @@
+package donation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	log "github.com/sirupsen/logrus"
+)
+
+// SessionsFileName is the file, relative to the auth directory, holding donation sessions.
+const SessionsFileName = misc.DonationSessionsFileName
+
+// FileSessionStore manages user sessions with JSON file persistence so logins survive restarts.
+type FileSessionStore struct {
+	mu       sync.RWMutex
+	filePath string
+	ttl      time.Duration
+	sessions map[string]*Session // keyed by SessionKey(token); stored copies carry no token
+}
+
+// sessionsFile represents the JSON file structure.
+type sessionsFile struct {
+	Sessions map[string]*Session `json:"sessions"`
+}
+
+// NewFileSessionStore creates a file-backed session store in the given base directory.
+func NewFileSessionStore(baseDir string, ttl time.Duration) (*FileSessionStore, error) {
+	if ttl <= 0 {
+		ttl = SessionTTL
+	}
+	store := &FileSessionStore{
+		filePath: filepath.Join(baseDir, SessionsFileName),
+		ttl:      ttl,
+		sessions: make(map[string]*Session),
+	}
+
+	// Load existing sessions if file exists
+	if err := store.load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// load reads sessions from the JSON file, skipping expired ones.
+func (s *FileSessionStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+
+	var file sessionsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	s.sessions = make(map[string]*Session, len(file.Sessions))
+	for key, session := range file.Sessions {
+		if session != nil && !session.IsExpired() {
+			s.sessions[key] = session
+		}
+	}
+
+	return nil
+}
+
+// save writes sessions to the JSON file.
+func (s *FileSessionStore) save() {
+	// Ensure directory exists
+	dir := filepath.Dir(s.filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.WithError(err).Error("failed to create donation session directory")
+		return
+	}
+
+	data, err := json.MarshalIndent(sessionsFile{Sessions: s.sessions}, "", "  ")
+	if err != nil {
+		log.WithError(err).Error("failed to encode donation sessions")
+		return
+	}
+
+	tmpPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		log.WithError(err).Error("failed to write donation sessions")
+		return
+	}
+	if err := os.Rename(tmpPath, s.filePath); err != nil {
+		log.WithError(err).Error("failed to replace donation sessions file")
+	}
+}
+
+// stored returns the persisted form of a session, which omits the token.
+func stored(session *Session) *Session {
+	copied := *session
+	copied.ID = ""
+	return &copied
+}
+
+// Create creates a new session for the given user and role.
+func (s *FileSessionStore) Create(user *LinuxDoUser, role string) (*Session, error) {
+	session, err := newSession(user, role, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[SessionKey(session.ID)] = stored(session)
+	s.save()
+	return session, nil
+}
+
+// Get retrieves a session by its token.
+// Returns nil if the session doesn't exist or has expired.
+func (s *FileSessionStore) Get(sessionID string) *Session {
+	key := SessionKey(sessionID)
+
+	s.mu.RLock()
+	session, ok := s.sessions[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	// Check if session has expired
+	if session.IsExpired() {
+		s.mu.Lock()
+		delete(s.sessions, key)
+		s.save()
+		s.mu.Unlock()
+		return nil
+	}
+
+	copied := *session
+	copied.ID = sessionID
+	return &copied
+}
+
+// Update updates an existing session.
+// Returns false if the session doesn't exist.
+func (s *FileSessionStore) Update(session *Session) bool {
+	if session == nil || session.ID == "" {
+		return false
+	}
+
+	key := SessionKey(session.ID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.sessions[key]; !exists {
+		return false
+	}
+	s.sessions[key] = stored(session)
+	s.save()
+	return true
+}
+
+// Delete removes a session by its token.
+func (s *FileSessionStore) Delete(sessionID string) {
+	s.Revoke(SessionKey(sessionID))
+}
+
+// Cleanup removes all expired sessions.
+func (s *FileSessionStore) Cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := false
+	for key, session := range s.sessions {
+		if session.IsExpired() {
+			delete(s.sessions, key)
+			removed = true
+		}
+	}
+	if removed {
+		s.save()
+	}
+}
+
+// List returns all active sessions.
+func (s *FileSessionStore) List() []SessionInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infos := make([]SessionInfo, 0, len(s.sessions))
+	for key, session := range s.sessions {
+		if !session.IsExpired() {
+			infos = append(infos, sessionInfo(key, session))
+		}
+	}
+	sortSessionInfos(infos)
+	return infos
+}
+
+// Revoke removes the session identified by key.
+func (s *FileSessionStore) Revoke(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.sessions[key]; !exists {
+		return false
+	}
+	delete(s.sessions, key)
+	s.save()
+	return true
+}
+
+// RevokeUser removes every session of a Linux Do user.
+func (s *FileSessionStore) RevokeUser(linuxDoID int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for key, session := range s.sessions {
+		if session.LinuxDoID == linuxDoID {
+			delete(s.sessions, key)
+			removed++
+		}
+	}
+	if removed > 0 {
+		s.save()
+	}
+	return removed
+}
@@ -0,0 +1,233 @@
+package donation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSessionTable = "donation_sessions"
+	sessionQueryTimeout = 5 * time.Second
+)
+
+var (
+	sessionDBMu     sync.RWMutex
+	sessionDB       *sql.DB
+	sessionDBSchema string
+)
+
+// UsePostgresSessions registers a shared Postgres connection for donation sessions. The donation
+// module uses it when the session store is "postgres" or "auto". The caller retains ownership of db.
+func UsePostgresSessions(db *sql.DB, schema string) {
+	sessionDBMu.Lock()
+	defer sessionDBMu.Unlock()
+	sessionDB = db
+	sessionDBSchema = schema
+}
+
+// registeredSessionDB returns the connection registered by UsePostgresSessions, if any.
+func registeredSessionDB() (*sql.DB, string) {
+	sessionDBMu.RLock()
+	defer sessionDBMu.RUnlock()
+	return sessionDB, sessionDBSchema
+}
+
+// PostgresSessionStore persists user sessions in PostgreSQL so they survive restarts and are
+// shared by every instance using the same database.
+type PostgresSessionStore struct {
+	db    *sql.DB
+	table string
+	ttl   time.Duration
+}
+
+// NewPostgresSessionStore prepares the session table on an existing connection. The caller
+// retains ownership of db.
+func NewPostgresSessionStore(ctx context.Context, db *sql.DB, schema string, ttl time.Duration) (*PostgresSessionStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("donation session store: database connection is required")
+	}
+	if ttl <= 0 {
+		ttl = SessionTTL
+	}
+	table := store.QuoteIdentifier(defaultSessionTable)
+	if schema = strings.TrimSpace(schema); schema != "" {
+		table = store.QuoteIdentifier(schema) + "." + table
+	}
+	store := &PostgresSessionStore{db: db, table: table, ttl: ttl}
+	if err := store.ensureSchema(ctx); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *PostgresSessionStore) ensureSchema(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			key TEXT PRIMARY KEY,
+			linux_do_id BIGINT NOT NULL,
+			username TEXT NOT NULL DEFAULT '',
+			role TEXT NOT NULL DEFAULT '',
+			newapi_user_id BIGINT NOT NULL DEFAULT 0,
+			created_at TIMESTAMPTZ NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL
+		)
+	`, s.table)); err != nil {
+		return fmt.Errorf("donation session store: create table: %w", err)
+	}
+	index := store.QuoteIdentifier(defaultSessionTable + "_expires_at_idx")
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (expires_at)", index, s.table)); err != nil {
+		return fmt.Errorf("donation session store: create index: %w", err)
+	}
+	return nil
+}
+
+// Create creates a new session for the given user and role.
+func (s *PostgresSessionStore) Create(user *LinuxDoUser, role string) (*Session, error) {
+	session, err := newSession(user, role, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sessionQueryTimeout)
+	defer cancel()
+	if _, err = s.db.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO %s (key, linux_do_id, username, role, newapi_user_id, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, s.table), SessionKey(session.ID), session.LinuxDoID, session.Username, session.Role,
+		session.NewAPIUserID, session.CreatedAt.UTC(), session.ExpiresAt.UTC()); err != nil {
+		return nil, fmt.Errorf("donation session store: insert session: %w", err)
+	}
+	return session, nil
+}
+
+// Get retrieves a session by its token.
+// Returns nil if the session doesn't exist or has expired.
+func (s *PostgresSessionStore) Get(sessionID string) *Session {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionQueryTimeout)
+	defer cancel()
+
+	session := &Session{ID: sessionID}
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT linux_do_id, username, role, newapi_user_id, created_at, expires_at
+		FROM %s WHERE key = $1 AND expires_at > $2
+	`, s.table), SessionKey(sessionID), time.Now().UTC()).Scan(
+		&session.LinuxDoID, &session.Username, &session.Role, &session.NewAPIUserID,
+		&session.CreatedAt, &session.ExpiresAt)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.WithError(err).Error("donation session store: load session")
+		}
+		return nil
+	}
+	return session
+}
+
+// Update updates an existing session.
+// Returns false if the session doesn't exist.
+func (s *PostgresSessionStore) Update(session *Session) bool {
+	if session == nil || session.ID == "" {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sessionQueryTimeout)
+	defer cancel()
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE %s SET username = $2, role = $3, newapi_user_id = $4, expires_at = $5
+		WHERE key = $1
+	`, s.table), SessionKey(session.ID), session.Username, session.Role, session.NewAPIUserID, session.ExpiresAt.UTC())
+	if err != nil {
+		log.WithError(err).Error("donation session store: update session")
+		return false
+	}
+	return rowsAffected(result) > 0
+}
+
+// Delete removes a session by its token.
+func (s *PostgresSessionStore) Delete(sessionID string) {
+	s.Revoke(SessionKey(sessionID))
+}
+
+// Cleanup removes all expired sessions.
+func (s *PostgresSessionStore) Cleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionQueryTimeout)
+	defer cancel()
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE expires_at <= $1", s.table), time.Now().UTC())
+	if err != nil {
+		log.WithError(err).Error("donation session store: remove expired sessions")
+		return
+	}
+	if removed := rowsAffected(result); removed > 0 {
+		log.Debugf("donation session store: removed %d expired session(s)", removed)
+	}
+}
+
+// List returns all active sessions.
+func (s *PostgresSessionStore) List() []SessionInfo {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionQueryTimeout)
+	defer cancel()
+
+	infos := make([]SessionInfo, 0)
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT key, linux_do_id, username, role, newapi_user_id, created_at, expires_at
+		FROM %s WHERE expires_at > $1 ORDER BY created_at DESC
+	`, s.table), time.Now().UTC())
+	if err != nil {
+		log.WithError(err).Error("donation session store: list sessions")
+		return infos
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var info SessionInfo
+		if err = rows.Scan(&info.Key, &info.LinuxDoID, &info.Username, &info.Role, &info.NewAPIUserID,
+			&info.CreatedAt, &info.ExpiresAt); err != nil {
+			log.WithError(err).Error("donation session store: scan session")
+			return infos
+		}
+		infos = append(infos, info)
+	}
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("donation session store: list sessions")
+	}
+	return infos
+}
+
+// Revoke removes the session identified by key.
+func (s *PostgresSessionStore) Revoke(key string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionQueryTimeout)
+	defer cancel()
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = $1", s.table), key)
+	if err != nil {
+		log.WithError(err).Error("donation session store: revoke session")
+		return false
+	}
+	return rowsAffected(result) > 0
+}
+
+// RevokeUser removes every session of a Linux Do user.
+func (s *PostgresSessionStore) RevokeUser(linuxDoID int) int {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionQueryTimeout)
+	defer cancel()
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE linux_do_id = $1", s.table), linuxDoID)
+	if err != nil {
+		log.WithError(err).Error("donation session store: revoke user sessions")
+		return 0
+	}
+	return int(rowsAffected(result))
+}
+
+func rowsAffected(result sql.Result) int64 {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+package donation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileSessionStorePersistsAndRevokes(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileSessionStore(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileSessionStore() error = %v", err)
+	}
+
+	first, err := store.Create(&LinuxDoUser{ID: 7, Username: "alice"}, RoleUser)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	second, err := store.Create(&LinuxDoUser{ID: 7, Username: "alice"}, RoleUser)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	other, err := store.Create(&LinuxDoUser{ID: 8, Username: "bob"}, RoleAdmin)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	first.NewAPIUserID = 70
+	if !store.Update(first) {
+		t.Fatalf("Update() = false, want true")
+	}
+
+	reloaded, err := NewFileSessionStore(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("reload NewFileSessionStore() error = %v", err)
+	}
+	got := reloaded.Get(first.ID)
+	if got == nil || got.ID != first.ID || got.NewAPIUserID != 70 || got.Username != "alice" {
+		t.Fatalf("reloaded Get() = %+v, want alice bound to 70", got)
+	}
+	for _, info := range reloaded.List() {
+		if info.Key == first.ID || info.Key == second.ID || info.Key == other.ID {
+			t.Fatalf("List() exposed a session token")
+		}
+	}
+
+	if n := reloaded.RevokeUser(7); n != 2 {
+		t.Fatalf("RevokeUser() = %d, want 2", n)
+	}
+	if reloaded.Get(second.ID) != nil {
+		t.Fatalf("Get() returned a revoked session")
+	}
+	if !reloaded.Revoke(SessionKey(other.ID)) || reloaded.Get(other.ID) != nil {
+		t.Fatalf("Revoke() did not remove the session")
+	}
+	if len(reloaded.List()) != 0 {
+		t.Fatalf("List() = %v, want no sessions", reloaded.List())
+	}
+}
+
+func TestFileSessionStoreDropsExpiredSessions(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileSessionStore(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileSessionStore() error = %v", err)
+	}
+	session, err := store.Create(&LinuxDoUser{ID: 9, Username: "carol"}, RoleUser)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	session.ExpiresAt = time.Now().Add(-time.Minute)
+	store.Update(session)
+
+	store.Cleanup()
+	if len(store.List()) != 0 {
+		t.Fatalf("Cleanup() kept an expired session")
+	}
+	reloaded, err := NewFileSessionStore(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("reload NewFileSessionStore() error = %v", err)
+	}
+	if reloaded.Get(session.ID) != nil {
+		t.Fatalf("expired session survived a reload")
+	}
+}
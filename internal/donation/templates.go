@@ -147,6 +147,10 @@ const indexPageHTML = `<!DOCTYPE html>
                     <h3>捐赠奖励额度</h3>
                     <div class="amount">$<span id="quota-amount">20</span></div>
                 </div>
+                <div class="input-group">
+                    <label for="payment-reference">请输入支付订单号</label>
+                    <input type="text" id="payment-reference" placeholder="例如: 2024010112345678">
+                </div>
                 <button onclick="confirmDonate()" class="btn btn-primary">✨ 确认捐赠</button>
             </div>
 
@@ -247,17 +251,36 @@ const indexPageHTML = `<!DOCTYPE html>
         }
 
         async function confirmDonate() {
-            if (!confirm('确认已完成捐赠？系统将为您添加额度。')) return;
+            const paymentReference = document.getElementById('payment-reference').value.trim();
+            if (!paymentReference) {
+                showMessage('请输入支付订单号', true);
+                return;
+            }
+            if (!confirm('确认已完成捐赠？管理员核实后将为您添加额度。')) return;
+
+            // Reuse the key for the same order so retries never create duplicate donations
+            const keyName = 'donation-key-' + paymentReference;
+            let idempotencyKey = sessionStorage.getItem(keyName);
+            if (!idempotencyKey) {
+                idempotencyKey = (window.crypto && crypto.randomUUID) ? crypto.randomUUID() : String(Date.now()) + Math.random();
+                sessionStorage.setItem(keyName, idempotencyKey);
+            }
 
             try {
                 const resp = await fetch('/donate/confirm', {
                     method: 'POST',
-                    headers: { 'Content-Type': 'application/json' }
+                    headers: { 'Content-Type': 'application/json', 'Idempotency-Key': idempotencyKey },
+                    body: JSON.stringify({ payment_reference: paymentReference })
                 });
                 const data = await resp.json();
                 
                 if (resp.ok) {
-                    showMessage('🎉 捐赠成功！额度已添加到您的账户', false);
+                    const status = data.donation ? data.donation.status : 'pending';
+                    if (status === 'credited') {
+                        showMessage('🎉 捐赠已核实，额度已添加到您的账户', false);
+                    } else {
+                        showMessage('✅ 捐赠已提交，核实后将自动添加额度', false);
+                    }
                 } else {
                     showMessage(data.message || '操作失败', true);
                 }
@@ -0,0 +1,263 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/access/clientkeys"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// accessLogFileName is the access log file inside the logs directory.
+const accessLogFileName = "access.log"
+
+// ginAccessRecordKey is the Gin context key holding the request's *AccessRecord.
+const ginAccessRecordKey = "__access_record__"
+
+var (
+	accessLogEnabled atomic.Bool
+	accessLogMu      sync.Mutex
+	accessLogWriter  *lumberjack.Logger
+)
+
+// AccessRecord collects the request details that only inner layers know — the handler, the
+// upstream model and credential, retries and token usage — for the access log line written
+// when the request completes. All methods are safe on a nil receiver.
+type AccessRecord struct {
+	mu              sync.Mutex
+	handler         string
+	requestedModel  string
+	upstreamModel   string
+	provider        string
+	authID          string
+	authIndex       string
+	attempts        int
+	inputTokens     int64
+	outputTokens    int64
+	reasoningTokens int64
+	cachedTokens    int64
+	totalTokens     int64
+}
+
+// accessLogLine is the JSON object written for every request.
+type accessLogLine struct {
+	Time            string `json:"time"`
+	RequestID       string `json:"request_id,omitempty"`
+	Method          string `json:"method"`
+	Path            string `json:"path"`
+	Status          int    `json:"status"`
+	LatencyMs       int64  `json:"latency_ms"`
+	ClientIP        string `json:"client_ip,omitempty"`
+	ClientKey       string `json:"client_key,omitempty"`
+	Handler         string `json:"handler,omitempty"`
+	RequestedModel  string `json:"requested_model,omitempty"`
+	UpstreamModel   string `json:"upstream_model,omitempty"`
+	Provider        string `json:"provider,omitempty"`
+	AuthID          string `json:"auth_id,omitempty"`
+	AuthIndex       string `json:"auth_index,omitempty"`
+	Retries         int    `json:"retries"`
+	InputTokens     int64  `json:"input_tokens,omitempty"`
+	OutputTokens    int64  `json:"output_tokens,omitempty"`
+	ReasoningTokens int64  `json:"reasoning_tokens,omitempty"`
+	CachedTokens    int64  `json:"cached_tokens,omitempty"`
+	TotalTokens     int64  `json:"total_tokens,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// AccessRecordFromContext returns the access record of the request carried by an execution
+// context (through its "gin" value), or nil when the access log is off.
+func AccessRecordFromContext(ctx context.Context) *AccessRecord {
+	if ctx == nil {
+		return nil
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return nil
+	}
+	return GetGinAccessRecord(ginCtx)
+}
+
+// GetGinAccessRecord returns the access record stored in the Gin context, if any.
+func GetGinAccessRecord(c *gin.Context) *AccessRecord {
+	if c == nil {
+		return nil
+	}
+	if v, exists := c.Get(ginAccessRecordKey); exists {
+		if rec, ok := v.(*AccessRecord); ok {
+			return rec
+		}
+	}
+	return nil
+}
+
+// SetRequest records the handler type and the model the client asked for.
+func (r *AccessRecord) SetRequest(handler, model string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.handler = handler
+	r.requestedModel = model
+	r.mu.Unlock()
+}
+
+// AddAttempt records one upstream attempt; the last attempt names the credential and model
+// that produced the response.
+func (r *AccessRecord) AddAttempt(provider, authID, authIndex, upstreamModel string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.attempts++
+	r.provider = provider
+	r.authID = authID
+	r.authIndex = authIndex
+	r.upstreamModel = upstreamModel
+	r.mu.Unlock()
+}
+
+// AddUsage accumulates token counts reported by the executors.
+func (r *AccessRecord) AddUsage(input, output, reasoning, cached, total int64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.inputTokens += input
+	r.outputTokens += output
+	r.reasoningTokens += reasoning
+	r.cachedTokens += cached
+	r.totalTokens += total
+	r.mu.Unlock()
+}
+
+func (r *AccessRecord) fill(line *accessLogLine) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	line.Handler = r.handler
+	line.RequestedModel = r.requestedModel
+	line.UpstreamModel = r.upstreamModel
+	line.Provider = r.provider
+	line.AuthID = r.authID
+	line.AuthIndex = r.authIndex
+	if r.attempts > 1 {
+		line.Retries = r.attempts - 1
+	}
+	line.InputTokens = r.inputTokens
+	line.OutputTokens = r.outputTokens
+	line.ReasoningTokens = r.reasoningTokens
+	line.CachedTokens = r.cachedTokens
+	line.TotalTokens = r.totalTokens
+}
+
+// AccessLogMiddleware returns a Gin middleware that writes one JSON line per request to the
+// access log. It must run after GinLogrusLogger so the request ID is available. It is a no-op
+// while the access log is disabled.
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !accessLogEnabled.Load() {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		record := &AccessRecord{}
+		c.Set(ginAccessRecordKey, record)
+
+		c.Next()
+
+		if shouldSkipGinRequestLogging(c) {
+			return
+		}
+		line := accessLogLine{
+			Time:      start.UTC().Format(time.RFC3339Nano),
+			RequestID: GetGinRequestID(c),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			LatencyMs: time.Since(start).Milliseconds(),
+			ClientIP:  c.ClientIP(),
+			ClientKey: ClientKeyName(c),
+			Error:     c.Errors.ByType(gin.ErrorTypePrivate).String(),
+		}
+		record.fill(&line)
+		writeAccessLogLine(&line)
+	}
+}
+
+// ClientKeyName names the client key that authenticated the request without exposing it.
+func ClientKeyName(c *gin.Context) string {
+	v, exists := c.Get("accessMetadata")
+	if !exists {
+		return ""
+	}
+	metadata, ok := v.(map[string]string)
+	if !ok {
+		return ""
+	}
+	if name := metadata[sdkaccess.ClientNameMetadataKey]; name != "" {
+		return name
+	}
+	return metadata[clientkeys.MetadataKeyID]
+}
+
+func writeAccessLogLine(line *accessLogLine) {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+	accessLogMu.Lock()
+	defer accessLogMu.Unlock()
+	if accessLogWriter == nil {
+		return
+	}
+	if _, err = accessLogWriter.Write(data); err != nil {
+		log.WithError(err).Warn("logging: failed to write access log")
+	}
+}
+
+// configureAccessLogLocked opens, rotates or closes the access log according to cfg.
+// It returns the active access log path, or "" when disabled. Callers hold writerMu.
+func configureAccessLogLocked(logDir string, cfg config.AccessLogConfig) string {
+	accessLogMu.Lock()
+	defer accessLogMu.Unlock()
+
+	if accessLogWriter != nil {
+		_ = accessLogWriter.Close()
+		accessLogWriter = nil
+	}
+	accessLogEnabled.Store(cfg.Enable)
+	if !cfg.Enable {
+		return ""
+	}
+	maxSize := cfg.MaxSizeMB
+	if maxSize <= 0 {
+		maxSize = 10
+	}
+	path := filepath.Join(logDir, accessLogFileName)
+	accessLogWriter = &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSize,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+		Compress:   cfg.Compress,
+	}
+	return path
+}
+
+func closeAccessLog() {
+	accessLogMu.Lock()
+	defer accessLogMu.Unlock()
+	accessLogEnabled.Store(false)
+	if accessLogWriter != nil {
+		_ = accessLogWriter.Close()
+		accessLogWriter = nil
+	}
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestAccessLogMiddlewareWritesJSONLine(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	path := configureAccessLogLocked(dir, config.AccessLogConfig{Enable: true})
+	t.Cleanup(closeAccessLog)
+
+	engine := gin.New()
+	engine.Use(GinLogrusLogger(), AccessLogMiddleware())
+	engine.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Set("accessMetadata", map[string]string{"client_name": "team"})
+		ctx := context.WithValue(context.Background(), "gin", c)
+		rec := AccessRecordFromContext(ctx)
+		rec.SetRequest("openai", "gpt-5")
+		rec.AddAttempt("codex", "a1", "idx1", "gpt-5-codex")
+		rec.AddAttempt("codex", "a2", "idx2", "gpt-5-codex")
+		rec.AddUsage(10, 5, 0, 2, 15)
+		c.Status(http.StatusOK)
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+	closeAccessLog()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read access log: %v", err)
+	}
+	if filepath.Base(path) != accessLogFileName {
+		t.Fatalf("access log path = %s", path)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("access log lines = %d, want 1", len(lines))
+	}
+	var line accessLogLine
+	if err = json.Unmarshal([]byte(lines[0]), &line); err != nil {
+		t.Fatalf("decode line: %v", err)
+	}
+	if line.RequestID == "" || line.Status != http.StatusOK || line.ClientKey != "team" || line.Handler != "openai" {
+		t.Fatalf("unexpected line %+v", line)
+	}
+	if line.RequestedModel != "gpt-5" || line.UpstreamModel != "gpt-5-codex" || line.AuthIndex != "idx2" || line.Retries != 1 || line.TotalTokens != 15 {
+		t.Fatalf("unexpected execution fields %+v", line)
+	}
+}
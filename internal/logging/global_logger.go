@@ -120,17 +120,20 @@ func ConfigureLogOutput(cfg *config.Config) error {
 		logDir = filepath.Join(cfg.AuthDir, "logs")
 	}
 
-	protectedPath := ""
-	if cfg.LoggingToFile {
+	var protectedPaths []string
+	if cfg.LoggingToFile || cfg.AccessLog.Enable {
 		if err := os.MkdirAll(logDir, 0o755); err != nil {
 			return fmt.Errorf("logging: failed to create log directory: %w", err)
 		}
+	}
+	if cfg.LoggingToFile {
 		if logWriter != nil {
 			_ = logWriter.Close()
 		}
-		protectedPath = filepath.Join(logDir, "main.log")
+		mainPath := filepath.Join(logDir, "main.log")
+		protectedPaths = append(protectedPaths, mainPath)
 		logWriter = &lumberjack.Logger{
-			Filename:   protectedPath,
+			Filename:   mainPath,
 			MaxSize:    10,
 			MaxBackups: 0,
 			MaxAge:     0,
@@ -145,7 +148,11 @@ func ConfigureLogOutput(cfg *config.Config) error {
 		log.SetOutput(os.Stdout)
 	}
 
-	configureLogDirCleanerLocked(logDir, cfg.LogsMaxTotalSizeMB, protectedPath)
+	if accessPath := configureAccessLogLocked(logDir, cfg.AccessLog); accessPath != "" {
+		protectedPaths = append(protectedPaths, accessPath)
+	}
+
+	configureLogDirCleanerLocked(logDir, cfg.LogsMaxTotalSizeMB, protectedPaths...)
 	return nil
 }
 
@@ -154,6 +161,7 @@ func closeLogOutputs() {
 	defer writerMu.Unlock()
 
 	stopLogDirCleanerLocked()
+	closeAccessLog()
 
 	if logWriter != nil {
 		_ = logWriter.Close()
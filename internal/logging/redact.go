@@ -0,0 +1,324 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	redactedPlaceholder  = "[redacted]"
+	redactedUnparsedJSON = "[redacted unparsed JSON]"
+	maxRedactJSONLines   = 10000
+)
+
+// promptFields hold message text in the OpenAI, Claude and Gemini request and response formats.
+var promptFields = map[string]struct{}{
+	"content":           {},
+	"text":              {},
+	"prompt":            {},
+	"system":            {},
+	"instructions":      {},
+	"input":             {},
+	"thinking":          {},
+	"reasoning_content": {},
+}
+
+// toolArgumentFields hold tool call arguments, including streamed fragments.
+var toolArgumentFields = map[string]struct{}{
+	"arguments":    {},
+	"args":         {},
+	"partial_json": {},
+}
+
+// Redactor rewrites request log content according to a RequestLogRedactionConfig. JSON
+// documents keep their structure and field order; only the selected values are replaced.
+// A nil *Redactor leaves everything untouched.
+type Redactor struct {
+	prompts     bool
+	toolArgs    bool
+	authHeaders bool
+	headers     map[string]struct{}
+	fields      map[string]struct{}
+}
+
+// NewRedactor builds a redactor for cfg. It returns nil when cfg selects nothing.
+func NewRedactor(cfg config.RequestLogRedactionConfig) *Redactor {
+	r := &Redactor{
+		prompts:     cfg.Prompts,
+		toolArgs:    cfg.ToolArguments,
+		authHeaders: cfg.AuthHeaders,
+		headers:     lowerSet(cfg.Headers),
+		fields:      lowerSet(cfg.Fields),
+	}
+	if !r.prompts && !r.toolArgs && !r.authHeaders && len(r.headers) == 0 && len(r.fields) == 0 {
+		return nil
+	}
+	return r
+}
+
+func lowerSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			set[v] = struct{}{}
+		}
+	}
+	return set
+}
+
+// redactsBodies reports whether body content can change; header-only redactors skip JSON parsing.
+func (r *Redactor) redactsBodies() bool {
+	return r != nil && (r.prompts || r.toolArgs || len(r.fields) > 0)
+}
+
+// headerRedacted reports whether the value of header name must be fully replaced.
+func (r *Redactor) headerRedacted(name string) bool {
+	if r == nil {
+		return false
+	}
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if _, ok := r.headers[lower]; ok {
+		return true
+	}
+	if !r.authHeaders {
+		return false
+	}
+	switch lower {
+	case "cookie", "set-cookie":
+		return true
+	}
+	return strings.Contains(lower, "authorization") ||
+		strings.Contains(lower, "api-key") ||
+		strings.Contains(lower, "apikey") ||
+		strings.Contains(lower, "token") ||
+		strings.Contains(lower, "secret")
+}
+
+// Headers returns a copy of headers with redacted values replaced.
+func (r *Redactor) Headers(headers map[string][]string) map[string][]string {
+	if r == nil || headers == nil {
+		return headers
+	}
+	out := make(map[string][]string, len(headers))
+	for key, values := range headers {
+		copied := make([]string, len(values))
+		if r.headerRedacted(key) {
+			for i := range copied {
+				copied[i] = redactedPlaceholder
+			}
+		} else {
+			copy(copied, values)
+		}
+		out[key] = copied
+	}
+	return out
+}
+
+// errorMessages returns copies of messages whose error text is redacted like a body, since
+// upstream errors often echo the request.
+func (r *Redactor) errorMessages(messages []*interfaces.ErrorMessage) []*interfaces.ErrorMessage {
+	if !r.redactsBodies() || len(messages) == 0 {
+		return messages
+	}
+	out := make([]*interfaces.ErrorMessage, len(messages))
+	for i, msg := range messages {
+		if msg == nil || msg.Error == nil {
+			out[i] = msg
+			continue
+		}
+		copied := *msg
+		copied.Error = errors.New(string(r.Body([]byte(msg.Error.Error()))))
+		out[i] = &copied
+	}
+	return out
+}
+
+// Body redacts a request or response body. A body that is a single JSON document is rewritten
+// as a whole; anything else, such as SSE streams and the upstream request/response sections, is
+// processed line by line.
+func (r *Redactor) Body(body []byte) []byte {
+	if r == nil || len(body) == 0 {
+		return body
+	}
+	trimmed := bytes.TrimSpace(body)
+	if r.redactsBodies() && len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && gjson.ValidBytes(trimmed) {
+		return r.redactJSON(trimmed)
+	}
+	return r.Text(body)
+}
+
+// Text redacts a text blob line by line. Header lines ("Name: value") of redacted headers lose
+// their value, JSON lines (optionally prefixed with "data:") are rewritten, and pretty-printed
+// JSON spanning several lines is collected and rewritten as one document. JSON that never becomes
+// valid, such as a body cut off by truncation, is replaced entirely rather than written unredacted.
+func (r *Redactor) Text(text []byte) []byte {
+	if r == nil || len(text) == 0 {
+		return text
+	}
+	lines := bytes.Split(text, []byte("\n"))
+	out := make([]byte, 0, len(text))
+	for i := 0; i < len(lines); i++ {
+		if i > 0 {
+			out = append(out, '\n')
+		}
+		line := lines[i]
+		trimmed := bytes.TrimSpace(line)
+		if r.redactsBodies() && len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && !bytes.Equal(trimmed, []byte("[DONE]")) && !gjson.ValidBytes(trimmed) {
+			end, ok := r.collectJSON(lines, i)
+			if ok {
+				block := bytes.Join(lines[i:end+1], []byte("\n"))
+				out = append(out, r.redactJSON(bytes.TrimSpace(block))...)
+				i = end
+				continue
+			}
+			out = append(out, redactedUnparsedJSON...)
+			i = end
+			continue
+		}
+		out = append(out, r.Line(line)...)
+	}
+	return out
+}
+
+// collectJSON finds the line that completes the JSON document starting at lines[start].
+// When none does, it returns the last line it looked at and false.
+func (r *Redactor) collectJSON(lines [][]byte, start int) (int, bool) {
+	var block []byte
+	last := start
+	for j := start; j < len(lines) && j-start < maxRedactJSONLines; j++ {
+		if j > start {
+			block = append(block, '\n')
+		}
+		block = append(block, lines[j]...)
+		last = j
+		// Only a line ending in a closing bracket can complete the document.
+		if end := bytes.TrimSpace(lines[j]); len(end) > 0 && (end[len(end)-1] == '}' || end[len(end)-1] == ']') &&
+			gjson.ValidBytes(bytes.TrimSpace(block)) {
+			return j, true
+		}
+	}
+	return last, false
+}
+
+// Line redacts a single line of a log section.
+func (r *Redactor) Line(line []byte) []byte {
+	if r == nil || len(line) == 0 {
+		return line
+	}
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 {
+		return line
+	}
+	if r.redactsBodies() {
+		prefix, payload := splitSSEPrefix(trimmed)
+		if len(payload) > 0 && (payload[0] == '{' || payload[0] == '[') && !bytes.Equal(payload, []byte("[DONE]")) {
+			out := append([]byte{}, prefix...)
+			if !gjson.ValidBytes(payload) {
+				// Typically an event cut off by body truncation.
+				return append(out, redactedUnparsedJSON...)
+			}
+			return append(out, r.redactJSON(payload)...)
+		}
+	}
+	if idx := bytes.IndexByte(line, ':'); idx > 0 {
+		name := string(line[:idx])
+		if !strings.ContainsAny(name, " \t{\"") && r.headerRedacted(name) {
+			return []byte(name + ": " + redactedPlaceholder)
+		}
+	}
+	return line
+}
+
+func splitSSEPrefix(line []byte) ([]byte, []byte) {
+	if !bytes.HasPrefix(line, []byte("data:")) {
+		return nil, line
+	}
+	payload := line[len("data:"):]
+	prefix := line[:len("data:")]
+	if len(payload) > 0 && payload[0] == ' ' {
+		prefix = line[:len("data: ")]
+		payload = payload[1:]
+	}
+	return prefix, payload
+}
+
+func (r *Redactor) redactJSON(doc []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(doc))
+	r.writeValue(&buf, gjson.ParseBytes(doc))
+	return buf.Bytes()
+}
+
+func (r *Redactor) writeValue(buf *bytes.Buffer, value gjson.Result) {
+	switch {
+	case value.IsObject():
+		buf.WriteByte('{')
+		first := true
+		value.ForEach(func(key, field gjson.Result) bool {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			buf.WriteString(key.Raw)
+			buf.WriteByte(':')
+			r.writeField(buf, key.String(), field)
+			return true
+		})
+		buf.WriteByte('}')
+	case value.IsArray():
+		buf.WriteByte('[')
+		first := true
+		value.ForEach(func(_, item gjson.Result) bool {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			r.writeValue(buf, item)
+			return true
+		})
+		buf.WriteByte(']')
+	default:
+		buf.WriteString(value.Raw)
+	}
+}
+
+func (r *Redactor) writeField(buf *bytes.Buffer, key string, value gjson.Result) {
+	lower := strings.ToLower(key)
+	if _, ok := r.fields[lower]; ok {
+		buf.WriteString(strconv.Quote(redactedPlaceholder))
+		return
+	}
+	if r.toolArgs {
+		if _, ok := toolArgumentFields[lower]; ok {
+			writeRedactedSize(buf, value)
+			return
+		}
+		// Claude tool_use blocks carry their arguments as an "input" object.
+		if lower == "input" && value.IsObject() {
+			writeRedactedSize(buf, value)
+			return
+		}
+	}
+	if r.prompts && value.Type == gjson.String {
+		if _, ok := promptFields[lower]; ok {
+			writeRedactedSize(buf, value)
+			return
+		}
+	}
+	r.writeValue(buf, value)
+}
+
+func writeRedactedSize(buf *bytes.Buffer, value gjson.Result) {
+	size := len(value.Raw)
+	if value.Type == gjson.String {
+		size = len(value.String())
+	}
+	buf.WriteString(strconv.Quote(fmt.Sprintf("[redacted %d bytes]", size)))
+}
@@ -0,0 +1,350 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// requestLogIndexFileName is the JSON-lines index kept next to the request log files.
+	requestLogIndexFileName = "request-log-index.jsonl"
+
+	defaultRequestLogSearchLimit = 50
+	maxRequestLogSearchLimit     = 500
+	maxRequestLogTextScanBytes   = 32 << 20
+)
+
+// RequestLogEntry describes one request log file in the index.
+type RequestLogEntry struct {
+	ID        string    `json:"id,omitempty"`
+	File      string    `json:"file"`
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	URL       string    `json:"url"`
+	Status    int       `json:"status,omitempty"`
+	ClientKey string    `json:"client-key,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Size      int64     `json:"size"`
+	Error     bool      `json:"error,omitempty"`
+}
+
+// RequestLogMetadata carries request details that only the HTTP layer knows.
+type RequestLogMetadata struct {
+	// ClientKey names the client key that authenticated the request.
+	ClientKey string
+}
+
+// RequestLogQuery filters a request log search. Zero values match everything.
+type RequestLogQuery struct {
+	// From and To bound the request time; To is exclusive.
+	From      time.Time
+	To        time.Time
+	ClientKey string
+	Model     string
+	// Status matches an exact code ("429") or a class ("4xx").
+	Status string
+	// Text is matched case-insensitively against the log file contents.
+	Text   string
+	Offset int
+	Limit  int
+}
+
+// RequestLogIndex records the request log files written to one logs directory so they can be
+// searched without opening every file. Entries whose file has been removed, for example by the
+// log directory cleaner, are dropped the next time they are seen.
+type RequestLogIndex struct {
+	mu      sync.Mutex
+	dir     string
+	loaded  bool
+	entries []RequestLogEntry
+}
+
+var (
+	requestLogIndexesMu sync.Mutex
+	requestLogIndexes   = make(map[string]*RequestLogIndex)
+)
+
+// RequestLogIndexFor returns the shared index of the given logs directory.
+func RequestLogIndexFor(dir string) *RequestLogIndex {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	requestLogIndexesMu.Lock()
+	defer requestLogIndexesMu.Unlock()
+	idx, ok := requestLogIndexes[dir]
+	if !ok {
+		idx = &RequestLogIndex{dir: dir}
+		requestLogIndexes[dir] = idx
+	}
+	return idx
+}
+
+// Add records a newly written log file.
+func (x *RequestLogIndex) Add(entry RequestLogEntry) {
+	if x == nil || entry.File == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.loadLocked()
+	x.entries = append(x.entries, entry)
+	f, err := os.OpenFile(x.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		log.WithError(err).Warn("failed to open request log index")
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		log.WithError(err).Warn("failed to append request log index")
+	}
+}
+
+// Search returns the entries matching q, newest first, together with the number of matches
+// before pagination.
+func (x *RequestLogIndex) Search(q RequestLogQuery) ([]RequestLogEntry, int, error) {
+	if x == nil {
+		return nil, 0, nil
+	}
+	statusMin, statusMax, err := parseStatusFilter(q.Status)
+	if err != nil {
+		return nil, 0, err
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultRequestLogSearchLimit
+	}
+	limit = min(limit, maxRequestLogSearchLimit)
+	offset := max(q.Offset, 0)
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+
+	x.mu.Lock()
+	x.loadLocked()
+	snapshot := make([]RequestLogEntry, len(x.entries))
+	copy(snapshot, x.entries)
+	x.mu.Unlock()
+
+	sort.SliceStable(snapshot, func(i, j int) bool { return snapshot[i].Time.After(snapshot[j].Time) })
+
+	var (
+		matched []RequestLogEntry
+		missing []string
+	)
+	for _, entry := range snapshot {
+		if !q.From.IsZero() && entry.Time.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && !entry.Time.Before(q.To) {
+			continue
+		}
+		if q.ClientKey != "" && !strings.EqualFold(entry.ClientKey, q.ClientKey) {
+			continue
+		}
+		if q.Model != "" && !strings.EqualFold(entry.Model, q.Model) {
+			continue
+		}
+		if statusMax > 0 && (entry.Status < statusMin || entry.Status > statusMax) {
+			continue
+		}
+		content, errRead := x.readForSearch(entry.File, text != "")
+		if errRead != nil {
+			if os.IsNotExist(errRead) {
+				missing = append(missing, entry.File)
+			}
+			continue
+		}
+		if text != "" && !bytes.Contains(bytes.ToLower(content), []byte(text)) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	if len(missing) > 0 {
+		x.prune(missing)
+	}
+
+	total := len(matched)
+	if offset >= total {
+		return []RequestLogEntry{}, total, nil
+	}
+	end := min(offset+limit, total)
+	return matched[offset:end], total, nil
+}
+
+// readForSearch confirms the log file exists and, when needed, returns its contents for a
+// free-text match. Oversized files are searched in their first maxRequestLogTextScanBytes.
+func (x *RequestLogIndex) readForSearch(name string, needContent bool) ([]byte, error) {
+	path := filepath.Join(x.dir, name)
+	if !needContent {
+		_, err := os.Stat(path)
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(io.LimitReader(f, maxRequestLogTextScanBytes))
+	return buf.Bytes(), err
+}
+
+// prune drops entries for removed files and rewrites the index file.
+func (x *RequestLogIndex) prune(files []string) {
+	gone := make(map[string]struct{}, len(files))
+	for _, name := range files {
+		gone[name] = struct{}{}
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	kept := x.entries[:0]
+	for _, entry := range x.entries {
+		if _, ok := gone[entry.File]; !ok {
+			kept = append(kept, entry)
+		}
+	}
+	x.entries = kept
+	x.rewriteLocked()
+}
+
+func (x *RequestLogIndex) path() string {
+	return filepath.Join(x.dir, requestLogIndexFileName)
+}
+
+// loadLocked reads the index file once, dropping entries whose log file no longer exists.
+func (x *RequestLogIndex) loadLocked() {
+	if x.loaded {
+		return
+	}
+	x.loaded = true
+	f, err := os.Open(x.path())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.WithError(err).Warn("failed to open request log index")
+		}
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	stale := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry RequestLogEntry
+		if errUnmarshal := json.Unmarshal(scanner.Bytes(), &entry); errUnmarshal != nil || entry.File == "" {
+			stale++
+			continue
+		}
+		if _, errStat := os.Stat(filepath.Join(x.dir, entry.File)); errStat != nil {
+			stale++
+			continue
+		}
+		x.entries = append(x.entries, entry)
+	}
+	if stale > 0 {
+		x.rewriteLocked()
+	}
+}
+
+func (x *RequestLogIndex) rewriteLocked() {
+	var buf bytes.Buffer
+	for _, entry := range x.entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	tmp := x.path() + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		log.WithError(err).Warn("failed to rewrite request log index")
+		return
+	}
+	if err := os.Rename(tmp, x.path()); err != nil {
+		_ = os.Remove(tmp)
+		log.WithError(err).Warn("failed to replace request log index")
+	}
+}
+
+// newRequestLogEntry describes the log file just written at path.
+func newRequestLogEntry(path, requestID string, timestamp time.Time, method, url string, status int, model string, meta RequestLogMetadata, forced bool) RequestLogEntry {
+	entry := RequestLogEntry{
+		ID:        requestID,
+		File:      filepath.Base(path),
+		Time:      timestamp.UTC(),
+		Method:    method,
+		URL:       url,
+		Status:    status,
+		ClientKey: meta.ClientKey,
+		Model:     model,
+		Error:     forced,
+	}
+	if info, err := os.Stat(path); err == nil {
+		entry.Size = info.Size()
+	}
+	return entry
+}
+
+// RequestLogModel returns the model named in the request body, or in the URL for the Gemini
+// style ".../models/{model}:action" routes.
+func RequestLogModel(url string, body []byte) string {
+	if len(body) > 0 {
+		if model := gjson.GetBytes(body, "model"); model.Type == gjson.String && model.String() != "" {
+			return model.String()
+		}
+	}
+	path := url
+	if idx := strings.IndexByte(path, '?'); idx >= 0 {
+		path = path[:idx]
+	}
+	idx := strings.LastIndex(path, "/models/")
+	if idx < 0 {
+		return ""
+	}
+	model := path[idx+len("/models/"):]
+	if colon := strings.IndexByte(model, ':'); colon >= 0 {
+		model = model[:colon]
+	}
+	if slash := strings.IndexByte(model, '/'); slash >= 0 {
+		model = model[:slash]
+	}
+	return model
+}
+
+// parseStatusFilter turns "429" or "4xx" into an inclusive range. An empty filter returns 0, 0.
+func parseStatusFilter(raw string) (int, int, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return 0, 0, nil
+	}
+	if len(raw) == 3 && strings.HasSuffix(raw, "xx") && raw[0] >= '1' && raw[0] <= '5' {
+		base := int(raw[0]-'0') * 100
+		return base, base + 99, nil
+	}
+	code, err := strconv.Atoi(raw)
+	if err != nil || code < 100 || code > 599 {
+		return 0, 0, errors.New("status must be a code such as 429 or a class such as 4xx")
+	}
+	return code, code, nil
+}
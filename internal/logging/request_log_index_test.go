@@ -0,0 +1,126 @@
+package logging
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestRedactorScrubsPromptsToolArgumentsAndHeaders(t *testing.T) {
+	r := NewRedactor(config.RequestLogRedactionConfig{Prompts: true, ToolArguments: true, AuthHeaders: true, Fields: []string{"user_id"}})
+
+	body := []byte(`{"model":"gpt-5","messages":[{"role":"user","content":"secret prompt"},{"role":"assistant","tool_calls":[{"function":{"name":"lookup","arguments":"{\"q\":\"secret arg\"}"}}]}],"metadata":{"user_id":"u-1"}}`)
+	got := string(r.Body(body))
+	for _, leaked := range []string{"secret prompt", "secret arg", "u-1"} {
+		if strings.Contains(got, leaked) {
+			t.Fatalf("redacted body still contains %q: %s", leaked, got)
+		}
+	}
+	if !strings.Contains(got, `"model":"gpt-5"`) || !strings.Contains(got, `"name":"lookup"`) {
+		t.Fatalf("redacted body lost unrelated fields: %s", got)
+	}
+
+	stream := []byte("event: content_block_delta\ndata: {\"delta\":{\"type\":\"text_delta\",\"text\":\"secret reply\"}}\n\n")
+	if got = string(r.Body(stream)); strings.Contains(got, "secret reply") || !strings.Contains(got, "event: content_block_delta") {
+		t.Fatalf("unexpected redacted stream: %q", got)
+	}
+
+	section := []byte("Headers:\nAuthorization: Bearer sk-secret\nContent-Type: application/json\n\nBody:\n{\n  \"prompt\": \"secret\"\n}\n")
+	got = string(r.Text(section))
+	if strings.Contains(got, "sk-secret") || strings.Contains(got, `"secret"`) || !strings.Contains(got, "Content-Type: application/json") {
+		t.Fatalf("unexpected redacted section: %q", got)
+	}
+
+	pretty := []byte("{\n  \"model\": \"gpt-5\",\n  \"messages\": [\n    {\"role\": \"user\", \"content\": \"secret prompt\"},\n    {\"role\": \"user\", \"content\": \"more secret\"}\n  ]\n}\n")
+	// Streamed bodies are cut at max-body-bytes before they reach the redactor.
+	if got = string(r.Body(pretty[:len(pretty)/2+10])); got != redactedUnparsedJSON {
+		t.Fatalf("unexpected redacted truncated body: %q", got)
+	}
+
+	headers := r.Headers(map[string][]string{"X-Api-Key": {"k"}, "Accept": {"*/*"}})
+	if headers["X-Api-Key"][0] != redactedPlaceholder || headers["Accept"][0] != "*/*" {
+		t.Fatalf("unexpected redacted headers: %v", headers)
+	}
+}
+
+func TestRequestLogIndexSearch(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewFileRequestLogger(true, dir, "")
+	logger.SetRedaction(config.RequestLogRedactionConfig{Prompts: true})
+
+	requests := []struct {
+		id, url, body string
+		status        int
+		client        string
+	}{
+		{"r1", "/v1/chat/completions", `{"model":"gpt-5","messages":[{"role":"user","content":"hello"}]}`, http.StatusOK, "team-a"},
+		{"r2", "/v1beta/models/gemini-2.5-pro:generateContent", `{"contents":[]}`, http.StatusTooManyRequests, "team-b"},
+		{"r3", "/v1/messages", `{"model":"claude-sonnet-4","system":"needle"}`, http.StatusOK, "team-a"},
+	}
+	for _, req := range requests {
+		response := []byte(`{"id":"` + req.id + `","note":"marker-` + req.id + `"}`)
+		if err := logger.LogRequestWithMetadata(req.url, http.MethodPost, nil, []byte(req.body), req.status, nil, response, nil, nil, nil, false, req.id, RequestLogMetadata{ClientKey: req.client}); err != nil {
+			t.Fatalf("log %s: %v", req.id, err)
+		}
+	}
+
+	index := RequestLogIndexFor(dir)
+	search := func(q RequestLogQuery) []string {
+		t.Helper()
+		entries, _, err := index.Search(q)
+		if err != nil {
+			t.Fatalf("search %+v: %v", q, err)
+		}
+		ids := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+
+	if ids := search(RequestLogQuery{ClientKey: "team-a"}); len(ids) != 2 {
+		t.Fatalf("client key filter = %v", ids)
+	}
+	if ids := search(RequestLogQuery{Model: "gemini-2.5-pro"}); len(ids) != 1 || ids[0] != "r2" {
+		t.Fatalf("model filter = %v", ids)
+	}
+	if ids := search(RequestLogQuery{Status: "4xx"}); len(ids) != 1 || ids[0] != "r2" {
+		t.Fatalf("status filter = %v", ids)
+	}
+	if ids := search(RequestLogQuery{Text: "MARKER-R3"}); len(ids) != 1 || ids[0] != "r3" {
+		t.Fatalf("text filter = %v", ids)
+	}
+	if ids := search(RequestLogQuery{Text: "needle"}); len(ids) != 0 {
+		t.Fatalf("redacted prompt is searchable: %v", ids)
+	}
+	if ids := search(RequestLogQuery{To: time.Now().Add(-time.Hour)}); len(ids) != 0 {
+		t.Fatalf("time filter = %v", ids)
+	}
+	entries, total, _ := index.Search(RequestLogQuery{Offset: 1, Limit: 1})
+	if total != 3 || len(entries) != 1 {
+		t.Fatalf("pagination returned %d of %d", len(entries), total)
+	}
+	if _, _, err := index.Search(RequestLogQuery{Status: "bad"}); err == nil {
+		t.Fatalf("expected an error for an invalid status filter")
+	}
+
+	// Removed files drop out of the index and the rewritten index file.
+	if err := os.Remove(filepath.Join(dir, entries[0].File)); err != nil {
+		t.Fatalf("remove log: %v", err)
+	}
+	if _, total, _ = index.Search(RequestLogQuery{}); total != 2 {
+		t.Fatalf("total after removal = %d", total)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, requestLogIndexFileName))
+	if err != nil {
+		t.Fatalf("read index: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Fatalf("index has %d lines after pruning", lines)
+	}
+}
@@ -0,0 +1,159 @@
+package logging
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// RequestLogSample describes a finished (or, for streams, a starting) request for sampling.
+type RequestLogSample struct {
+	ClientKey string
+	Model     string
+	Status    int
+	// Latency is the request duration. A negative latency means it is not known yet, and
+	// latency conditions are treated as satisfied.
+	Latency time.Duration
+	// Draw is a per-request random number in [0, 100) compared against rule percentages, so a
+	// request is judged consistently when it is checked more than once.
+	Draw float64
+}
+
+// RequestLogSampler decides which requests are written while request logging is enabled.
+type RequestLogSampler struct {
+	rules atomic.Pointer[[]sampleRule]
+}
+
+type sampleRule struct {
+	percentage float64
+	clientKeys []string
+	models     []string
+	minLatency time.Duration
+	statuses   [][2]int
+}
+
+var defaultRequestLogSampler = &RequestLogSampler{}
+
+// DefaultRequestLogSampler returns the process-wide sampler used by the request logging middleware.
+func DefaultRequestLogSampler() *RequestLogSampler {
+	return defaultRequestLogSampler
+}
+
+// Configure replaces the sampling rules. Invalid status filters are logged and ignored.
+func (s *RequestLogSampler) Configure(cfg config.RequestLogSamplingConfig) {
+	if len(cfg.Rules) == 0 {
+		s.rules.Store(nil)
+		return
+	}
+	rules := make([]sampleRule, 0, len(cfg.Rules))
+	for i, rule := range cfg.Rules {
+		compiled := sampleRule{
+			percentage: rule.Percentage,
+			clientKeys: trimmedNonEmpty(rule.ClientKeys),
+			models:     trimmedNonEmpty(rule.Models),
+			minLatency: time.Duration(rule.MinLatencyMs) * time.Millisecond,
+		}
+		if compiled.percentage <= 0 || compiled.percentage > 100 {
+			compiled.percentage = 100
+		}
+		for _, raw := range rule.Statuses {
+			lo, hi, err := parseStatusFilter(raw)
+			if err != nil {
+				log.Warnf("request-log-sampling rule %d: ignoring status %q: %v", i, raw, err)
+				continue
+			}
+			if hi > 0 {
+				compiled.statuses = append(compiled.statuses, [2]int{lo, hi})
+			}
+		}
+		rules = append(rules, compiled)
+	}
+	s.rules.Store(&rules)
+}
+
+// Active reports whether sampling rules are configured. Without rules every request is logged.
+func (s *RequestLogSampler) Active() bool {
+	if s == nil {
+		return false
+	}
+	rules := s.rules.Load()
+	return rules != nil && len(*rules) > 0
+}
+
+// Sample reports whether the request matches at least one rule. It returns true when no rules
+// are configured.
+func (s *RequestLogSampler) Sample(in RequestLogSample) bool {
+	if s == nil {
+		return true
+	}
+	rules := s.rules.Load()
+	if rules == nil || len(*rules) == 0 {
+		return true
+	}
+	for i := range *rules {
+		if (*rules)[i].matches(in) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *sampleRule) matches(in RequestLogSample) bool {
+	if in.Draw >= r.percentage {
+		return false
+	}
+	if len(r.clientKeys) > 0 && !containsFold(r.clientKeys, in.ClientKey) {
+		return false
+	}
+	if len(r.models) > 0 {
+		matched := false
+		for _, pattern := range r.models {
+			if util.MatchModelPattern(strings.ToLower(pattern), strings.ToLower(in.Model)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if r.minLatency > 0 && in.Latency >= 0 && in.Latency < r.minLatency {
+		return false
+	}
+	if len(r.statuses) > 0 {
+		matched := false
+		for _, status := range r.statuses {
+			if in.Status >= status[0] && in.Status <= status[1] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func trimmedNonEmpty(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+	}
+	return false
+}
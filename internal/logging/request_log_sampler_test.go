@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestRequestLogSamplerRules(t *testing.T) {
+	sampler := &RequestLogSampler{}
+	if !sampler.Sample(RequestLogSample{Draw: 99}) {
+		t.Fatalf("sampler without rules must log every request")
+	}
+	sampler.Configure(config.RequestLogSamplingConfig{Rules: []config.RequestLogSampleRule{
+		{Percentage: 10},
+		{Statuses: []string{"5xx", "429"}},
+		{MinLatencyMs: 1000},
+		{ClientKeys: []string{"team-a"}, Models: []string{"gpt-5*"}},
+	}})
+
+	cases := []struct {
+		name string
+		in   RequestLogSample
+		want bool
+	}{
+		{"within percentage", RequestLogSample{Draw: 5, Status: 200}, true},
+		{"outside percentage", RequestLogSample{Draw: 50, Status: 200}, false},
+		{"status class", RequestLogSample{Draw: 50, Status: 502}, true},
+		{"exact status", RequestLogSample{Draw: 50, Status: 429}, true},
+		{"slow", RequestLogSample{Draw: 50, Status: 200, Latency: 2 * time.Second}, true},
+		{"latency unknown", RequestLogSample{Draw: 50, Status: 200, Latency: -1}, true},
+		{"client and model", RequestLogSample{Draw: 50, Status: 200, ClientKey: "TEAM-A", Model: "gpt-5-codex"}, true},
+		{"client other model", RequestLogSample{Draw: 50, Status: 200, ClientKey: "team-a", Model: "claude-sonnet-4"}, false},
+	}
+	for _, tc := range cases {
+		if got := sampler.Sample(tc.in); got != tc.want {
+			t.Fatalf("%s: Sample() = %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRequestLoggerTruncatesBodies(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewFileRequestLogger(true, dir, "")
+	logger.SetMaxBodyBytes(16)
+
+	body := []byte(strings.Repeat("a", 40))
+	if err := logger.LogRequest("/v1/chat/completions", http.MethodPost, nil, body, http.StatusOK, nil, []byte(strings.Repeat("b", 20)), nil, nil, nil, "t1"); err != nil {
+		t.Fatalf("log request: %v", err)
+	}
+	writer, err := logger.LogStreamingRequest("/v1/chat/completions", http.MethodPost, nil, nil, "t2")
+	if err != nil {
+		t.Fatalf("start stream: %v", err)
+	}
+	_ = writer.WriteStatus(http.StatusOK, nil)
+	writer.WriteChunkAsync([]byte(strings.Repeat("c", 10)))
+	writer.WriteChunkAsync([]byte(strings.Repeat("d", 10)))
+	if err = writer.Close(); err != nil {
+		t.Fatalf("close stream: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.log"))
+	if len(files) != 2 {
+		t.Fatalf("expected 2 log files, got %v", files)
+	}
+	for _, file := range files {
+		data, errRead := os.ReadFile(file)
+		if errRead != nil {
+			t.Fatalf("read %s: %v", file, errRead)
+		}
+		content := string(data)
+		switch {
+		case strings.HasSuffix(file, "-t1.log"):
+			if strings.Contains(content, strings.Repeat("a", 17)) || !strings.Contains(content, "[truncated: 24 bytes omitted]") || !strings.Contains(content, "[truncated: 4 bytes omitted]") {
+				t.Fatalf("non-streaming log not truncated:\n%s", content)
+			}
+		case strings.HasSuffix(file, "-t2.log"):
+			if !strings.Contains(content, strings.Repeat("c", 10)+strings.Repeat("d", 6)+"\n[truncated: 4 bytes omitted]") {
+				t.Fatalf("streaming log not truncated:\n%s", content)
+			}
+		}
+	}
+
+	discarded, err := logger.LogStreamingRequest("/v1/chat/completions", http.MethodPost, nil, nil, "t3")
+	if err != nil {
+		t.Fatalf("start stream: %v", err)
+	}
+	if err = discarded.(*FileStreamingLogWriter).Discard(); err != nil {
+		t.Fatalf("discard: %v", err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "*t3*")); len(leftovers) != 0 {
+		t.Fatalf("discarded stream left files: %v", leftovers)
+	}
+	if temps, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(temps) != 0 {
+		t.Fatalf("temp files left behind: %v", temps)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 )
@@ -124,6 +125,15 @@ type FileRequestLogger struct {
 
 	// logsDir is the directory where log files are stored.
 	logsDir string
+
+	// redactor scrubs log content before it is written; nil disables redaction.
+	redactor atomic.Pointer[Redactor]
+
+	// index records written log files for the management search endpoint.
+	index *RequestLogIndex
+
+	// maxBodyBytes truncates each logged body and upstream section; 0 disables truncation.
+	maxBodyBytes atomic.Int64
 }
 
 // NewFileRequestLogger creates a new file-based request logger.
@@ -147,6 +157,7 @@ func NewFileRequestLogger(enabled bool, logsDir string, configDir string) *FileR
 	return &FileRequestLogger{
 		enabled: enabled,
 		logsDir: logsDir,
+		index:   RequestLogIndexFor(logsDir),
 	}
 }
 
@@ -167,6 +178,22 @@ func (l *FileRequestLogger) SetEnabled(enabled bool) {
 	l.enabled = enabled
 }
 
+// SetRedaction replaces the redaction rules applied to subsequently written logs.
+//
+// Parameters:
+//   - cfg: The redaction configuration
+func (l *FileRequestLogger) SetRedaction(cfg config.RequestLogRedactionConfig) {
+	l.redactor.Store(NewRedactor(cfg))
+}
+
+// SetMaxBodyBytes limits the size of each logged body and upstream section.
+//
+// Parameters:
+//   - limit: The maximum number of bytes kept per section; 0 or less disables truncation
+func (l *FileRequestLogger) SetMaxBodyBytes(limit int) {
+	l.maxBodyBytes.Store(int64(max(limit, 0)))
+}
+
 // LogRequest logs a complete non-streaming request/response cycle to a file.
 //
 // Parameters:
@@ -184,19 +211,27 @@ func (l *FileRequestLogger) SetEnabled(enabled bool) {
 // Returns:
 //   - error: An error if logging fails, nil otherwise
 func (l *FileRequestLogger) LogRequest(url, method string, requestHeaders map[string][]string, body []byte, statusCode int, responseHeaders map[string][]string, response, apiRequest, apiResponse []byte, apiResponseErrors []*interfaces.ErrorMessage, requestID string) error {
-	return l.logRequest(url, method, requestHeaders, body, statusCode, responseHeaders, response, apiRequest, apiResponse, apiResponseErrors, false, requestID)
+	return l.logRequest(url, method, requestHeaders, body, statusCode, responseHeaders, response, apiRequest, apiResponse, apiResponseErrors, false, requestID, RequestLogMetadata{})
 }
 
 // LogRequestWithOptions logs a request with optional forced logging behavior.
 // The force flag allows writing error logs even when regular request logging is disabled.
 func (l *FileRequestLogger) LogRequestWithOptions(url, method string, requestHeaders map[string][]string, body []byte, statusCode int, responseHeaders map[string][]string, response, apiRequest, apiResponse []byte, apiResponseErrors []*interfaces.ErrorMessage, force bool, requestID string) error {
-	return l.logRequest(url, method, requestHeaders, body, statusCode, responseHeaders, response, apiRequest, apiResponse, apiResponseErrors, force, requestID)
+	return l.logRequest(url, method, requestHeaders, body, statusCode, responseHeaders, response, apiRequest, apiResponse, apiResponseErrors, force, requestID, RequestLogMetadata{})
 }
 
-func (l *FileRequestLogger) logRequest(url, method string, requestHeaders map[string][]string, body []byte, statusCode int, responseHeaders map[string][]string, response, apiRequest, apiResponse []byte, apiResponseErrors []*interfaces.ErrorMessage, force bool, requestID string) error {
+// LogRequestWithMetadata behaves like LogRequestWithOptions and also records meta in the
+// request log index.
+func (l *FileRequestLogger) LogRequestWithMetadata(url, method string, requestHeaders map[string][]string, body []byte, statusCode int, responseHeaders map[string][]string, response, apiRequest, apiResponse []byte, apiResponseErrors []*interfaces.ErrorMessage, force bool, requestID string, meta RequestLogMetadata) error {
+	return l.logRequest(url, method, requestHeaders, body, statusCode, responseHeaders, response, apiRequest, apiResponse, apiResponseErrors, force, requestID, meta)
+}
+
+func (l *FileRequestLogger) logRequest(url, method string, requestHeaders map[string][]string, body []byte, statusCode int, responseHeaders map[string][]string, response, apiRequest, apiResponse []byte, apiResponseErrors []*interfaces.ErrorMessage, force bool, requestID string, meta RequestLogMetadata) error {
 	if !l.enabled && !force {
 		return nil
 	}
+	timestamp := time.Now()
+	model := RequestLogModel(url, body)
 
 	// Ensure logs directory exists
 	if errEnsure := l.ensureLogsDir(); errEnsure != nil {
@@ -210,6 +245,15 @@ func (l *FileRequestLogger) logRequest(url, method string, requestHeaders map[st
 	}
 	filePath := filepath.Join(l.logsDir, filename)
 
+	redactor := l.redactor.Load()
+	limit := l.maxBodyBytes.Load()
+	requestHeaders = redactor.Headers(requestHeaders)
+	body = truncateLogBody(redactor.Body(body), limit, 0)
+	apiRequest = truncateLogBody(redactor.Text(apiRequest), limit, 0)
+	apiResponse = truncateLogBody(redactor.Text(apiResponse), limit, 0)
+	apiResponseErrors = redactor.errorMessages(apiResponseErrors)
+	responseHeaders = redactor.Headers(responseHeaders)
+
 	requestBodyPath, errTemp := l.writeRequestBodyTempFile(body)
 	if errTemp != nil {
 		log.WithError(errTemp).Warn("failed to create request body temp file, falling back to direct write")
@@ -227,6 +271,7 @@ func (l *FileRequestLogger) logRequest(url, method string, requestHeaders map[st
 		// If decompression fails, continue with original response and annotate the log output.
 		responseToWrite = response
 	}
+	responseToWrite = truncateLogBody(redactor.Body(responseToWrite), limit, 0)
 
 	logFile, errOpen := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if errOpen != nil {
@@ -257,6 +302,7 @@ func (l *FileRequestLogger) logRequest(url, method string, requestHeaders map[st
 	if writeErr != nil {
 		return fmt.Errorf("failed to write log file: %w", writeErr)
 	}
+	l.index.Add(newRequestLogEntry(filePath, requestID, timestamp, method, url, statusCode, model, meta, force && !l.enabled))
 
 	if force && !l.enabled {
 		if errCleanup := l.cleanupOldErrorLogs(); errCleanup != nil {
@@ -300,7 +346,12 @@ func (l *FileRequestLogger) LogStreamingRequest(url, method string, headers map[
 		requestHeaders[key] = headerValues
 	}
 
-	requestBodyPath, errTemp := l.writeRequestBodyTempFile(body)
+	redactor := l.redactor.Load()
+	limit := l.maxBodyBytes.Load()
+	requestHeaders = redactor.Headers(requestHeaders)
+	model := RequestLogModel(url, body)
+
+	requestBodyPath, errTemp := l.writeRequestBodyTempFile(truncateLogBody(redactor.Body(body), limit, 0))
 	if errTemp != nil {
 		return nil, fmt.Errorf("failed to create request body temp file: %w", errTemp)
 	}
@@ -317,6 +368,11 @@ func (l *FileRequestLogger) LogStreamingRequest(url, method string, headers map[
 		logFilePath:      filePath,
 		url:              url,
 		method:           method,
+		requestID:        requestID,
+		model:            model,
+		redactor:         redactor,
+		index:            l.index,
+		maxBodyBytes:     limit,
 		timestamp:        time.Now(),
 		requestHeaders:   requestHeaders,
 		requestBodyPath:  requestBodyPath,
@@ -547,7 +603,10 @@ func writeRequestInfoWithBody(
 	}
 	for key, values := range headers {
 		for _, value := range values {
-			masked := util.MaskSensitiveHeaderValue(key, value)
+			masked := value
+			if value != redactedPlaceholder {
+				masked = util.MaskSensitiveHeaderValue(key, value)
+			}
 			if _, errWrite := io.WriteString(w, fmt.Sprintf("%s: %s\n", key, masked)); errWrite != nil {
 				return errWrite
 			}
@@ -936,6 +995,30 @@ type FileStreamingLogWriter struct {
 	// method is the HTTP method.
 	method string
 
+	// requestID is the request ID used in the log file name.
+	requestID string
+
+	// model is the model named by the request, recorded in the index.
+	model string
+
+	// meta holds request details supplied by the HTTP layer before Close.
+	meta RequestLogMetadata
+
+	// redactor scrubs log content before the final log is written; nil disables redaction.
+	redactor *Redactor
+
+	// index receives the entry of the final log file.
+	index *RequestLogIndex
+
+	// maxBodyBytes caps the spooled response body and each upstream section; 0 disables it.
+	maxBodyBytes int64
+
+	// spooledBytes counts the response bytes written to the temp file.
+	spooledBytes int64
+
+	// omittedBytes counts the response bytes dropped once maxBodyBytes was reached.
+	omittedBytes int64
+
 	// timestamp is captured when the streaming log is initialized.
 	timestamp time.Time
 
@@ -1053,6 +1136,24 @@ func (w *FileStreamingLogWriter) WriteAPIResponse(apiResponse []byte) error {
 	return nil
 }
 
+// Discard stops spooling and removes the temporary files without writing a log file. It is
+// used when a streaming request turns out not to be sampled.
+//
+// Returns:
+//   - error: Always returns nil
+func (w *FileStreamingLogWriter) Discard() error {
+	w.logFilePath = ""
+	return w.Close()
+}
+
+// SetMetadata records request details for the request log index.
+//
+// Parameters:
+//   - meta: The request metadata
+func (w *FileStreamingLogWriter) SetMetadata(meta RequestLogMetadata) {
+	w.meta = meta
+}
+
 // Close finalizes the log file and cleans up resources.
 // It writes all buffered data to the file in the correct order:
 // API REQUEST -> API RESPONSE -> RESPONSE (status, headers, body chunks)
@@ -1095,6 +1196,9 @@ func (w *FileStreamingLogWriter) Close() error {
 			writeErr = errClose
 		}
 	}
+	if writeErr == nil {
+		w.index.Add(newRequestLogEntry(w.logFilePath, w.requestID, w.timestamp, w.method, w.url, w.responseStatus, w.model, w.meta, false))
+	}
 
 	w.cleanupTempFiles()
 	return writeErr
@@ -1109,6 +1213,17 @@ func (w *FileStreamingLogWriter) asyncWriter() {
 		if w.responseBodyFile == nil {
 			continue
 		}
+		if w.maxBodyBytes > 0 {
+			room := max(w.maxBodyBytes-w.spooledBytes, 0)
+			if int64(len(chunk)) > room {
+				w.omittedBytes += int64(len(chunk)) - room
+				chunk = chunk[:room]
+			}
+			if len(chunk) == 0 {
+				continue
+			}
+		}
+		w.spooledBytes += int64(len(chunk))
 		if _, errWrite := w.responseBodyFile.Write(chunk); errWrite != nil {
 			select {
 			case w.errorChan <- errWrite:
@@ -1140,13 +1255,23 @@ func (w *FileStreamingLogWriter) writeFinalLog(logFile *os.File) error {
 	if errWrite := writeRequestInfoWithBody(logFile, w.url, w.method, w.requestHeaders, nil, w.requestBodyPath, w.timestamp); errWrite != nil {
 		return errWrite
 	}
-	if errWrite := writeAPISection(logFile, "=== API REQUEST ===\n", "=== API REQUEST", w.apiRequest); errWrite != nil {
+	if errWrite := writeAPISection(logFile, "=== API REQUEST ===\n", "=== API REQUEST", truncateLogBody(w.redactor.Text(w.apiRequest), w.maxBodyBytes, 0)); errWrite != nil {
 		return errWrite
 	}
-	if errWrite := writeAPISection(logFile, "=== API RESPONSE ===\n", "=== API RESPONSE", w.apiResponse); errWrite != nil {
+	if errWrite := writeAPISection(logFile, "=== API RESPONSE ===\n", "=== API RESPONSE", truncateLogBody(w.redactor.Text(w.apiResponse), w.maxBodyBytes, 0)); errWrite != nil {
 		return errWrite
 	}
 
+	if w.redactor != nil || w.omittedBytes > 0 {
+		// Events may span chunks, so the spooled stream is redacted as a whole.
+		responseBody, errRead := os.ReadFile(w.responseBodyPath)
+		if errRead != nil {
+			return errRead
+		}
+		responseBody = truncateLogBody(w.redactor.Body(responseBody), w.maxBodyBytes, w.omittedBytes)
+		return writeResponseSection(logFile, w.responseStatus, w.statusWritten, w.redactor.Headers(w.responseHeaders), bytes.NewReader(responseBody), nil, false)
+	}
+
 	responseBodyFile, errOpen := os.Open(w.responseBodyPath)
 	if errOpen != nil {
 		return errOpen
@@ -1160,6 +1285,23 @@ func (w *FileStreamingLogWriter) writeFinalLog(logFile *os.File) error {
 	return writeResponseSection(logFile, w.responseStatus, w.statusWritten, w.responseHeaders, responseBodyFile, nil, false)
 }
 
+// truncateLogBody cuts data to limit bytes and appends a marker naming the omitted byte count,
+// including alreadyOmitted bytes dropped earlier. A limit of 0 or less keeps data whole.
+func truncateLogBody(data []byte, limit, alreadyOmitted int64) []byte {
+	omitted := alreadyOmitted
+	if limit > 0 && int64(len(data)) > limit {
+		omitted += int64(len(data)) - limit
+		data = data[:limit]
+	}
+	if omitted <= 0 {
+		return data
+	}
+	marker := fmt.Sprintf("\n[truncated: %d bytes omitted]", omitted)
+	out := make([]byte, 0, len(data)+len(marker))
+	out = append(out, data...)
+	return append(out, marker...)
+}
+
 func (w *FileStreamingLogWriter) cleanupTempFiles() {
 	if w.requestBodyPath != "" {
 		if errRemove := os.Remove(w.requestBodyPath); errRemove != nil {
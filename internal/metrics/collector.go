@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+var (
+	metricsEnabled   atomic.Bool
+	defaultCollector = NewCollector()
+)
+
+func init() {
+	coreusage.RegisterPlugin(defaultCollector)
+}
+
+// SetEnabled toggles whether usage records and auth events are aggregated.
+func SetEnabled(enabled bool) { metricsEnabled.Store(enabled) }
+
+// Enabled reports whether metrics collection is active.
+func Enabled() bool { return metricsEnabled.Load() }
+
+// DefaultCollector returns the shared collector fed by the usage pipeline.
+func DefaultCollector() *Collector { return defaultCollector }
+
+// Collector aggregates usage records and auth lifecycle events into Prometheus metrics.
+// It implements coreusage.Plugin, coreauth.Hook and coreauth.ResultStateHook.
+type Collector struct {
+	requests       *series
+	tokens         *series
+	latency        *series
+	upstreamErrors *series
+	cooldowns      *series
+	authAvailable  *series
+
+	mu    sync.Mutex
+	auths map[string]authLabels
+}
+
+type authLabels struct {
+	provider string
+	index    string
+}
+
+// NewCollector constructs an empty collector.
+func NewCollector() *Collector {
+	return &Collector{
+		requests: newSeries("cliproxy_requests_total",
+			"Total upstream requests by provider, model, credential and result.",
+			kindCounter, "provider", "model", "auth_index", "result"),
+		tokens: newSeries("cliproxy_tokens_total",
+			"Total tokens consumed by provider, model and token type.",
+			kindCounter, "provider", "model", "type"),
+		latency: newHistogram("cliproxy_request_duration_seconds",
+			"Upstream request latency in seconds.",
+			defaultLatencyBuckets, "provider", "model"),
+		upstreamErrors: newSeries("cliproxy_upstream_errors_total",
+			"Total upstream errors by provider, model and HTTP status.",
+			kindCounter, "provider", "model", "status"),
+		cooldowns: newSeries("cliproxy_auth_cooldowns_total",
+			"Total times a credential entered cooldown after a rate limit.",
+			kindCounter, "provider", "auth_index"),
+		authAvailable: newSeries("cliproxy_auth_available",
+			"Whether an enabled credential is currently selectable (1) or cooling down (0).",
+			kindGauge, "provider", "auth_index"),
+		auths: make(map[string]authLabels),
+	}
+}
+
+// HandleUsage implements coreusage.Plugin.
+func (c *Collector) HandleUsage(_ context.Context, record coreusage.Record) {
+	if c == nil || !metricsEnabled.Load() {
+		return
+	}
+	provider := labelOrUnknown(record.Provider)
+	model := labelOrUnknown(record.Model)
+	result := "success"
+	if record.Failed {
+		result = "failure"
+	}
+	c.requests.add(1, provider, model, strings.TrimSpace(record.AuthIndex), result)
+
+	detail := record.Detail
+	c.addTokens(provider, model, "input", detail.InputTokens)
+	c.addTokens(provider, model, "output", detail.OutputTokens)
+	c.addTokens(provider, model, "reasoning", detail.ReasoningTokens)
+	c.addTokens(provider, model, "cached", detail.CachedTokens)
+
+	if !record.RequestedAt.IsZero() {
+		if elapsed := time.Since(record.RequestedAt); elapsed >= 0 {
+			c.latency.observe(elapsed.Seconds(), provider, model)
+		}
+	}
+}
+
+func (c *Collector) addTokens(provider, model, kind string, value int64) {
+	if value <= 0 {
+		return
+	}
+	c.tokens.add(float64(value), provider, model, kind)
+}
+
+// OnAuthRegistered implements coreauth.Hook.
+func (c *Collector) OnAuthRegistered(_ context.Context, auth *coreauth.Auth) {
+	c.trackAuth(auth)
+}
+
+// OnAuthUpdated implements coreauth.Hook.
+func (c *Collector) OnAuthUpdated(_ context.Context, auth *coreauth.Auth) {
+	c.trackAuth(auth)
+}
+
+// OnResult implements coreauth.Hook.
+func (c *Collector) OnResult(_ context.Context, result coreauth.Result) {
+	if c == nil || !metricsEnabled.Load() || result.Success {
+		return
+	}
+	provider := labelOrUnknown(result.Provider)
+	model := labelOrUnknown(result.Model)
+	status := 0
+	if result.Error != nil {
+		status = result.Error.HTTPStatus
+	}
+	c.upstreamErrors.add(1, provider, model, strconv.Itoa(status))
+}
+
+// OnResultApplied implements coreauth.ResultStateHook. It refreshes the availability gauge from
+// the credential state the result produced and counts rate limits that scheduled a cooldown;
+// with disable-cooling and no upstream retry hint nothing is scheduled and nothing is counted.
+func (c *Collector) OnResultApplied(_ context.Context, result coreauth.Result, auth *coreauth.Auth) {
+	if c == nil || auth == nil {
+		return
+	}
+	c.trackAuth(auth)
+	if !metricsEnabled.Load() || result.Success || result.Error == nil || result.Error.HTTPStatus != 429 {
+		return
+	}
+	next := auth.NextRetryAfter
+	if result.Model != "" {
+		next = time.Time{}
+		if state := auth.ModelStates[result.Model]; state != nil {
+			next = state.NextRetryAfter
+		}
+	}
+	if next.After(time.Now()) {
+		c.cooldowns.add(1, labelOrUnknown(auth.Provider), c.authIndex(auth.ID))
+	}
+}
+
+// trackAuth updates the availability gauge of the credential. Disabled credentials, which
+// include removed ones, have their series deleted so they do not linger in the output.
+func (c *Collector) trackAuth(auth *coreauth.Auth) {
+	if c == nil || auth == nil {
+		return
+	}
+	labels := authLabels{provider: labelOrUnknown(auth.Provider), index: auth.Index}
+	if labels.index == "" {
+		labels.index = auth.ID
+	}
+	removed := auth.Disabled || auth.Status == coreauth.StatusDisabled
+	c.mu.Lock()
+	previous, known := c.auths[auth.ID]
+	if removed {
+		delete(c.auths, auth.ID)
+	} else {
+		c.auths[auth.ID] = labels
+	}
+	c.mu.Unlock()
+
+	if known && (removed || previous != labels) {
+		c.authAvailable.delete(previous.provider, previous.index)
+		c.cooldowns.delete(previous.provider, previous.index)
+	}
+	if removed {
+		return
+	}
+	available := 1.0
+	if auth.Unavailable {
+		available = 0
+	}
+	c.authAvailable.set(available, labels.provider, labels.index)
+}
+
+func (c *Collector) authIndex(authID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if labels, ok := c.auths[authID]; ok {
+		return labels.index
+	}
+	return authID
+}
+
+// Write renders every metric family in the Prometheus text exposition format.
+func (c *Collector) Write(w io.Writer) error {
+	if c == nil {
+		return nil
+	}
+	for _, s := range []*series{c.requests, c.tokens, c.latency, c.upstreamErrors, c.cooldowns, c.authAvailable} {
+		if err := s.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset clears all aggregated samples.
+func (c *Collector) Reset() {
+	if c == nil {
+		return
+	}
+	for _, s := range []*series{c.requests, c.tokens, c.latency, c.upstreamErrors, c.cooldowns, c.authAvailable} {
+		s.reset()
+	}
+	c.mu.Lock()
+	c.auths = make(map[string]authLabels)
+	c.mu.Unlock()
+}
+
+func labelOrUnknown(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestCollectorRendersUsageAndAuthMetrics(t *testing.T) {
+	SetEnabled(true)
+	defer SetEnabled(false)
+
+	c := NewCollector()
+	ctx := context.Background()
+	c.OnAuthRegistered(ctx, &coreauth.Auth{ID: "a1", Index: "idx1", Provider: "gemini"})
+	c.HandleUsage(ctx, coreusage.Record{
+		Provider:    "gemini",
+		Model:       "gemini-2.5-pro",
+		AuthIndex:   "idx1",
+		RequestedAt: time.Now().Add(-2 * time.Second),
+		Detail:      coreusage.Detail{InputTokens: 10, OutputTokens: 5},
+	})
+	limited := coreauth.Result{AuthID: "a1", Provider: "gemini", Model: "gemini-2.5-pro", Error: &coreauth.Error{HTTPStatus: 429}}
+	c.OnResult(ctx, limited)
+	c.OnResultApplied(ctx, limited, &coreauth.Auth{ID: "a1", Index: "idx1", Provider: "gemini", ModelStates: map[string]*coreauth.ModelState{
+		"gemini-2.5-pro": {Unavailable: true, NextRetryAfter: time.Now().Add(time.Minute)},
+	}})
+	// With disable-cooling no retry time is scheduled, so no cooldown is counted.
+	c.OnResult(ctx, limited)
+	c.OnResultApplied(ctx, limited, &coreauth.Auth{ID: "a1", Index: "idx1", Provider: "gemini", Unavailable: true, ModelStates: map[string]*coreauth.ModelState{
+		"gemini-2.5-pro": {Unavailable: true},
+	}})
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`cliproxy_requests_total{provider="gemini",model="gemini-2.5-pro",auth_index="idx1",result="success"} 1`,
+		`cliproxy_tokens_total{provider="gemini",model="gemini-2.5-pro",type="input"} 10`,
+		`cliproxy_tokens_total{provider="gemini",model="gemini-2.5-pro",type="output"} 5`,
+		`cliproxy_request_duration_seconds_bucket{provider="gemini",model="gemini-2.5-pro",le="2.5"} 1`,
+		`cliproxy_request_duration_seconds_count{provider="gemini",model="gemini-2.5-pro"} 1`,
+		`cliproxy_upstream_errors_total{provider="gemini",model="gemini-2.5-pro",status="429"} 2`,
+		`cliproxy_auth_cooldowns_total{provider="gemini",auth_index="idx1"} 1`,
+		`cliproxy_auth_available{provider="gemini",auth_index="idx1"} 0`,
+		"# TYPE cliproxy_request_duration_seconds histogram",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("metrics output missing %q\n%s", want, out)
+		}
+	}
+
+	// Removing a credential disables it and drops its series.
+	c.OnAuthUpdated(ctx, &coreauth.Auth{ID: "a1", Index: "idx1", Provider: "gemini", Disabled: true, Status: coreauth.StatusDisabled})
+	buf.Reset()
+	if err := c.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, stale := range []string{`cliproxy_auth_available{provider="gemini",auth_index="idx1"}`, `cliproxy_auth_cooldowns_total{provider="gemini",auth_index="idx1"}`} {
+		if strings.Contains(buf.String(), stale) {
+			t.Fatalf("removed credential still reported as %s:\n%s", stale, buf.String())
+		}
+	}
+}
+
+func TestCollectorIgnoresUsageWhenDisabled(t *testing.T) {
+	SetEnabled(false)
+
+	c := NewCollector()
+	c.HandleUsage(context.Background(), coreusage.Record{Provider: "claude", Model: "claude-sonnet-4"})
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "claude-sonnet-4") {
+		t.Fatalf("expected no samples while disabled, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,239 @@
+// Package metrics exposes Prometheus-compatible runtime metrics for the CLI Proxy API server.
+// It aggregates usage records and auth lifecycle events into counters, gauges and histograms
+// rendered in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// labelSeparator joins label values into a single map key; it cannot appear in valid UTF-8 text.
+const labelSeparator = "\xff"
+
+// defaultLatencyBuckets are the upper bounds (in seconds) used for request latency histograms.
+var defaultLatencyBuckets = []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300}
+
+type metricKind string
+
+const (
+	kindCounter   metricKind = "counter"
+	kindGauge     metricKind = "gauge"
+	kindHistogram metricKind = "histogram"
+)
+
+// series stores the sample values for one metric family keyed by joined label values.
+type series struct {
+	name   string
+	help   string
+	kind   metricKind
+	labels []string
+
+	mu      sync.Mutex
+	values  map[string]float64
+	buckets []float64
+	hists   map[string]*histogramValue
+}
+
+type histogramValue struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newSeries(name, help string, kind metricKind, labels ...string) *series {
+	return &series{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		values: make(map[string]float64),
+	}
+}
+
+func newHistogram(name, help string, buckets []float64, labels ...string) *series {
+	s := newSeries(name, help, kindHistogram, labels...)
+	s.buckets = append([]float64(nil), buckets...)
+	sort.Float64s(s.buckets)
+	s.hists = make(map[string]*histogramValue)
+	return s
+}
+
+func (s *series) key(values []string) string {
+	if len(values) != len(s.labels) {
+		padded := make([]string, len(s.labels))
+		copy(padded, values)
+		values = padded
+	}
+	return strings.Join(values, labelSeparator)
+}
+
+// add increments a counter or gauge by delta.
+func (s *series) add(delta float64, values ...string) {
+	key := s.key(values)
+	s.mu.Lock()
+	s.values[key] += delta
+	s.mu.Unlock()
+}
+
+// set replaces a gauge value.
+func (s *series) set(value float64, values ...string) {
+	key := s.key(values)
+	s.mu.Lock()
+	s.values[key] = value
+	s.mu.Unlock()
+}
+
+// delete drops the sample with the given label values.
+func (s *series) delete(values ...string) {
+	key := s.key(values)
+	s.mu.Lock()
+	delete(s.values, key)
+	delete(s.hists, key)
+	s.mu.Unlock()
+}
+
+// observe records a histogram sample.
+func (s *series) observe(value float64, values ...string) {
+	key := s.key(values)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.hists[key]
+	if !ok {
+		h = &histogramValue{counts: make([]uint64, len(s.buckets))}
+		s.hists[key] = h
+	}
+	for i, bound := range s.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// reset drops every sample of the family.
+func (s *series) reset() {
+	s.mu.Lock()
+	s.values = make(map[string]float64)
+	if s.hists != nil {
+		s.hists = make(map[string]*histogramValue)
+	}
+	s.mu.Unlock()
+}
+
+// write renders the family in the Prometheus text exposition format.
+func (s *series) write(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", s.name, escapeHelp(s.help), s.name, s.kind); err != nil {
+		return err
+	}
+	if s.kind == kindHistogram {
+		for _, key := range sortedKeys(s.hists) {
+			h := s.hists[key]
+			values := splitKey(key, len(s.labels))
+			for i, bound := range s.buckets {
+				labels := formatLabels(s.labels, values, "le", formatFloat(bound))
+				if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", s.name, labels, h.counts[i]); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", s.name, formatLabels(s.labels, values, "le", "+Inf"), h.count); err != nil {
+				return err
+			}
+			plain := formatLabels(s.labels, values, "", "")
+			if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", s.name, plain, formatFloat(h.sum), s.name, plain, h.count); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, key := range sortedKeys(s.values) {
+		labels := formatLabels(s.labels, splitKey(key, len(s.labels)), "", "")
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", s.name, labels, formatFloat(s.values[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func splitKey(key string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	parts := strings.SplitN(key, labelSeparator, n)
+	for len(parts) < n {
+		parts = append(parts, "")
+	}
+	return parts
+}
+
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(values[i]))
+		b.WriteByte('"')
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(extraName)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(extraValue))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func escapeLabelValue(v string) string {
+	if !strings.ContainsAny(v, "\\\"\n") {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return strings.ReplaceAll(v, "\n", `\n`)
+}
+
+func escapeHelp(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	return strings.ReplaceAll(v, "\n", `\n`)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
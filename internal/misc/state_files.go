@@ -0,0 +1,33 @@
+package misc
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Names of the JSON state files components keep in the auth directory. They live next to the
+// credentials but are not credentials, so token stores, the watcher and the auth-file management
+// API skip them.
+const (
+	// DonationBindingsFileName holds donation account bindings.
+	DonationBindingsFileName = "bindings.json"
+	// DonationLedgerFileName holds the donation ledger.
+	DonationLedgerFileName = "donations.json"
+	// DonationSessionsFileName holds donation site login sessions.
+	DonationSessionsFileName = "donation-sessions.json"
+	// ClientKeysUsageFileName holds client key last-used records.
+	ClientKeysUsageFileName = "client-keys-usage.json"
+)
+
+var authDirStateFiles = map[string]struct{}{
+	DonationBindingsFileName: {},
+	DonationLedgerFileName:   {},
+	DonationSessionsFileName: {},
+	ClientKeysUsageFileName:  {},
+}
+
+// IsAuthDirStateFile reports whether path names one of the known auth-directory state files.
+func IsAuthDirStateFile(path string) bool {
+	_, ok := authDirStateFiles[strings.ToLower(filepath.Base(path))]
+	return ok
+}
@@ -0,0 +1,272 @@
+// Package notify delivers credential and quota events to operator webhooks. The Notifier
+// observes the core auth manager as a coreauth.Hook and turns state transitions into events.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// Event types.
+const (
+	EventAuthDisabled     = "auth_disabled"
+	EventRefreshFailed    = "refresh_failed"
+	EventQuotaExceeded    = "quota_exceeded"
+	EventModelUnavailable = "model_unavailable"
+	EventAuthRecovered    = "auth_recovered"
+)
+
+const defaultRefreshFailureThreshold = 3
+
+// Event is the JSON body POSTed to webhooks.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	Provider   string    `json:"provider,omitempty"`
+	AuthID     string    `json:"auth_id,omitempty"`
+	AuthLabel  string    `json:"auth_label,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Message    string    `json:"message,omitempty"`
+}
+
+// authState is what the notifier remembers about a credential between callbacks.
+type authState struct {
+	label           string
+	provider        string
+	disabled        bool
+	failing         bool
+	refreshFailures int
+	quotaModels     map[string]struct{}
+}
+
+// Notifier implements coreauth.Hook and coreauth.RefreshFailureHook.
+type Notifier struct {
+	mu         sync.Mutex
+	cfg        config.NotificationConfig
+	auths      map[string]*authState
+	downModels map[string]struct{}
+	sender     *sender
+}
+
+var defaultNotifier = NewNotifier()
+
+// DefaultNotifier returns the notifier attached to the core auth manager.
+func DefaultNotifier() *Notifier { return defaultNotifier }
+
+// NewNotifier constructs a notifier without webhooks.
+func NewNotifier() *Notifier {
+	return &Notifier{
+		auths:      make(map[string]*authState),
+		downModels: make(map[string]struct{}),
+		sender:     newSender(),
+	}
+}
+
+// Configure replaces the webhook configuration. It is safe to call on every config reload.
+func (n *Notifier) Configure(cfg config.NotificationConfig) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	n.cfg = cfg
+	n.cfg.Webhooks = append([]config.WebhookConfig(nil), cfg.Webhooks...)
+	n.mu.Unlock()
+}
+
+func (n *Notifier) stateFor(auth *coreauth.Auth) *authState {
+	st, ok := n.auths[auth.ID]
+	if !ok {
+		st = &authState{}
+		n.auths[auth.ID] = st
+	}
+	st.provider = auth.Provider
+	st.label = auth.Label
+	return st
+}
+
+// OnAuthRegistered implements coreauth.Hook.
+func (n *Notifier) OnAuthRegistered(_ context.Context, auth *coreauth.Auth) {
+	if n == nil || auth == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	st := n.stateFor(auth)
+	st.disabled = auth.Disabled
+}
+
+// OnAuthUpdated implements coreauth.Hook.
+func (n *Notifier) OnAuthUpdated(_ context.Context, auth *coreauth.Auth) {
+	if n == nil || auth == nil {
+		return
+	}
+	var events []Event
+	n.mu.Lock()
+	st := n.stateFor(auth)
+	disabled := auth.Disabled || auth.Status == coreauth.StatusDisabled
+	if disabled && !st.disabled {
+		ev := n.newEvent(EventAuthDisabled, auth.ID, st)
+		ev.Message = auth.StatusMessage
+		events = append(events, ev)
+	}
+	st.disabled = disabled
+	if auth.LastError == nil {
+		st.refreshFailures = 0
+	}
+	n.mu.Unlock()
+	n.dispatch(events)
+}
+
+// OnRefreshFailed implements coreauth.RefreshFailureHook.
+func (n *Notifier) OnRefreshFailed(_ context.Context, auth *coreauth.Auth, err error) {
+	if n == nil || auth == nil {
+		return
+	}
+	var events []Event
+	n.mu.Lock()
+	st := n.stateFor(auth)
+	st.refreshFailures++
+	threshold := n.cfg.RefreshFailureThreshold
+	if threshold <= 0 {
+		threshold = defaultRefreshFailureThreshold
+	}
+	if st.refreshFailures == threshold {
+		ev := n.newEvent(EventRefreshFailed, auth.ID, st)
+		ev.Message = fmt.Sprintf("refresh failed %d times in a row", st.refreshFailures)
+		if err != nil {
+			ev.Message += ": " + err.Error()
+		}
+		events = append(events, ev)
+	}
+	n.mu.Unlock()
+	n.dispatch(events)
+}
+
+// OnResult implements coreauth.Hook.
+func (n *Notifier) OnResult(_ context.Context, result coreauth.Result) {
+	if n == nil || result.AuthID == "" {
+		return
+	}
+	status := 0
+	message := ""
+	if result.Error != nil {
+		status = result.Error.HTTPStatus
+		message = result.Error.Message
+	}
+	model := strings.TrimSpace(result.Model)
+
+	var events []Event
+	n.mu.Lock()
+	st, ok := n.auths[result.AuthID]
+	if !ok {
+		st = &authState{provider: result.Provider}
+		n.auths[result.AuthID] = st
+	}
+	if result.Success {
+		if st.failing {
+			ev := n.newEvent(EventAuthRecovered, result.AuthID, st)
+			ev.Model = model
+			events = append(events, ev)
+		}
+		st.failing = false
+		st.quotaModels = nil
+		delete(n.downModels, model)
+		n.mu.Unlock()
+		n.dispatch(events)
+		return
+	}
+	if !credentialFailure(status) {
+		n.mu.Unlock()
+		return
+	}
+	st.failing = true
+	if status == 429 && model != "" {
+		if _, seen := st.quotaModels[model]; !seen {
+			if st.quotaModels == nil {
+				st.quotaModels = make(map[string]struct{})
+			}
+			st.quotaModels[model] = struct{}{}
+			ev := n.newEvent(EventQuotaExceeded, result.AuthID, st)
+			ev.Model, ev.StatusCode, ev.Message = model, status, message
+			events = append(events, ev)
+		}
+	}
+	if model != "" {
+		if _, down := n.downModels[model]; !down && registry.GetGlobalRegistry().GetModelCount(model) == 0 {
+			n.downModels[model] = struct{}{}
+			events = append(events, Event{
+				ID:         uuid.NewString(),
+				Type:       EventModelUnavailable,
+				Time:       time.Now().UTC(),
+				Provider:   result.Provider,
+				Model:      model,
+				StatusCode: status,
+				Message:    "every credential serving the model is cooling down",
+			})
+		}
+	}
+	n.mu.Unlock()
+	n.dispatch(events)
+}
+
+// credentialFailure reports whether an upstream status says something about the credential
+// rather than the request.
+func credentialFailure(status int) bool {
+	switch status {
+	case 401, 402, 403, 429:
+		return true
+	default:
+		return false
+	}
+}
+
+func (n *Notifier) newEvent(typ, authID string, st *authState) Event {
+	return Event{
+		ID:        uuid.NewString(),
+		Type:      typ,
+		Time:      time.Now().UTC(),
+		Provider:  st.provider,
+		AuthID:    authID,
+		AuthLabel: st.label,
+	}
+}
+
+// dispatch queues events for every webhook subscribed to their type.
+func (n *Notifier) dispatch(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	n.mu.Lock()
+	hooks := n.cfg.Webhooks
+	n.mu.Unlock()
+	for _, ev := range events {
+		for _, hook := range hooks {
+			if strings.TrimSpace(hook.URL) == "" || !subscribed(hook, ev.Type) {
+				continue
+			}
+			n.sender.enqueue(hook, ev)
+		}
+	}
+}
+
+func subscribed(hook config.WebhookConfig, eventType string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, typ := range hook.Events {
+		if strings.EqualFold(strings.TrimSpace(typ), eventType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestNotifierDeliversSignedFilteredEvents(t *testing.T) {
+	var attempts atomic.Int32
+	received := make(chan Event, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.Header.Get(HeaderSignature), Sign("s3cret", r.Header.Get(HeaderTimestamp), body); got != want {
+			t.Errorf("signature = %q, want %q", got, want)
+		}
+		// The first delivery fails so the sender has to retry.
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var ev Event
+		if err := json.Unmarshal(body, &ev); err != nil {
+			t.Errorf("decode event: %v", err)
+		}
+		if r.Header.Get(HeaderEvent) != ev.Type {
+			t.Errorf("event header = %q, body type %q", r.Header.Get(HeaderEvent), ev.Type)
+		}
+		received <- ev
+	}))
+	defer srv.Close()
+
+	n := NewNotifier()
+	n.sender.sleep = func(time.Duration) {}
+	n.Configure(config.NotificationConfig{
+		RefreshFailureThreshold: 2,
+		Webhooks: []config.WebhookConfig{{
+			URL:    srv.URL,
+			Secret: "s3cret",
+			Events: []string{EventRefreshFailed},
+		}},
+	})
+
+	ctx := context.Background()
+	auth := &coreauth.Auth{ID: "a1", Provider: "claude", Label: "team"}
+	n.OnAuthRegistered(ctx, auth)
+	// Not subscribed: the webhook filter drops it.
+	n.OnAuthUpdated(ctx, &coreauth.Auth{ID: "a1", Provider: "claude", Label: "team", Disabled: true})
+	n.OnRefreshFailed(ctx, auth, errors.New("invalid_grant"))
+	n.OnRefreshFailed(ctx, auth, errors.New("invalid_grant"))
+	// Past the threshold: no repeat notification.
+	n.OnRefreshFailed(ctx, auth, errors.New("invalid_grant"))
+
+	select {
+	case ev := <-received:
+		if ev.Type != EventRefreshFailed || ev.AuthID != "a1" || ev.AuthLabel != "team" {
+			t.Fatalf("event = %+v, want refresh_failed for a1", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook delivery")
+	}
+	select {
+	case ev := <-received:
+		t.Fatalf("unexpected extra event %+v", ev)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("attempts = %d, want 2", got)
+	}
+}
@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	queueSize             = 256
+	senderWorkers         = 2
+	defaultWebhookRetries = 3
+	defaultWebhookTimeout = 10 * time.Second
+	retryBackoffBase      = time.Second
+	retryBackoffMax       = time.Minute
+
+	// Webhook request headers.
+	HeaderEvent     = "X-CLIProxy-Event"
+	HeaderDelivery  = "X-CLIProxy-Delivery"
+	HeaderTimestamp = "X-CLIProxy-Timestamp"
+	HeaderSignature = "X-CLIProxy-Signature"
+)
+
+type delivery struct {
+	hook  config.WebhookConfig
+	event Event
+}
+
+// sender delivers events from a bounded queue with a small worker pool.
+type sender struct {
+	once   sync.Once
+	queue  chan delivery
+	client *http.Client
+	sleep  func(time.Duration)
+}
+
+func newSender() *sender {
+	return &sender{
+		queue:  make(chan delivery, queueSize),
+		client: &http.Client{},
+		sleep:  time.Sleep,
+	}
+}
+
+func (s *sender) enqueue(hook config.WebhookConfig, ev Event) {
+	s.once.Do(func() {
+		for i := 0; i < senderWorkers; i++ {
+			go s.run()
+		}
+	})
+	select {
+	case s.queue <- delivery{hook: hook, event: ev}:
+	default:
+		log.Warnf("webhook queue full, dropping %s event for %s", ev.Type, hook.URL)
+	}
+}
+
+func (s *sender) run() {
+	for d := range s.queue {
+		if err := s.deliver(d); err != nil {
+			log.Warnf("webhook delivery of %s event to %s failed: %v", d.event.Type, d.hook.URL, err)
+		}
+	}
+}
+
+// deliver POSTs the event, retrying network errors, 429 and 5xx responses with exponential backoff.
+func (s *sender) deliver(d delivery) error {
+	body, err := json.Marshal(d.event)
+	if err != nil {
+		return err
+	}
+	retries := d.hook.MaxRetries
+	if retries == 0 {
+		retries = defaultWebhookRetries
+	} else if retries < 0 {
+		retries = 0
+	}
+	timeout := time.Duration(d.hook.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	backoff := retryBackoffBase
+	for attempt := 0; ; attempt++ {
+		retryable, errPost := s.post(d, body, timeout)
+		if errPost == nil {
+			return nil
+		}
+		if !retryable || attempt >= retries {
+			return errPost
+		}
+		s.sleep(backoff)
+		backoff = min(backoff*2, retryBackoffMax)
+	}
+}
+
+func (s *sender) post(d delivery, body []byte, timeout time.Duration) (retryable bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.event.Type)
+	req.Header.Set(HeaderDelivery, d.event.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if d.hook.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(d.hook.Secret, timestamp, body))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+	return retryable, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+}
+
+// Sign returns the signature header value for a delivery: "sha256=" followed by the hex
+// HMAC-SHA256 of timestamp + "." + body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
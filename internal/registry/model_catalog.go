@@ -0,0 +1,239 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModelCatalog is an operator-maintained file that adds models and overrides the metadata
+// compiled into model_definitions.go. YAML and JSON share the same schema.
+type ModelCatalog struct {
+	// Models lists the added or overridden model definitions.
+	Models []CatalogModel `yaml:"models" json:"models"`
+}
+
+// CatalogModel describes one catalog entry. Zero-valued fields keep the built-in value, so an
+// override only has to name the fields it changes.
+type CatalogModel struct {
+	// Provider scopes the entry to one provider key (e.g. "claude", "gemini-cli", "codex" or an
+	// openai-compatibility name). An empty provider applies the override wherever the model is
+	// listed but cannot add new models.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	// ID is the model identifier the entry applies to.
+	ID string `yaml:"id" json:"id"`
+
+	OwnedBy                    string   `yaml:"owned-by,omitempty" json:"owned-by,omitempty"`
+	DisplayName                string   `yaml:"display-name,omitempty" json:"display-name,omitempty"`
+	Name                       string   `yaml:"name,omitempty" json:"name,omitempty"`
+	Version                    string   `yaml:"version,omitempty" json:"version,omitempty"`
+	Description                string   `yaml:"description,omitempty" json:"description,omitempty"`
+	InputTokenLimit            int      `yaml:"input-token-limit,omitempty" json:"input-token-limit,omitempty"`
+	OutputTokenLimit           int      `yaml:"output-token-limit,omitempty" json:"output-token-limit,omitempty"`
+	ContextLength              int      `yaml:"context-length,omitempty" json:"context-length,omitempty"`
+	MaxCompletionTokens        int      `yaml:"max-completion-tokens,omitempty" json:"max-completion-tokens,omitempty"`
+	SupportedGenerationMethods []string `yaml:"supported-generation-methods,omitempty" json:"supported-generation-methods,omitempty"`
+	SupportedParameters        []string `yaml:"supported-parameters,omitempty" json:"supported-parameters,omitempty"`
+
+	// Thinking overrides the reasoning budget range. Unset fields keep the built-in range.
+	Thinking *CatalogThinking `yaml:"thinking,omitempty" json:"thinking,omitempty"`
+}
+
+// CatalogThinking overrides fields of ThinkingSupport. Pointers distinguish "unset" from an
+// explicit zero or false.
+type CatalogThinking struct {
+	// Disabled removes thinking support from the model.
+	Disabled       bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Min            *int     `yaml:"min,omitempty" json:"min,omitempty"`
+	Max            *int     `yaml:"max,omitempty" json:"max,omitempty"`
+	ZeroAllowed    *bool    `yaml:"zero-allowed,omitempty" json:"zero-allowed,omitempty"`
+	DynamicAllowed *bool    `yaml:"dynamic-allowed,omitempty" json:"dynamic-allowed,omitempty"`
+	Levels         []string `yaml:"levels,omitempty" json:"levels,omitempty"`
+}
+
+var (
+	modelCatalogMu sync.RWMutex
+	modelCatalog   *ModelCatalog
+)
+
+// ParseModelCatalog decodes and validates catalog file content. JSON is accepted as YAML.
+func ParseModelCatalog(data []byte) (*ModelCatalog, error) {
+	var catalog ModelCatalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("parse model catalog: %w", err)
+	}
+	for i := range catalog.Models {
+		entry := &catalog.Models[i]
+		entry.ID = strings.TrimSpace(entry.ID)
+		entry.Provider = strings.ToLower(strings.TrimSpace(entry.Provider))
+		if entry.ID == "" {
+			return nil, fmt.Errorf("parse model catalog: models[%d] has no id", i)
+		}
+		if t := entry.Thinking; t != nil && t.Min != nil && t.Max != nil && *t.Min > *t.Max {
+			return nil, fmt.Errorf("parse model catalog: model %s has thinking min above max", entry.ID)
+		}
+	}
+	return &catalog, nil
+}
+
+// SetModelCatalog installs catalog as the active overrides. Nil clears them.
+func SetModelCatalog(catalog *ModelCatalog) {
+	modelCatalogMu.Lock()
+	modelCatalog = catalog
+	modelCatalogMu.Unlock()
+}
+
+func currentModelCatalog() *ModelCatalog {
+	modelCatalogMu.RLock()
+	defer modelCatalogMu.RUnlock()
+	return modelCatalog
+}
+
+// ApplyModelCatalog returns models with the active catalog applied for provider: matching
+// entries override fields of copies of the listed models and provider-scoped entries that are
+// not listed yet are appended. The input slice is returned unchanged when no catalog is set.
+func ApplyModelCatalog(provider string, models []*ModelInfo) []*ModelInfo {
+	catalog := currentModelCatalog()
+	if catalog == nil || len(catalog.Models) == 0 {
+		return models
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	out := make([]*ModelInfo, 0, len(models))
+	listed := make(map[string]struct{}, len(models))
+	for _, model := range models {
+		if model == nil {
+			continue
+		}
+		listed[model.ID] = struct{}{}
+		out = append(out, catalog.apply(provider, model))
+	}
+	for i := range catalog.Models {
+		entry := &catalog.Models[i]
+		if entry.Provider == "" || entry.Provider != provider {
+			continue
+		}
+		if _, ok := listed[entry.ID]; ok {
+			continue
+		}
+		listed[entry.ID] = struct{}{}
+		out = append(out, catalog.apply(provider, entry.newModelInfo()))
+	}
+	return out
+}
+
+// lookupCatalogModel returns the info of a model the catalog adds for any provider.
+func lookupCatalogModel(modelID string) *ModelInfo {
+	catalog := currentModelCatalog()
+	if catalog == nil {
+		return nil
+	}
+	for i := range catalog.Models {
+		entry := &catalog.Models[i]
+		if entry.Provider != "" && entry.ID == modelID {
+			return catalog.apply(entry.Provider, entry.newModelInfo())
+		}
+	}
+	return nil
+}
+
+// apply returns a copy of model with every entry matching provider and the model ID applied.
+// Global entries are applied first so provider-scoped entries win.
+func (c *ModelCatalog) apply(provider string, model *ModelInfo) *ModelInfo {
+	if c == nil {
+		return model
+	}
+	var out *ModelInfo
+	for _, scoped := range []bool{false, true} {
+		for i := range c.Models {
+			entry := &c.Models[i]
+			if entry.ID != model.ID || (entry.Provider != "") != scoped || (scoped && entry.Provider != provider) {
+				continue
+			}
+			if out == nil {
+				out = cloneModelInfo(model)
+			}
+			entry.override(out)
+		}
+	}
+	if out == nil {
+		return model
+	}
+	return out
+}
+
+func (e *CatalogModel) newModelInfo() *ModelInfo {
+	return &ModelInfo{
+		ID:          e.ID,
+		Object:      "model",
+		Created:     time.Now().Unix(),
+		OwnedBy:     e.Provider,
+		Type:        e.Provider,
+		DisplayName: e.ID,
+	}
+}
+
+func (e *CatalogModel) override(info *ModelInfo) {
+	if e.OwnedBy != "" {
+		info.OwnedBy = e.OwnedBy
+	}
+	if e.DisplayName != "" {
+		info.DisplayName = e.DisplayName
+	}
+	if e.Name != "" {
+		info.Name = e.Name
+	}
+	if e.Version != "" {
+		info.Version = e.Version
+	}
+	if e.Description != "" {
+		info.Description = e.Description
+	}
+	if e.InputTokenLimit > 0 {
+		info.InputTokenLimit = e.InputTokenLimit
+	}
+	if e.OutputTokenLimit > 0 {
+		info.OutputTokenLimit = e.OutputTokenLimit
+	}
+	if e.ContextLength > 0 {
+		info.ContextLength = e.ContextLength
+	}
+	if e.MaxCompletionTokens > 0 {
+		info.MaxCompletionTokens = e.MaxCompletionTokens
+	}
+	if len(e.SupportedGenerationMethods) > 0 {
+		info.SupportedGenerationMethods = append([]string(nil), e.SupportedGenerationMethods...)
+	}
+	if len(e.SupportedParameters) > 0 {
+		info.SupportedParameters = append([]string(nil), e.SupportedParameters...)
+	}
+	if e.Thinking == nil {
+		return
+	}
+	if e.Thinking.Disabled {
+		info.Thinking = nil
+		return
+	}
+	thinking := ThinkingSupport{}
+	if info.Thinking != nil {
+		thinking = *info.Thinking
+	}
+	if e.Thinking.Min != nil {
+		thinking.Min = *e.Thinking.Min
+	}
+	if e.Thinking.Max != nil {
+		thinking.Max = *e.Thinking.Max
+	}
+	if e.Thinking.ZeroAllowed != nil {
+		thinking.ZeroAllowed = *e.Thinking.ZeroAllowed
+	}
+	if e.Thinking.DynamicAllowed != nil {
+		thinking.DynamicAllowed = *e.Thinking.DynamicAllowed
+	}
+	if len(e.Thinking.Levels) > 0 {
+		thinking.Levels = append([]string(nil), e.Thinking.Levels...)
+	}
+	info.Thinking = &thinking
+}
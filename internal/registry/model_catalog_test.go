@@ -0,0 +1,42 @@
+package registry
+
+import "testing"
+
+func TestModelCatalogOverridesAndAddsModels(t *testing.T) {
+	catalog, err := ParseModelCatalog([]byte(`
+models:
+  - id: claude-sonnet-4-5-20250929
+    max-completion-tokens: 32000
+    thinking: { max: 50000 }
+  - provider: claude
+    id: claude-next
+    context-length: 500000
+    thinking: { min: 1024, max: 64000, zero-allowed: true }
+`))
+	if err != nil {
+		t.Fatalf("ParseModelCatalog() error = %v", err)
+	}
+	SetModelCatalog(catalog)
+	t.Cleanup(func() { SetModelCatalog(nil) })
+
+	builtIn := LookupStaticModelInfo("claude-sonnet-4-5-20250929")
+	if builtIn == nil || builtIn.Thinking == nil {
+		t.Fatalf("built-in model not found or without thinking: %+v", builtIn)
+	}
+	if builtIn.MaxCompletionTokens != 32000 || builtIn.Thinking.Max != 50000 || builtIn.Thinking.Min != 1024 {
+		t.Fatalf("override = %d tokens, thinking %+v; want 32000 and max 50000 with the built-in min", builtIn.MaxCompletionTokens, builtIn.Thinking)
+	}
+
+	added := LookupStaticModelInfo("claude-next")
+	if added == nil || added.ContextLength != 500000 || added.Thinking == nil || !added.Thinking.ZeroAllowed {
+		t.Fatalf("catalog model = %+v, want the catalog definition", added)
+	}
+
+	models := ApplyModelCatalog("claude", GetClaudeModels())
+	if last := models[len(models)-1]; last.ID != "claude-next" {
+		t.Fatalf("last model = %s, want the catalog-added claude-next", last.ID)
+	}
+	if others := ApplyModelCatalog("gemini", nil); len(others) != 0 {
+		t.Fatalf("gemini models = %d, want provider-scoped entries to stay on claude", len(others))
+	}
+}
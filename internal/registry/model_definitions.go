@@ -192,6 +192,32 @@ func GetGeminiModels() []*ModelInfo {
 			SupportedGenerationMethods: []string{"generateContent", "countTokens", "createCachedContent", "batchGenerateContent"},
 			Thinking:                   &ThinkingSupport{Min: 128, Max: 32768, ZeroAllowed: false, DynamicAllowed: true, Levels: []string{"low", "high"}},
 		},
+		{
+			ID:                         "gemini-embedding-001",
+			Object:                     "model",
+			Created:                    1752710400,
+			OwnedBy:                    "google",
+			Type:                       "gemini",
+			Name:                       "models/gemini-embedding-001",
+			Version:                    "001",
+			DisplayName:                "Gemini Embedding 001",
+			Description:                "Text embedding model with configurable output dimensionality.",
+			InputTokenLimit:            2048,
+			SupportedGenerationMethods: []string{"embedContent", "batchEmbedContents"},
+		},
+		{
+			ID:                         "text-embedding-004",
+			Object:                     "model",
+			Created:                    1714003200,
+			OwnedBy:                    "google",
+			Type:                       "gemini",
+			Name:                       "models/text-embedding-004",
+			Version:                    "001",
+			DisplayName:                "Text Embedding 004",
+			Description:                "Text embedding model with 768-dimensional output.",
+			InputTokenLimit:            2048,
+			SupportedGenerationMethods: []string{"embedContent", "batchEmbedContents"},
+		},
 	}
 }
 
@@ -287,6 +313,32 @@ func GetGeminiVertexModels() []*ModelInfo {
 			SupportedGenerationMethods: []string{"generateContent", "countTokens", "createCachedContent", "batchGenerateContent"},
 			Thinking:                   &ThinkingSupport{Min: 128, Max: 32768, ZeroAllowed: false, DynamicAllowed: true, Levels: []string{"low", "high"}},
 		},
+		{
+			ID:                         "gemini-embedding-001",
+			Object:                     "model",
+			Created:                    1752710400,
+			OwnedBy:                    "google",
+			Type:                       "gemini",
+			Name:                       "models/gemini-embedding-001",
+			Version:                    "001",
+			DisplayName:                "Gemini Embedding 001",
+			Description:                "Text embedding model with configurable output dimensionality.",
+			InputTokenLimit:            2048,
+			SupportedGenerationMethods: []string{"embedContent", "batchEmbedContents"},
+		},
+		{
+			ID:                         "text-embedding-005",
+			Object:                     "model",
+			Created:                    1731974400,
+			OwnedBy:                    "google",
+			Type:                       "gemini",
+			Name:                       "models/text-embedding-005",
+			Version:                    "001",
+			DisplayName:                "Text Embedding 005",
+			Description:                "Text embedding model specialized for English and code.",
+			InputTokenLimit:            2048,
+			SupportedGenerationMethods: []string{"embedContent", "batchEmbedContents"},
+		},
 	}
 }
 
@@ -782,28 +834,34 @@ func GetAntigravityModelConfig() map[string]*AntigravityModelConfig {
 	}
 }
 
-// LookupStaticModelInfo searches all static model definitions for a model by ID.
-// Returns nil if no matching model is found.
+// LookupStaticModelInfo searches all static model definitions for a model by ID, with the
+// operator model catalog applied. Returns nil if no matching model is found.
 func LookupStaticModelInfo(modelID string) *ModelInfo {
 	if modelID == "" {
 		return nil
 	}
-	allModels := [][]*ModelInfo{
-		GetClaudeModels(),
-		GetGeminiModels(),
-		GetGeminiVertexModels(),
-		GetGeminiCLIModels(),
-		GetAIStudioModels(),
-		GetOpenAIModels(),
-		GetQwenModels(),
-		GetIFlowModels(),
+	allModels := []struct {
+		provider string
+		models   []*ModelInfo
+	}{
+		{"claude", GetClaudeModels()},
+		{"gemini", GetGeminiModels()},
+		{"vertex", GetGeminiVertexModels()},
+		{"gemini-cli", GetGeminiCLIModels()},
+		{"aistudio", GetAIStudioModels()},
+		{"codex", GetOpenAIModels()},
+		{"qwen", GetQwenModels()},
+		{"iflow", GetIFlowModels()},
 	}
-	for _, models := range allModels {
-		for _, m := range models {
+	for _, group := range allModels {
+		for _, m := range group.models {
 			if m != nil && m.ID == modelID {
+				if catalog := currentModelCatalog(); catalog != nil {
+					return catalog.apply(group.provider, m)
+				}
 				return m
 			}
 		}
 	}
-	return nil
+	return lookupCatalogModel(modelID)
 }
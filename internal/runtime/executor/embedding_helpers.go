@@ -0,0 +1,320 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// geminiEmbedContentAction embeds a single content in the Gemini API schema.
+	geminiEmbedContentAction = "embedContent"
+	// geminiBatchEmbedContentsAction embeds several contents in the Gemini API schema.
+	geminiBatchEmbedContentsAction = "batchEmbedContents"
+)
+
+// embeddingRequest is the provider-neutral form of an embedding request.
+type embeddingRequest struct {
+	Inputs         []string
+	Dimensions     int64
+	TaskType       string
+	Title          string
+	EncodingFormat string
+}
+
+// embeddingResult holds the vectors returned by an upstream provider in input order.
+type embeddingResult struct {
+	Vectors      [][]float64
+	PromptTokens int64
+}
+
+// embeddingAction returns the Gemini embed action carried in request metadata, if any.
+func embeddingAction(req cliproxyexecutor.Request) string {
+	if req.Metadata == nil {
+		return ""
+	}
+	action, _ := req.Metadata["action"].(string)
+	return action
+}
+
+// isGeminiSource reports whether the inbound request uses the Gemini API schema.
+func isGeminiSource(from sdktranslator.Format) bool {
+	return from == sdktranslator.FromString("gemini")
+}
+
+// parseEmbeddingRequest normalizes an OpenAI or Gemini embedding request.
+func parseEmbeddingRequest(from sdktranslator.Format, action string, payload []byte) (embeddingRequest, error) {
+	if !gjson.ValidBytes(payload) {
+		return embeddingRequest{}, statusErr{code: http.StatusBadRequest, msg: "invalid embedding request body"}
+	}
+	root := gjson.ParseBytes(payload)
+	var out embeddingRequest
+
+	if isGeminiSource(from) {
+		items := []gjson.Result{root}
+		if action == geminiBatchEmbedContentsAction {
+			items = root.Get("requests").Array()
+		}
+		for _, item := range items {
+			var parts []string
+			for _, part := range item.Get("content.parts").Array() {
+				if text := part.Get("text"); text.Exists() {
+					parts = append(parts, text.String())
+				}
+			}
+			out.Inputs = append(out.Inputs, strings.Join(parts, "\n"))
+			if out.TaskType == "" {
+				out.TaskType = item.Get("taskType").String()
+			}
+			if out.Title == "" {
+				out.Title = item.Get("title").String()
+			}
+			if out.Dimensions == 0 {
+				out.Dimensions = item.Get("outputDimensionality").Int()
+			}
+		}
+	} else {
+		input := root.Get("input")
+		switch {
+		case input.Type == gjson.String:
+			out.Inputs = []string{input.String()}
+		case input.IsArray():
+			for _, item := range input.Array() {
+				if item.Type != gjson.String {
+					return embeddingRequest{}, statusErr{code: http.StatusBadRequest, msg: "token array inputs are not supported for this model; send text inputs"}
+				}
+				out.Inputs = append(out.Inputs, item.String())
+			}
+		}
+		out.Dimensions = root.Get("dimensions").Int()
+		out.EncodingFormat = root.Get("encoding_format").String()
+	}
+
+	if len(out.Inputs) == 0 {
+		return embeddingRequest{}, statusErr{code: http.StatusBadRequest, msg: "embedding request has no input"}
+	}
+	return out, nil
+}
+
+// renderEmbeddingResponse encodes the result in the inbound request schema.
+func renderEmbeddingResponse(from sdktranslator.Format, action, model string, req embeddingRequest, result embeddingResult) ([]byte, error) {
+	if isGeminiSource(from) {
+		if action == geminiEmbedContentAction {
+			var values []float64
+			if len(result.Vectors) > 0 {
+				values = result.Vectors[0]
+			}
+			return json.Marshal(map[string]any{"embedding": map[string]any{"values": values}})
+		}
+		embeddings := make([]map[string]any, 0, len(result.Vectors))
+		for _, vector := range result.Vectors {
+			embeddings = append(embeddings, map[string]any{"values": vector})
+		}
+		return json.Marshal(map[string]any{"embeddings": embeddings})
+	}
+
+	data := make([]map[string]any, 0, len(result.Vectors))
+	for i, vector := range result.Vectors {
+		var embedding any = vector
+		if strings.EqualFold(req.EncodingFormat, "base64") {
+			embedding = encodeEmbeddingBase64(vector)
+		}
+		data = append(data, map[string]any{"object": "embedding", "index": i, "embedding": embedding})
+	}
+	return json.Marshal(map[string]any{
+		"object": "list",
+		"data":   data,
+		"model":  model,
+		"usage":  map[string]any{"prompt_tokens": result.PromptTokens, "total_tokens": result.PromptTokens},
+	})
+}
+
+// encodeEmbeddingBase64 packs the vector as little-endian float32 values, matching OpenAI's base64 encoding.
+func encodeEmbeddingBase64(vector []float64) string {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(v)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// geminiBatchEmbedBody builds a Gemini batchEmbedContents payload.
+func geminiBatchEmbedBody(model string, req embeddingRequest) ([]byte, error) {
+	requests := make([]map[string]any, 0, len(req.Inputs))
+	for _, input := range req.Inputs {
+		entry := map[string]any{
+			"model":   "models/" + model,
+			"content": map[string]any{"parts": []map[string]any{{"text": input}}},
+		}
+		if req.TaskType != "" {
+			entry["taskType"] = req.TaskType
+		}
+		if req.Title != "" {
+			entry["title"] = req.Title
+		}
+		if req.Dimensions > 0 {
+			entry["outputDimensionality"] = req.Dimensions
+		}
+		requests = append(requests, entry)
+	}
+	return json.Marshal(map[string]any{"requests": requests})
+}
+
+// parseGeminiEmbeddings reads embedContent or batchEmbedContents responses.
+func parseGeminiEmbeddings(data []byte) embeddingResult {
+	var result embeddingResult
+	root := gjson.ParseBytes(data)
+	if single := root.Get("embedding.values"); single.Exists() {
+		result.Vectors = append(result.Vectors, floatValues(single))
+		return result
+	}
+	for _, item := range root.Get("embeddings").Array() {
+		result.Vectors = append(result.Vectors, floatValues(item.Get("values")))
+	}
+	return result
+}
+
+// vertexPredictEmbedBody builds a Vertex AI text embedding predict payload.
+func vertexPredictEmbedBody(req embeddingRequest) ([]byte, error) {
+	instances := make([]map[string]any, 0, len(req.Inputs))
+	for _, input := range req.Inputs {
+		instance := map[string]any{"content": input}
+		if req.TaskType != "" {
+			instance["task_type"] = req.TaskType
+		}
+		if req.Title != "" {
+			instance["title"] = req.Title
+		}
+		instances = append(instances, instance)
+	}
+	body := map[string]any{"instances": instances}
+	if req.Dimensions > 0 {
+		body["parameters"] = map[string]any{"outputDimensionality": req.Dimensions}
+	}
+	return json.Marshal(body)
+}
+
+// parseVertexEmbeddings reads Vertex AI predict responses for embedding models.
+func parseVertexEmbeddings(data []byte) embeddingResult {
+	var result embeddingResult
+	for _, prediction := range gjson.GetBytes(data, "predictions").Array() {
+		result.Vectors = append(result.Vectors, floatValues(prediction.Get("embeddings.values")))
+		result.PromptTokens += prediction.Get("embeddings.statistics.token_count").Int()
+	}
+	return result
+}
+
+// openAIEmbeddingBody builds an OpenAI embeddings payload.
+func openAIEmbeddingBody(model string, req embeddingRequest) ([]byte, error) {
+	body := map[string]any{"model": model, "input": req.Inputs}
+	if req.Dimensions > 0 {
+		body["dimensions"] = req.Dimensions
+	}
+	return json.Marshal(body)
+}
+
+// parseOpenAIEmbeddings reads OpenAI embeddings responses returned with float encoding. Items
+// without an index keep their position; an out-of-range or repeated index is an upstream error.
+func parseOpenAIEmbeddings(data []byte) (embeddingResult, error) {
+	root := gjson.ParseBytes(data)
+	items := root.Get("data").Array()
+	result := embeddingResult{
+		Vectors:      make([][]float64, len(items)),
+		PromptTokens: root.Get("usage.prompt_tokens").Int(),
+	}
+	for i, item := range items {
+		idx := i
+		if index := item.Get("index"); index.Exists() {
+			idx = int(index.Int())
+		}
+		if idx < 0 || idx >= len(items) || result.Vectors[idx] != nil {
+			return embeddingResult{}, statusErr{code: http.StatusBadGateway, msg: fmt.Sprintf("upstream embedding response has invalid index %d", idx)}
+		}
+		result.Vectors[idx] = floatValues(item.Get("embedding"))
+	}
+	return result, nil
+}
+
+func floatValues(node gjson.Result) []float64 {
+	items := node.Array()
+	values := make([]float64, len(items))
+	for i, item := range items {
+		values[i] = item.Float()
+	}
+	return values
+}
+
+// postEmbeddingRequest sends an embedding payload upstream with request logging and returns the response body.
+func postEmbeddingRequest(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, provider, url string, body []byte, applyHeaders func(*http.Request)) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if applyHeaders != nil {
+		applyHeaders(httpReq)
+	}
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	recordAPIRequest(ctx, cfg, upstreamRequestLog{
+		URL:       url,
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      body,
+		Provider:  provider,
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+
+	httpClient := newProxyAwareHTTPClient(ctx, cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		recordAPIResponseError(ctx, cfg, err)
+		return nil, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("%s executor: close embedding response body error: %v", provider, errClose)
+		}
+	}()
+	recordAPIResponseMetadata(ctx, cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		recordAPIResponseError(ctx, cfg, err)
+		return nil, err
+	}
+	appendAPIResponseChunk(ctx, cfg, data)
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		log.Debugf("request error, error status: %d, error body: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), data))
+		return nil, statusErr{code: httpResp.StatusCode, msg: string(data)}
+	}
+	return data, nil
+}
+
+// embeddingResponse wraps an encoded embedding payload, converting encoding errors into executor errors.
+func embeddingResponse(payload []byte, err error) (cliproxyexecutor.Response, error) {
+	if err != nil {
+		return cliproxyexecutor.Response{}, fmt.Errorf("encode embedding response: %w", err)
+	}
+	return cliproxyexecutor.Response{Payload: payload}, nil
+}
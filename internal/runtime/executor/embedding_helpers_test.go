@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestEmbeddingOpenAIToGeminiRoundTrip(t *testing.T) {
+	openai := sdktranslator.FromString("openai")
+	req, err := parseEmbeddingRequest(openai, "", []byte(`{"model":"m","input":["a","b"],"dimensions":8}`))
+	if err != nil {
+		t.Fatalf("parseEmbeddingRequest() error = %v", err)
+	}
+	body, err := geminiBatchEmbedBody("gemini-embedding-001", req)
+	if err != nil {
+		t.Fatalf("geminiBatchEmbedBody() error = %v", err)
+	}
+	if got := gjson.GetBytes(body, "requests.#").Int(); got != 2 {
+		t.Fatalf("requests = %d, want 2", got)
+	}
+	if got := gjson.GetBytes(body, "requests.1.content.parts.0.text").String(); got != "b" {
+		t.Fatalf("second input = %q, want %q", got, "b")
+	}
+	if got := gjson.GetBytes(body, "requests.0.outputDimensionality").Int(); got != 8 {
+		t.Fatalf("outputDimensionality = %d, want 8", got)
+	}
+
+	result := parseGeminiEmbeddings([]byte(`{"embeddings":[{"values":[0.5,1]},{"values":[2]}]}`))
+	out, err := renderEmbeddingResponse(openai, "", "m", req, result)
+	if err != nil {
+		t.Fatalf("renderEmbeddingResponse() error = %v", err)
+	}
+	if got := gjson.GetBytes(out, "data.1.index").Int(); got != 1 {
+		t.Fatalf("data[1].index = %d, want 1", got)
+	}
+	if got := gjson.GetBytes(out, "data.0.embedding.1").Float(); got != 1 {
+		t.Fatalf("data[0].embedding[1] = %v, want 1", got)
+	}
+}
+
+func TestEmbeddingGeminiFromOpenAIResponse(t *testing.T) {
+	gemini := sdktranslator.FromString("gemini")
+	req, err := parseEmbeddingRequest(gemini, geminiEmbedContentAction, []byte(`{"content":{"parts":[{"text":"hi"}]},"taskType":"RETRIEVAL_QUERY"}`))
+	if err != nil {
+		t.Fatalf("parseEmbeddingRequest() error = %v", err)
+	}
+	if req.TaskType != "RETRIEVAL_QUERY" || len(req.Inputs) != 1 || req.Inputs[0] != "hi" {
+		t.Fatalf("parsed request = %+v", req)
+	}
+	result, err := parseOpenAIEmbeddings([]byte(`{"data":[{"index":0,"embedding":[0.25,0.75]}],"usage":{"prompt_tokens":3}}`))
+	if err != nil {
+		t.Fatalf("parseOpenAIEmbeddings() error = %v", err)
+	}
+	if result.PromptTokens != 3 {
+		t.Fatalf("prompt tokens = %d, want 3", result.PromptTokens)
+	}
+	out, err := renderEmbeddingResponse(gemini, geminiEmbedContentAction, "m", req, result)
+	if err != nil {
+		t.Fatalf("renderEmbeddingResponse() error = %v", err)
+	}
+	if got := gjson.GetBytes(out, "embedding.values.1").Float(); got != 0.75 {
+		t.Fatalf("embedding.values[1] = %v, want 0.75", got)
+	}
+}
+
+func TestEmbeddingRejectsTokenArrays(t *testing.T) {
+	_, err := parseEmbeddingRequest(sdktranslator.FromString("openai"), "", []byte(`{"input":[[1,2,3]]}`))
+	if err == nil {
+		t.Fatalf("parseEmbeddingRequest() error = nil, want token array rejection")
+	}
+}
+
+func TestParseOpenAIEmbeddingsRejectsInvalidIndices(t *testing.T) {
+	for name, body := range map[string]string{
+		"negative":     `{"data":[{"index":-1,"embedding":[1]}]}`,
+		"out of range": `{"data":[{"index":0,"embedding":[1]},{"index":2,"embedding":[2]}]}`,
+		"duplicate":    `{"data":[{"index":0,"embedding":[1]},{"index":0,"embedding":[2]}]}`,
+	} {
+		_, err := parseOpenAIEmbeddings([]byte(body))
+		var status statusErr
+		if !errors.As(err, &status) || status.StatusCode() != http.StatusBadGateway {
+			t.Fatalf("%s: parseOpenAIEmbeddings() error = %v, want a 502", name, err)
+		}
+	}
+	result, err := parseOpenAIEmbeddings([]byte(`{"data":[{"index":1,"embedding":[2]},{"index":0,"embedding":[1]}]}`))
+	if err != nil {
+		t.Fatalf("parseOpenAIEmbeddings() error = %v", err)
+	}
+	if len(result.Vectors) != 2 || result.Vectors[0][0] != 1 || result.Vectors[1][0] != 2 {
+		t.Fatalf("vectors = %v, want upstream order restored", result.Vectors)
+	}
+}
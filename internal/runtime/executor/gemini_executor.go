@@ -85,7 +85,10 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, r
 	// Official Gemini API via API key or OAuth bearer
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("gemini")
-	body := sdktranslator.TranslateRequest(from, to, model, bytes.Clone(req.Payload), false)
+	body, errTranslate := translateRequest(ctx, from, to, model, bytes.Clone(req.Payload), false)
+	if errTranslate != nil {
+		return resp, errTranslate
+	}
 	body = ApplyThinkingMetadata(body, req.Metadata, model)
 	body = util.ApplyDefaultThinkingIfNeeded(model, body)
 	body = util.NormalizeGeminiThinkingBudget(model, body)
@@ -164,7 +167,10 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, r
 	appendAPIResponseChunk(ctx, e.cfg, data)
 	reporter.publish(ctx, parseGeminiUsage(data))
 	var param any
-	out := sdktranslator.TranslateNonStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), body, data, &param)
+	out, errTranslate := translateNonStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), body, data, &param)
+	if errTranslate != nil {
+		return resp, errTranslate
+	}
 	resp = cliproxyexecutor.Response{Payload: []byte(out)}
 	return resp, nil
 }
@@ -183,7 +189,10 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.A
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("gemini")
-	body := sdktranslator.TranslateRequest(from, to, model, bytes.Clone(req.Payload), true)
+	body, errTranslate := translateRequest(ctx, from, to, model, bytes.Clone(req.Payload), true)
+	if errTranslate != nil {
+		return nil, errTranslate
+	}
 	body = ApplyThinkingMetadata(body, req.Metadata, model)
 	body = util.ApplyDefaultThinkingIfNeeded(model, body)
 	body = util.NormalizeGeminiThinkingBudget(model, body)
@@ -271,12 +280,20 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.A
 			if detail, ok := parseGeminiStreamUsage(payload); ok {
 				reporter.publish(ctx, detail)
 			}
-			lines := sdktranslator.TranslateStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), body, bytes.Clone(payload), &param)
+			lines, errTranslate := translateStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), body, bytes.Clone(payload), &param)
+			if errTranslate != nil {
+				out <- cliproxyexecutor.StreamChunk{Err: errTranslate}
+				return
+			}
 			for i := range lines {
 				out <- cliproxyexecutor.StreamChunk{Payload: []byte(lines[i])}
 			}
 		}
-		lines := sdktranslator.TranslateStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), body, bytes.Clone([]byte("[DONE]")), &param)
+		lines, errTranslate := translateStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), body, bytes.Clone([]byte("[DONE]")), &param)
+		if errTranslate != nil {
+			out <- cliproxyexecutor.StreamChunk{Err: errTranslate}
+			return
+		}
 		for i := range lines {
 			out <- cliproxyexecutor.StreamChunk{Payload: []byte(lines[i])}
 		}
@@ -300,7 +317,10 @@ func (e *GeminiExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Aut
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("gemini")
-	translatedReq := sdktranslator.TranslateRequest(from, to, model, bytes.Clone(req.Payload), false)
+	translatedReq, errTranslate := translateRequest(ctx, from, to, model, bytes.Clone(req.Payload), false)
+	if errTranslate != nil {
+		return cliproxyexecutor.Response{}, errTranslate
+	}
 	translatedReq = ApplyThinkingMetadata(translatedReq, req.Metadata, model)
 	translatedReq = util.StripThinkingConfigIfUnsupported(model, translatedReq)
 	translatedReq = fixGeminiImageAspectRatio(model, translatedReq)
@@ -369,6 +389,63 @@ func (e *GeminiExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Aut
 	return cliproxyexecutor.Response{Payload: []byte(translated)}, nil
 }
 
+// Embed creates embeddings using the Gemini API embedContent/batchEmbedContents endpoints.
+// Gemini-format requests are forwarded as-is; other formats are translated to batchEmbedContents.
+func (e *GeminiExecutor) Embed(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	apiKey, bearer := geminiCreds(auth)
+
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	model := req.Model
+	if override := e.resolveUpstreamModel(model, auth); override != "" {
+		model = override
+	}
+
+	from := opts.SourceFormat
+	action := embeddingAction(req)
+	embedReq, err := parseEmbeddingRequest(from, action, req.Payload)
+	if err != nil {
+		return resp, err
+	}
+
+	passthrough := isGeminiSource(from) && action != ""
+	upstreamAction := geminiBatchEmbedContentsAction
+	var body []byte
+	if passthrough {
+		upstreamAction = action
+		body = bytes.Clone(req.Payload)
+		if action == geminiBatchEmbedContentsAction {
+			for i := range gjson.GetBytes(body, "requests").Array() {
+				body, _ = sjson.SetBytes(body, fmt.Sprintf("requests.%d.model", i), "models/"+model)
+			}
+		} else {
+			body, _ = sjson.SetBytes(body, "model", "models/"+model)
+		}
+	} else if body, err = geminiBatchEmbedBody(model, embedReq); err != nil {
+		return resp, err
+	}
+
+	url := fmt.Sprintf("%s/%s/models/%s:%s", resolveGeminiBaseURL(auth), glAPIVersion, model, upstreamAction)
+	data, err := postEmbeddingRequest(ctx, e.cfg, auth, e.Identifier(), url, body, func(httpReq *http.Request) {
+		if apiKey != "" {
+			httpReq.Header.Set("x-goog-api-key", apiKey)
+		} else if bearer != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		applyGeminiHeaders(httpReq, auth)
+	})
+	if err != nil {
+		return resp, err
+	}
+	// The Gemini API does not report token usage for embeddings; count the request only.
+	reporter.ensurePublished(ctx)
+	if passthrough {
+		return cliproxyexecutor.Response{Payload: data}, nil
+	}
+	return embeddingResponse(renderEmbeddingResponse(from, action, req.Model, embedReq, parseGeminiEmbeddings(data)))
+}
+
 // Refresh refreshes the authentication credentials (no-op for Gemini API key).
 func (e *GeminiExecutor) Refresh(_ context.Context, auth *cliproxyauth.Auth) (*cliproxyauth.Auth, error) {
 	return auth, nil
@@ -0,0 +1,209 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/tidwall/gjson"
+)
+
+// maxModelListPages bounds pagination of upstream list-models endpoints.
+const maxModelListPages = 10
+
+// FetchUpstreamModels lists the models an API-key credential can reach through the upstream's
+// list-models endpoint. provider selects the API dialect: "claude" (Anthropic /v1/models),
+// "gemini" (Gemini models.list) and anything else an OpenAI-compatible /models endpoint.
+func FetchUpstreamModels(ctx context.Context, auth *cliproxyauth.Auth, cfg *config.Config, provider string) ([]*registry.ModelInfo, error) {
+	if auth == nil || auth.Attributes == nil {
+		return nil, fmt.Errorf("model discovery: auth has no credentials")
+	}
+	apiKey := strings.TrimSpace(auth.Attributes["api_key"])
+	baseURL := strings.TrimRight(strings.TrimSpace(auth.Attributes["base_url"]), "/")
+	client := newProxyAwareHTTPClient(ctx, cfg, auth, 30*time.Second)
+	switch provider {
+	case "claude":
+		if baseURL == "" {
+			baseURL = "https://api.anthropic.com"
+		}
+		return fetchClaudeModels(ctx, client, auth, baseURL, apiKey)
+	case "gemini":
+		return fetchGeminiModels(ctx, client, auth, resolveGeminiBaseURL(auth), apiKey)
+	default:
+		if baseURL == "" {
+			return nil, fmt.Errorf("model discovery: %s credential has no base url", provider)
+		}
+		return fetchOpenAIModels(ctx, client, auth, baseURL, apiKey, provider)
+	}
+}
+
+func fetchOpenAIModels(ctx context.Context, client *http.Client, auth *cliproxyauth.Auth, baseURL, apiKey, provider string) ([]*registry.ModelInfo, error) {
+	body, err := getModelList(ctx, client, auth, baseURL+"/models", func(r *http.Request) {
+		if apiKey != "" {
+			r.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	var models []*registry.ModelInfo
+	for _, item := range gjson.GetBytes(body, "data").Array() {
+		id := strings.TrimSpace(item.Get("id").String())
+		if id == "" {
+			continue
+		}
+		created := item.Get("created").Int()
+		if created == 0 {
+			created = now
+		}
+		ownedBy := item.Get("owned_by").String()
+		if ownedBy == "" {
+			ownedBy = provider
+		}
+		models = append(models, &registry.ModelInfo{
+			ID:          id,
+			Object:      "model",
+			Created:     created,
+			OwnedBy:     ownedBy,
+			Type:        provider,
+			DisplayName: id,
+		})
+	}
+	return models, nil
+}
+
+func fetchClaudeModels(ctx context.Context, client *http.Client, auth *cliproxyauth.Auth, baseURL, apiKey string) ([]*registry.ModelInfo, error) {
+	var models []*registry.ModelInfo
+	afterID := ""
+	for page := 0; page < maxModelListPages; page++ {
+		query := url.Values{"limit": {"1000"}}
+		if afterID != "" {
+			query.Set("after_id", afterID)
+		}
+		body, err := getModelList(ctx, client, auth, baseURL+"/v1/models?"+query.Encode(), func(r *http.Request) {
+			if strings.EqualFold(r.URL.Host, "api.anthropic.com") {
+				r.Header.Set("x-api-key", apiKey)
+			} else {
+				r.Header.Set("Authorization", "Bearer "+apiKey)
+			}
+			r.Header.Set("anthropic-version", "2023-06-01")
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range gjson.GetBytes(body, "data").Array() {
+			id := strings.TrimSpace(item.Get("id").String())
+			if id == "" {
+				continue
+			}
+			created := time.Now().Unix()
+			if ts, errParse := time.Parse(time.RFC3339, item.Get("created_at").String()); errParse == nil {
+				created = ts.Unix()
+			}
+			displayName := item.Get("display_name").String()
+			if displayName == "" {
+				displayName = id
+			}
+			models = append(models, &registry.ModelInfo{
+				ID:          id,
+				Object:      "model",
+				Created:     created,
+				OwnedBy:     "anthropic",
+				Type:        "claude",
+				DisplayName: displayName,
+			})
+		}
+		afterID = gjson.GetBytes(body, "last_id").String()
+		if !gjson.GetBytes(body, "has_more").Bool() || afterID == "" {
+			break
+		}
+	}
+	return models, nil
+}
+
+func fetchGeminiModels(ctx context.Context, client *http.Client, auth *cliproxyauth.Auth, baseURL, apiKey string) ([]*registry.ModelInfo, error) {
+	var models []*registry.ModelInfo
+	pageToken := ""
+	now := time.Now().Unix()
+	for page := 0; page < maxModelListPages; page++ {
+		query := url.Values{"pageSize": {"1000"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		body, err := getModelList(ctx, client, auth, baseURL+"/v1beta/models?"+query.Encode(), func(r *http.Request) {
+			r.Header.Set("x-goog-api-key", apiKey)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range gjson.GetBytes(body, "models").Array() {
+			name := item.Get("name").String()
+			id := strings.TrimPrefix(name, "models/")
+			if id == "" {
+				continue
+			}
+			var methods []string
+			for _, method := range item.Get("supportedGenerationMethods").Array() {
+				methods = append(methods, method.String())
+			}
+			displayName := item.Get("displayName").String()
+			if displayName == "" {
+				displayName = id
+			}
+			models = append(models, &registry.ModelInfo{
+				ID:                         id,
+				Object:                     "model",
+				Created:                    now,
+				OwnedBy:                    "google",
+				Type:                       "gemini",
+				DisplayName:                displayName,
+				Name:                       name,
+				Version:                    item.Get("version").String(),
+				Description:                item.Get("description").String(),
+				InputTokenLimit:            int(item.Get("inputTokenLimit").Int()),
+				OutputTokenLimit:           int(item.Get("outputTokenLimit").Int()),
+				SupportedGenerationMethods: methods,
+			})
+		}
+		pageToken = gjson.GetBytes(body, "nextPageToken").String()
+		if pageToken == "" {
+			break
+		}
+	}
+	return models, nil
+}
+
+// getModelList performs one list-models GET with the credential's custom headers applied.
+func getModelList(ctx context.Context, client *http.Client, auth *cliproxyauth.Auth, endpoint string, authorize func(*http.Request)) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	authorize(httpReq)
+	util.ApplyCustomHeadersFromAttrs(httpReq, auth.Attributes)
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = httpResp.Body.Close()
+	}()
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, 16<<20))
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusMultipleChoices {
+		return nil, statusErr{code: httpResp.StatusCode, msg: string(body)}
+	}
+	return body, nil
+}
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestFetchUpstreamModelsClaudeFollowsPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q, want bearer key", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("after_id") == "" {
+			_, _ = w.Write([]byte(`{"data":[{"id":"claude-a","display_name":"Claude A","created_at":"2025-01-02T00:00:00Z"}],"has_more":true,"last_id":"claude-a"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"claude-b"}],"has_more":false,"last_id":"claude-b"}`))
+	}))
+	defer server.Close()
+
+	auth := &cliproxyauth.Auth{ID: "claude-key", Provider: "claude", Attributes: map[string]string{
+		"api_key":  "sk-test",
+		"base_url": server.URL,
+	}}
+	models, err := FetchUpstreamModels(context.Background(), auth, &config.Config{}, "claude")
+	if err != nil {
+		t.Fatalf("FetchUpstreamModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "claude-a" || models[1].ID != "claude-b" {
+		t.Fatalf("models = %+v, want claude-a and claude-b", models)
+	}
+	if models[0].DisplayName != "Claude A" || models[1].DisplayName != "claude-b" {
+		t.Fatalf("display names = %q, %q", models[0].DisplayName, models[1].DisplayName)
+	}
+}
+
+func TestFetchUpstreamModelsParsesListings(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  string
+		path      string
+		pages     map[string]string
+		wantIDs   []string
+		wantOwner string
+		wantName  string
+	}{
+		{
+			name:     "openai",
+			provider: "openai-compatibility",
+			path:     "/models",
+			pages: map[string]string{
+				"": `{"object":"list","data":[{"id":"gpt-a","created":1700000000,"owned_by":"acme"},{"id":""},{"id":"gpt-b"}]}`,
+			},
+			wantIDs:   []string{"gpt-a", "gpt-b"},
+			wantOwner: "acme",
+			wantName:  "gpt-a",
+		},
+		{
+			name:     "gemini",
+			provider: "gemini",
+			path:     "/v1beta/models",
+			pages: map[string]string{
+				"":     `{"models":[{"name":"models/gemini-a","displayName":"Gemini A","inputTokenLimit":1000,"supportedGenerationMethods":["generateContent"]}],"nextPageToken":"next"}`,
+				"next": `{"models":[{"name":"models/gemini-b"}]}`,
+			},
+			wantIDs:   []string{"gemini-a", "gemini-b"},
+			wantOwner: "google",
+			wantName:  "Gemini A",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					t.Errorf("path = %q, want %q", r.URL.Path, tt.path)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.pages[r.URL.Query().Get("pageToken")]))
+			}))
+			defer server.Close()
+
+			auth := &cliproxyauth.Auth{ID: tt.name + "-key", Provider: tt.provider, Attributes: map[string]string{
+				"api_key":  "sk-test",
+				"base_url": server.URL,
+			}}
+			models, err := FetchUpstreamModels(context.Background(), auth, &config.Config{}, tt.provider)
+			if err != nil {
+				t.Fatalf("FetchUpstreamModels() error = %v", err)
+			}
+			if len(models) != len(tt.wantIDs) {
+				t.Fatalf("models = %d, want %d", len(models), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if models[i].ID != id {
+					t.Fatalf("models[%d].ID = %q, want %q", i, models[i].ID, id)
+				}
+				if models[i].Created == 0 {
+					t.Fatalf("models[%d].Created is zero", i)
+				}
+			}
+			if models[0].OwnedBy != tt.wantOwner || models[0].DisplayName != tt.wantName {
+				t.Fatalf("models[0] owner = %q, name = %q", models[0].OwnedBy, models[0].DisplayName)
+			}
+		})
+	}
+}
+
+func TestFetchUpstreamModelsReportsUpstreamErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	auth := &cliproxyauth.Auth{ID: "bad-key", Provider: "codex", Attributes: map[string]string{
+		"api_key":  "sk-bad",
+		"base_url": server.URL,
+	}}
+	if _, err := FetchUpstreamModels(context.Background(), auth, &config.Config{}, "codex"); err == nil {
+		t.Fatalf("expected an error for a 401 listing")
+	}
+}
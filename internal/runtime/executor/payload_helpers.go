@@ -184,7 +184,7 @@ func payloadRuleMatchesModel(rule *config.PayloadRule, model, protocol string) b
 		if ep := strings.TrimSpace(entry.Protocol); ep != "" && protocol != "" && !strings.EqualFold(ep, protocol) {
 			continue
 		}
-		if matchModelPattern(name, model) {
+		if util.MatchModelPattern(name, model) {
 			return true
 		}
 	}
@@ -209,51 +209,6 @@ func buildPayloadPath(root, path string) string {
 	return r + "." + p
 }
 
-// matchModelPattern performs simple wildcard matching where '*' matches zero or more characters.
-// Examples:
-//
-//	"*-5" matches "gpt-5"
-//	"gpt-*" matches "gpt-5" and "gpt-4"
-//	"gemini-*-pro" matches "gemini-2.5-pro" and "gemini-3-pro".
-func matchModelPattern(pattern, model string) bool {
-	pattern = strings.TrimSpace(pattern)
-	model = strings.TrimSpace(model)
-	if pattern == "" {
-		return false
-	}
-	if pattern == "*" {
-		return true
-	}
-	// Iterative glob-style matcher supporting only '*' wildcard.
-	pi, si := 0, 0
-	starIdx := -1
-	matchIdx := 0
-	for si < len(model) {
-		if pi < len(pattern) && (pattern[pi] == model[si]) {
-			pi++
-			si++
-			continue
-		}
-		if pi < len(pattern) && pattern[pi] == '*' {
-			starIdx = pi
-			matchIdx = si
-			pi++
-			continue
-		}
-		if starIdx != -1 {
-			pi = starIdx + 1
-			matchIdx++
-			si = matchIdx
-			continue
-		}
-		return false
-	}
-	for pi < len(pattern) && pattern[pi] == '*' {
-		pi++
-	}
-	return pi == len(pattern)
-}
-
 // NormalizeThinkingConfig normalizes thinking-related fields in the payload
 // based on model capabilities. For models without thinking support, it strips
 // reasoning fields. For models with level-based thinking, it validates and
@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// translateRequest converts the client payload into the upstream format within a trace span.
+// The conversion runs through the translator pipeline of the calling service so registered
+// request middleware can rewrite or reject the payload.
+func translateRequest(ctx context.Context, from, to sdktranslator.Format, model string, rawJSON []byte, stream bool) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "cliproxy.translate_request",
+		tracing.AttrFromFormat.String(from.String()),
+		tracing.AttrToFormat.String(to.String()),
+		tracing.AttrModel.String(model),
+	)
+	defer span.End()
+	out, err := sdktranslator.PipelineFromContext(ctx).TranslateRequest(ctx, from, to, sdktranslator.RequestEnvelope{
+		Format: from,
+		Model:  model,
+		Stream: stream,
+		Body:   rawJSON,
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// translateNonStream converts a complete upstream response into the client format within a trace span.
+func translateNonStream(ctx context.Context, from, to sdktranslator.Format, model string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) (string, error) {
+	ctx, span := tracing.Start(ctx, "cliproxy.translate_response",
+		tracing.AttrFromFormat.String(from.String()),
+		tracing.AttrToFormat.String(to.String()),
+		tracing.AttrModel.String(model),
+	)
+	defer span.End()
+	out, err := sdktranslator.PipelineFromContext(ctx).TranslateResponse(ctx, from, to, sdktranslator.ResponseEnvelope{
+		Format: from,
+		Model:  model,
+		Body:   rawJSON,
+	}, originalRequestRawJSON, requestRawJSON, param)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return "", err
+	}
+	return string(out.Body), nil
+}
+
+// translateStream converts one upstream stream chunk into client format chunks. Stream chunks
+// are not traced individually.
+func translateStream(ctx context.Context, from, to sdktranslator.Format, model string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) ([]string, error) {
+	out, err := sdktranslator.PipelineFromContext(ctx).TranslateResponse(ctx, from, to, sdktranslator.ResponseEnvelope{
+		Format: from,
+		Model:  model,
+		Stream: true,
+		Body:   rawJSON,
+	}, originalRequestRawJSON, requestRawJSON, param)
+	if err != nil {
+		return nil, err
+	}
+	return out.Chunks, nil
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	"github.com/tidwall/gjson"
@@ -84,6 +85,7 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, detail usage.Det
 			Failed:      failed,
 			Detail:      detail,
 		})
+		logging.AccessRecordFromContext(ctx).AddUsage(detail.InputTokens, detail.OutputTokens, detail.ReasoningTokens, detail.CachedTokens, detail.TotalTokens)
 	})
 }
 
@@ -236,22 +238,14 @@ func parseOpenAIStreamUsage(line []byte) (usage.Detail, bool) {
 	return detail, true
 }
 
+// parseClaudeUsage reads the usage block of a Claude response. CachedTokens counts prompt cache
+// reads only; cache creation tokens are not hits, so they are counted as input instead.
 func parseClaudeUsage(data []byte) usage.Detail {
 	usageNode := gjson.ParseBytes(data).Get("usage")
 	if !usageNode.Exists() {
 		return usage.Detail{}
 	}
-	detail := usage.Detail{
-		InputTokens:  usageNode.Get("input_tokens").Int(),
-		OutputTokens: usageNode.Get("output_tokens").Int(),
-		CachedTokens: usageNode.Get("cache_read_input_tokens").Int(),
-	}
-	if detail.CachedTokens == 0 {
-		// fall back to creation tokens when read tokens are absent
-		detail.CachedTokens = usageNode.Get("cache_creation_input_tokens").Int()
-	}
-	detail.TotalTokens = detail.InputTokens + detail.OutputTokens
-	return detail
+	return claudeUsageDetail(usageNode)
 }
 
 func parseClaudeStreamUsage(line []byte) (usage.Detail, bool) {
@@ -263,16 +257,17 @@ func parseClaudeStreamUsage(line []byte) (usage.Detail, bool) {
 	if !usageNode.Exists() {
 		return usage.Detail{}, false
 	}
+	return claudeUsageDetail(usageNode), true
+}
+
+func claudeUsageDetail(usageNode gjson.Result) usage.Detail {
 	detail := usage.Detail{
-		InputTokens:  usageNode.Get("input_tokens").Int(),
+		InputTokens:  usageNode.Get("input_tokens").Int() + usageNode.Get("cache_creation_input_tokens").Int(),
 		OutputTokens: usageNode.Get("output_tokens").Int(),
 		CachedTokens: usageNode.Get("cache_read_input_tokens").Int(),
 	}
-	if detail.CachedTokens == 0 {
-		detail.CachedTokens = usageNode.Get("cache_creation_input_tokens").Int()
-	}
 	detail.TotalTokens = detail.InputTokens + detail.OutputTokens
-	return detail, true
+	return detail
 }
 
 func parseGeminiFamilyUsageDetail(node gjson.Result) usage.Detail {
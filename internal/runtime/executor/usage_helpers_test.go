@@ -0,0 +1,17 @@
+package executor
+
+import "testing"
+
+func TestParseClaudeUsageCountsCacheCreationAsInput(t *testing.T) {
+	body := []byte(`{"usage":{"input_tokens":10,"cache_creation_input_tokens":200,"cache_read_input_tokens":300,"output_tokens":5}}`)
+	detail := parseClaudeUsage(body)
+	if detail.InputTokens != 210 || detail.CachedTokens != 300 || detail.OutputTokens != 5 || detail.TotalTokens != 215 {
+		t.Fatalf("parseClaudeUsage() = %+v, want input 210 (with cache creation), cached 300, total 215", detail)
+	}
+
+	line := []byte(`data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"input_tokens":10,"cache_creation_input_tokens":200,"cache_read_input_tokens":300,"output_tokens":1}}`)
+	streamed, ok := parseClaudeStreamUsage(line)
+	if !ok || streamed.InputTokens != 210 || streamed.CachedTokens != 300 || streamed.TotalTokens != 211 {
+		t.Fatalf("parseClaudeStreamUsage() = %+v, %t; want input 210, cached 300, total 211", streamed, ok)
+	}
+}
@@ -239,7 +239,7 @@ func (s *ObjectTokenStore) List(_ context.Context) ([]*cliproxyauth.Auth, error)
 		if d.IsDir() {
 			return nil
 		}
-		if !strings.HasSuffix(strings.ToLower(d.Name()), ".json") {
+		if !strings.HasSuffix(strings.ToLower(d.Name()), ".json") || misc.IsAuthDirStateFile(path) {
 			return nil
 		}
 		auth, err := s.readAuthFile(path, dir)
@@ -594,6 +594,7 @@ func (s *ObjectTokenStore) readAuthFile(path, baseDir string) (*cliproxyauth.Aut
 		LastRefreshedAt:  time.Time{},
 		NextRefreshAfter: time.Time{},
 	}
+	auth.ApplyMetadataSettings()
 	return auth, nil
 }
 
@@ -99,6 +99,14 @@ func NewPostgresStore(ctx context.Context, cfg PostgresStoreConfig) (*PostgresSt
 	return store, nil
 }
 
+// DB exposes the underlying connection so other subsystems can share it.
+func (s *PostgresStore) DB() *sql.DB {
+	if s == nil {
+		return nil
+	}
+	return s.db
+}
+
 // Close releases the underlying database connection.
 func (s *PostgresStore) Close() error {
 	if s == nil || s.db == nil {
@@ -113,7 +121,7 @@ func (s *PostgresStore) EnsureSchema(ctx context.Context) error {
 		return fmt.Errorf("postgres store: not initialized")
 	}
 	if schema := strings.TrimSpace(s.cfg.Schema); schema != "" {
-		query := fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", quoteIdentifier(schema))
+		query := fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", QuoteIdentifier(schema))
 		if _, err := s.db.ExecContext(ctx, query); err != nil {
 			return fmt.Errorf("postgres store: create schema: %w", err)
 		}
@@ -279,6 +287,9 @@ func (s *PostgresStore) List(ctx context.Context) ([]*cliproxyauth.Auth, error)
 		if err = rows.Scan(&id, &payload, &createdAt, &updatedAt); err != nil {
 			return nil, fmt.Errorf("postgres store: scan auth row: %w", err)
 		}
+		if misc.IsAuthDirStateFile(id) {
+			continue
+		}
 		path, errPath := s.absoluteAuthPath(id)
 		if errPath != nil {
 			log.WithError(errPath).Warnf("postgres store: skipping auth %s outside spool", id)
@@ -310,6 +321,7 @@ func (s *PostgresStore) List(ctx context.Context) ([]*cliproxyauth.Auth, error)
 			LastRefreshedAt:  time.Time{},
 			NextRefreshAfter: time.Time{},
 		}
+		auth.ApplyMetadataSettings()
 		auths = append(auths, auth)
 	}
 	if err = rows.Err(); err != nil {
@@ -614,12 +626,14 @@ func (s *PostgresStore) absoluteAuthPath(id string) (string, error) {
 
 func (s *PostgresStore) fullTableName(name string) string {
 	if strings.TrimSpace(s.cfg.Schema) == "" {
-		return quoteIdentifier(name)
+		return QuoteIdentifier(name)
 	}
-	return quoteIdentifier(s.cfg.Schema) + "." + quoteIdentifier(name)
+	return QuoteIdentifier(s.cfg.Schema) + "." + QuoteIdentifier(name)
 }
 
-func quoteIdentifier(identifier string) string {
+// QuoteIdentifier quotes a Postgres identifier such as a schema or table name. It is shared
+// by the other Postgres-backed stores that live alongside the token store's tables.
+func QuoteIdentifier(identifier string) string {
 	replaced := strings.ReplaceAll(identifier, "\"", "\"\"")
 	return "\"" + replaced + "\""
 }
@@ -0,0 +1,213 @@
+// Package tracing provides OpenTelemetry tracing for the proxy. Spans cover the inbound request,
+// the API handler, request translation, credential selection, cooldown waits, every upstream
+// attempt and the upstream HTTP call. Tracing is off by default; while it is off every helper
+// returns non-recording spans, so call sites need no guards.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	instrumentationName = "github.com/router-for-me/CLIProxyAPI"
+	defaultServiceName  = "cli-proxy-api"
+	protocolGRPC        = "grpc"
+)
+
+// Span attribute keys shared by the instrumented layers.
+const (
+	AttrProvider      = attribute.Key("cliproxy.provider")
+	AttrModel         = attribute.Key("cliproxy.model")
+	AttrUpstreamModel = attribute.Key("cliproxy.upstream_model")
+	AttrAuthIndex     = attribute.Key("cliproxy.auth_index")
+	AttrHandler       = attribute.Key("cliproxy.handler")
+	AttrAttempt       = attribute.Key("cliproxy.attempt")
+	AttrStream        = attribute.Key("cliproxy.stream")
+	AttrFromFormat    = attribute.Key("cliproxy.translate.from")
+	AttrToFormat      = attribute.Key("cliproxy.translate.to")
+	AttrWaitMs        = attribute.Key("cliproxy.wait_ms")
+	AttrRequestID     = attribute.Key("cliproxy.request_id")
+)
+
+var (
+	mu         sync.Mutex
+	current    *sdktrace.TracerProvider
+	currentCfg config.TracingConfig
+	enabled    atomic.Bool
+	tracer     atomic.Pointer[tracerRef]
+	propagator = propagation.TraceContext{}
+)
+
+// tracerRef boxes the active tracer so the noop and SDK implementations can be swapped atomically.
+type tracerRef struct{ trace.Tracer }
+
+func init() {
+	setTracer(noop.NewTracerProvider().Tracer(instrumentationName))
+}
+
+func setTracer(t trace.Tracer) { tracer.Store(&tracerRef{t}) }
+
+func currentTracer() trace.Tracer { return tracer.Load().Tracer }
+
+// Configure installs an OTLP exporter according to cfg, replacing and flushing the previous one.
+// Calling it again with an unchanged configuration is a no-op.
+func Configure(ctx context.Context, cfg config.TracingConfig) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if (current != nil || !cfg.Enable) && reflect.DeepEqual(cfg, currentCfg) {
+		return nil
+	}
+	previous := current
+	current = nil
+	currentCfg = cfg
+	enabled.Store(false)
+	setTracer(noop.NewTracerProvider().Tracer(instrumentationName))
+	if previous != nil {
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_ = previous.Shutdown(shutdownCtx)
+		cancel()
+	}
+	if !cfg.Enable {
+		return nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("tracing: create exporter: %w", err)
+	}
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	res := resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+		attribute.String("service.version", buildinfo.Version),
+	)
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	current = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	setTracer(current.Tracer(instrumentationName))
+	enabled.Store(true)
+	return nil
+}
+
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	endpoint := strings.TrimSpace(cfg.Endpoint)
+	if strings.EqualFold(strings.TrimSpace(cfg.Protocol), protocolGRPC) {
+		var opts []otlptracegrpc.Option
+		if endpoint != "" {
+			if strings.Contains(endpoint, "://") {
+				opts = append(opts, otlptracegrpc.WithEndpointURL(endpoint))
+			} else {
+				opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+			}
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	}
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		if strings.Contains(endpoint, "://") {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		} else {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		}
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+	return otlptracehttp.New(ctx, opts...)
+}
+
+// Shutdown flushes pending spans and stops the exporter.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled.Store(false)
+	setTracer(noop.NewTracerProvider().Tracer(instrumentationName))
+	if current == nil {
+		return nil
+	}
+	err := current.Shutdown(ctx)
+	current = nil
+	currentCfg = config.TracingConfig{}
+	return err
+}
+
+// Enabled reports whether spans are currently exported.
+func Enabled() bool { return enabled.Load() }
+
+// Start begins a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return currentTracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RecordError marks the span as failed with err. A nil err is ignored.
+func RecordError(span trace.Span, err error) {
+	if span == nil || err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// End records err on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	RecordError(span, err)
+	span.End()
+}
+
+// ContextWithRequestSpan carries the span of requestCtx over to ctx unless ctx already has one.
+// Handlers run on contexts detached from the inbound request, so this keeps them in its trace.
+func ContextWithRequestSpan(ctx, requestCtx context.Context) context.Context {
+	if ctx == nil || requestCtx == nil {
+		return ctx
+	}
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	span := trace.SpanFromContext(requestCtx)
+	if !span.SpanContext().IsValid() {
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, span)
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestMiddlewareContinuesClientTraceAndSpansUpstreamCalls(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	setTracer(provider.Tracer(instrumentationName))
+	enabled.Store(true)
+	t.Cleanup(func() {
+		enabled.Store(false)
+		setTracer(noop.NewTracerProvider().Tracer(instrumentationName))
+	})
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("traceparent") != "" {
+			t.Errorf("trace context leaked to upstream: %q", r.Header.Get("traceparent"))
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	engine := gin.New()
+	engine.Use(Middleware())
+	engine.POST("/v1/chat/completions", func(c *gin.Context) {
+		req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, upstream.URL+"/v1/generate?key=secret", nil)
+		resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+		if err != nil {
+			t.Errorf("upstream call: %v", err)
+			return
+		}
+		_ = resp.Body.Close()
+		c.Status(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	client, server := spans[0], spans[1]
+	if got := server.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("server trace id = %s, want the client's", got)
+	}
+	if server.Parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Fatalf("server parent = %s, want the client span", server.Parent.SpanID())
+	}
+	if client.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Fatalf("upstream span parent = %s, want server span %s", client.Parent.SpanID(), server.SpanContext.SpanID())
+	}
+	for _, attr := range client.Attributes {
+		if attr.Key == "url.path" && attr.Value.AsString() != "/v1/generate" {
+			t.Fatalf("upstream url.path = %q", attr.Value.AsString())
+		}
+	}
+}
+
+func TestConfigureTogglesExporter(t *testing.T) {
+	cfg := config.TracingConfig{Enable: true, Protocol: "grpc", Endpoint: "127.0.0.1:4317", Insecure: true}
+	if err := Configure(context.Background(), cfg); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if !Enabled() {
+		t.Fatal("tracing not enabled")
+	}
+	if err := Configure(context.Background(), config.TracingConfig{}); err != nil {
+		t.Fatalf("Configure disabled: %v", err)
+	}
+	if Enabled() {
+		t.Fatal("tracing still enabled")
+	}
+	_, span := Start(context.Background(), "noop")
+	if span.IsRecording() {
+		t.Fatal("span recorded while disabled")
+	}
+}
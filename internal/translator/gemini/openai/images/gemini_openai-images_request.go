@@ -0,0 +1,141 @@
+// Package images translates OpenAI Images API requests (generations and edits) into Gemini
+// generateContent requests for image-capable models and converts the responses back.
+package images
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// DefaultModel is used when an Images API request does not name a model.
+const DefaultModel = "gemini-2.5-flash-image"
+
+// MaxImages bounds the number of images generated for a single request.
+const MaxImages = 10
+
+// supportedAspectRatios lists the aspect ratios accepted by Gemini imageConfig.
+var supportedAspectRatios = []string{"1:1", "2:3", "3:2", "3:4", "4:3", "4:5", "5:4", "9:16", "16:9", "21:9"}
+
+// InlineImage is an uploaded image passed to the model as inline data.
+type InlineImage struct {
+	MIMEType string
+	Data     []byte
+}
+
+// Request is the provider-neutral form of an OpenAI Images generation or edit request.
+type Request struct {
+	Model          string
+	Prompt         string
+	N              int
+	Size           string
+	ResponseFormat string
+	// Images holds the source images of an edit request.
+	Images []InlineImage
+	// Mask marks the editable area of the first image; Gemini has no native mask so it is sent
+	// as an additional image with an instruction.
+	Mask *InlineImage
+}
+
+// ParseGenerationRequest decodes an /v1/images/generations JSON body.
+func ParseGenerationRequest(rawJSON []byte) (Request, error) {
+	if !gjson.ValidBytes(rawJSON) {
+		return Request{}, fmt.Errorf("request body must be valid JSON")
+	}
+	root := gjson.ParseBytes(rawJSON)
+	req := Request{
+		Model:          root.Get("model").String(),
+		Prompt:         root.Get("prompt").String(),
+		Size:           root.Get("size").String(),
+		ResponseFormat: root.Get("response_format").String(),
+	}
+	if n := root.Get("n"); n.Exists() {
+		req.N = int(n.Int())
+	}
+	return req, req.Normalize()
+}
+
+// Normalize applies defaults and validates the request.
+func (r *Request) Normalize() error {
+	r.Model = strings.TrimSpace(r.Model)
+	if r.Model == "" {
+		r.Model = DefaultModel
+	}
+	if strings.TrimSpace(r.Prompt) == "" {
+		return fmt.Errorf("prompt is required")
+	}
+	if r.N == 0 {
+		r.N = 1
+	}
+	if r.N < 1 || r.N > MaxImages {
+		return fmt.Errorf("n must be between 1 and %d", MaxImages)
+	}
+	r.ResponseFormat = strings.ToLower(strings.TrimSpace(r.ResponseFormat))
+	switch r.ResponseFormat {
+	case "":
+		r.ResponseFormat = "b64_json"
+	case "b64_json", "url":
+	default:
+		return fmt.Errorf("response_format must be b64_json or url")
+	}
+	if _, err := AspectRatioForSize(r.Size); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AspectRatioForSize maps an OpenAI size ("1024x1536") to the closest Gemini aspect ratio. An
+// empty or "auto" size returns "" so the model picks the ratio.
+func AspectRatioForSize(size string) (string, error) {
+	size = strings.ToLower(strings.TrimSpace(size))
+	if size == "" || size == "auto" {
+		return "", nil
+	}
+	widthText, heightText, ok := strings.Cut(size, "x")
+	width, errWidth := strconv.Atoi(strings.TrimSpace(widthText))
+	height, errHeight := strconv.Atoi(strings.TrimSpace(heightText))
+	if !ok || errWidth != nil || errHeight != nil || width <= 0 || height <= 0 {
+		return "", fmt.Errorf("size must be auto or WIDTHxHEIGHT, got %q", size)
+	}
+	target := math.Log(float64(width) / float64(height))
+	best, bestDistance := "", math.Inf(1)
+	for _, ratio := range supportedAspectRatios {
+		w, h, _ := strings.Cut(ratio, ":")
+		rw, _ := strconv.ParseFloat(w, 64)
+		rh, _ := strconv.ParseFloat(h, 64)
+		if distance := math.Abs(math.Log(rw/rh) - target); distance < bestDistance {
+			best, bestDistance = ratio, distance
+		}
+	}
+	return best, nil
+}
+
+// ConvertOpenAIImagesRequestToGemini builds the Gemini generateContent request for one image.
+func ConvertOpenAIImagesRequestToGemini(req Request) []byte {
+	out := []byte(`{"contents":[{"role":"user","parts":[]}],"generationConfig":{"responseModalities":["IMAGE","TEXT"]}}`)
+	for _, image := range req.Images {
+		out = appendInlineImage(out, image)
+	}
+	if req.Mask != nil {
+		out, _ = sjson.SetBytes(out, "contents.0.parts.-1.text", "The next image is a mask for the first image: only change the areas where the mask is transparent and keep everything else unchanged.")
+		out = appendInlineImage(out, *req.Mask)
+	}
+	out, _ = sjson.SetBytes(out, "contents.0.parts.-1.text", req.Prompt)
+	if ratio, _ := AspectRatioForSize(req.Size); ratio != "" {
+		out, _ = sjson.SetBytes(out, "generationConfig.imageConfig.aspectRatio", ratio)
+	}
+	return out
+}
+
+func appendInlineImage(out []byte, image InlineImage) []byte {
+	part := `{"inlineData":{"mimeType":"","data":""}}`
+	part, _ = sjson.Set(part, "inlineData.mimeType", image.MIMEType)
+	part, _ = sjson.Set(part, "inlineData.data", base64.StdEncoding.EncodeToString(image.Data))
+	out, _ = sjson.SetRawBytes(out, "contents.0.parts.-1", []byte(part))
+	return out
+}
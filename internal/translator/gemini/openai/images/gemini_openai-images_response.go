@@ -0,0 +1,71 @@
+package images
+
+import (
+	"fmt"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertGeminiResponsesToOpenAIImages merges the Gemini generateContent responses of one Images
+// API request into the OpenAI images response. With the "url" format images are returned as
+// data URLs since the proxy does not host files. At most limit images are returned, since a
+// response may carry several; limit <= 0 keeps all of them. It fails when no response carried
+// an image.
+func ConvertGeminiResponsesToOpenAIImages(responses [][]byte, responseFormat string, limit int, created int64) ([]byte, error) {
+	out := []byte(`{"created":0,"data":[]}`)
+	out, _ = sjson.SetBytes(out, "created", created)
+	var inputTokens, outputTokens int64
+	count := 0
+	finishReason := ""
+	for _, raw := range responses {
+		root := gjson.ParseBytes(raw)
+		if response := root.Get("response"); response.Exists() {
+			root = response
+		}
+		inputTokens += root.Get("usageMetadata.promptTokenCount").Int()
+		outputTokens += root.Get("usageMetadata.candidatesTokenCount").Int()
+		for _, candidate := range root.Get("candidates").Array() {
+			if reason := candidate.Get("finishReason").String(); reason != "" {
+				finishReason = reason
+			}
+			for _, part := range candidate.Get("content.parts").Array() {
+				inline := part.Get("inlineData")
+				if !inline.Exists() {
+					inline = part.Get("inline_data")
+				}
+				data := inline.Get("data").String()
+				if data == "" || part.Get("thought").Bool() || (limit > 0 && count >= limit) {
+					continue
+				}
+				item := `{}`
+				if responseFormat == "url" {
+					mimeType := inline.Get("mimeType").String()
+					if mimeType == "" {
+						mimeType = inline.Get("mime_type").String()
+					}
+					if mimeType == "" {
+						mimeType = "image/png"
+					}
+					item, _ = sjson.Set(item, "url", "data:"+mimeType+";base64,"+data)
+				} else {
+					item, _ = sjson.Set(item, "b64_json", data)
+				}
+				out, _ = sjson.SetRawBytes(out, "data.-1", []byte(item))
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		if finishReason != "" {
+			return nil, fmt.Errorf("model returned no image (finish reason %s)", finishReason)
+		}
+		return nil, fmt.Errorf("model returned no image")
+	}
+	if inputTokens > 0 || outputTokens > 0 {
+		out, _ = sjson.SetBytes(out, "usage.input_tokens", inputTokens)
+		out, _ = sjson.SetBytes(out, "usage.output_tokens", outputTokens)
+		out, _ = sjson.SetBytes(out, "usage.total_tokens", inputTokens+outputTokens)
+	}
+	return out, nil
+}
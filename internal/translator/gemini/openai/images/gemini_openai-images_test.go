@@ -0,0 +1,86 @@
+package images
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestImagesRequestRoundTrip(t *testing.T) {
+	for size, want := range map[string]string{"1024x1024": "1:1", "1536x1024": "3:2", "1024x1792": "9:16", "auto": ""} {
+		if got, err := AspectRatioForSize(size); err != nil || got != want {
+			t.Fatalf("AspectRatioForSize(%q) = %q, %v; want %q", size, got, err, want)
+		}
+	}
+
+	req, err := ParseGenerationRequest([]byte(`{"prompt":"a red fox","size":"1792x1024","n":2,"response_format":"url"}`))
+	if err != nil {
+		t.Fatalf("ParseGenerationRequest() error = %v", err)
+	}
+	req.Images = []InlineImage{{MIMEType: "image/png", Data: []byte("png")}}
+	body := ConvertOpenAIImagesRequestToGemini(req)
+	if got := gjson.GetBytes(body, "generationConfig.imageConfig.aspectRatio").String(); got != "16:9" {
+		t.Fatalf("aspectRatio = %q, want 16:9", got)
+	}
+	if got := gjson.GetBytes(body, "contents.0.parts.0.inlineData.data").String(); got != "cG5n" {
+		t.Fatalf("inline image = %q, want the base64 upload", got)
+	}
+	if got := gjson.GetBytes(body, "contents.0.parts.1.text").String(); got != "a red fox" {
+		t.Fatalf("prompt part = %q, want the prompt after the image", got)
+	}
+
+	upstream := []byte(`{"candidates":[{"content":{"parts":[{"text":"Here you go"},{"inlineData":{"mimeType":"image/jpeg","data":"AAAA"}}]}}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":1290}}`)
+	out, err := ConvertGeminiResponsesToOpenAIImages([][]byte{upstream, upstream}, req.ResponseFormat, req.N, 1700000000)
+	if err != nil {
+		t.Fatalf("ConvertGeminiResponsesToOpenAIImages() error = %v", err)
+	}
+	if got := gjson.GetBytes(out, "data.#").Int(); got != 2 {
+		t.Fatalf("images = %d, want 2", got)
+	}
+	if got := gjson.GetBytes(out, "data.1.url").String(); got != "data:image/jpeg;base64,AAAA" {
+		t.Fatalf("url = %q, want a data URL", got)
+	}
+	if got := gjson.GetBytes(out, "usage.total_tokens").Int(); got != 2590 {
+		t.Fatalf("total_tokens = %d, want 2590", got)
+	}
+
+	multi := []byte(`{"candidates":[{"content":{"parts":[{"inlineData":{"data":"AAAA"}},{"inlineData":{"data":"BBBB"}}]}}]}`)
+	if out, err = ConvertGeminiResponsesToOpenAIImages([][]byte{multi}, "b64_json", 1, 0); err != nil || gjson.GetBytes(out, "data.#").Int() != 1 {
+		t.Fatalf("images = %s, %v; want the output capped at n", out, err)
+	}
+
+	if _, err = ConvertGeminiResponsesToOpenAIImages([][]byte{[]byte(`{"candidates":[{"finishReason":"SAFETY"}]}`)}, "b64_json", 1, 0); err == nil {
+		t.Fatalf("expected an error when no image is returned")
+	}
+	if _, err = ParseGenerationRequest([]byte(`{"prompt":"x","n":11}`)); err == nil {
+		t.Fatalf("expected n above the limit to be rejected")
+	}
+}
+
+func TestImagesEditRequestWithMask(t *testing.T) {
+	req, err := ParseGenerationRequest([]byte(`{"prompt":"add a hat","size":"1024x1024"}`))
+	if err != nil {
+		t.Fatalf("ParseGenerationRequest() error = %v", err)
+	}
+	req.Images = []InlineImage{{MIMEType: "image/png", Data: []byte("src")}}
+	req.Mask = &InlineImage{MIMEType: "image/png", Data: []byte("mask")}
+	body := ConvertOpenAIImagesRequestToGemini(req)
+
+	parts := gjson.GetBytes(body, "contents.0.parts").Array()
+	if len(parts) != 4 {
+		t.Fatalf("parts = %d, want image, mask instruction, mask and prompt", len(parts))
+	}
+	if got := parts[0].Get("inlineData.data").String(); got != "c3Jj" {
+		t.Fatalf("first part = %q, want the source image", got)
+	}
+	if got := parts[1].Get("text").String(); !strings.Contains(got, "mask") {
+		t.Fatalf("second part = %q, want the mask instruction", got)
+	}
+	if got := parts[2].Get("inlineData.data").String(); got != "bWFzaw==" {
+		t.Fatalf("third part = %q, want the mask image", got)
+	}
+	if got := parts[3].Get("text").String(); got != "add a hat" {
+		t.Fatalf("last part = %q, want the prompt", got)
+	}
+}
@@ -13,8 +13,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	log "github.com/sirupsen/logrus"
 )
 
+// persistTimeout bounds a single write to the usage store.
+const persistTimeout = 5 * time.Second
+
 var statisticsEnabled atomic.Bool
 
 func init() {
@@ -71,6 +75,12 @@ type RequestStatistics struct {
 	requestsByHour map[int]int64
 	tokensByDay    map[string]int64
 	tokensByHour   map[int]int64
+
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+
+	storeMu sync.RWMutex
+	store   Store
 }
 
 // apiStats holds aggregated metrics for a single API key.
@@ -96,7 +106,8 @@ type RequestDetail struct {
 	Failed    bool       `json:"failed"`
 }
 
-// TokenStats captures the token usage breakdown for a request.
+// TokenStats captures the token usage breakdown for a request. For every provider, InputTokens
+// includes CachedTokens and OutputTokens includes ReasoningTokens.
 type TokenStats struct {
 	InputTokens     int64 `json:"input_tokens"`
 	OutputTokens    int64 `json:"output_tokens"`
@@ -111,19 +122,34 @@ type StatisticsSnapshot struct {
 	SuccessCount  int64 `json:"success_count"`
 	FailureCount  int64 `json:"failure_count"`
 	TotalTokens   int64 `json:"total_tokens"`
+	// TotalCost is the USD spend priced with the current pricing table.
+	TotalCost float64 `json:"total_cost"`
 
 	APIs map[string]APISnapshot `json:"apis"`
+	// Credentials summarises traffic per upstream credential, keyed by auth index.
+	Credentials map[string]CredentialSnapshot `json:"credentials"`
+
+	RequestsByDay  map[string]int64   `json:"requests_by_day"`
+	RequestsByHour map[string]int64   `json:"requests_by_hour"`
+	TokensByDay    map[string]int64   `json:"tokens_by_day"`
+	TokensByHour   map[string]int64   `json:"tokens_by_hour"`
+	CostByDay      map[string]float64 `json:"cost_by_day"`
+
+	// ResponseCache reports lookups against the response cache for non-streaming requests.
+	ResponseCache ResponseCacheSnapshot `json:"response_cache"`
+}
 
-	RequestsByDay  map[string]int64 `json:"requests_by_day"`
-	RequestsByHour map[string]int64 `json:"requests_by_hour"`
-	TokensByDay    map[string]int64 `json:"tokens_by_day"`
-	TokensByHour   map[string]int64 `json:"tokens_by_hour"`
+// ResponseCacheSnapshot summarises response cache effectiveness.
+type ResponseCacheSnapshot struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
 }
 
 // APISnapshot summarises metrics for a single API key.
 type APISnapshot struct {
 	TotalRequests int64                    `json:"total_requests"`
 	TotalTokens   int64                    `json:"total_tokens"`
+	TotalCost     float64                  `json:"total_cost"`
 	Models        map[string]ModelSnapshot `json:"models"`
 }
 
@@ -131,9 +157,18 @@ type APISnapshot struct {
 type ModelSnapshot struct {
 	TotalRequests int64           `json:"total_requests"`
 	TotalTokens   int64           `json:"total_tokens"`
+	TotalCost     float64         `json:"total_cost"`
 	Details       []RequestDetail `json:"details"`
 }
 
+// CredentialSnapshot summarises metrics for a single upstream credential.
+type CredentialSnapshot struct {
+	Source        string  `json:"source"`
+	TotalRequests int64   `json:"total_requests"`
+	TotalTokens   int64   `json:"total_tokens"`
+	TotalCost     float64 `json:"total_cost"`
+}
+
 var defaultRequestStatistics = NewRequestStatistics()
 
 // GetRequestStatistics returns the shared statistics store.
@@ -162,7 +197,7 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 	if timestamp.IsZero() {
 		timestamp = time.Now()
 	}
-	detail := normaliseDetail(record.Detail)
+	detail := normaliseDetail(record.Provider, record.Detail)
 	totalTokens := detail.TotalTokens
 	statsKey := record.APIKey
 	if statsKey == "" {
@@ -179,6 +214,14 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 	}
 	dayKey := timestamp.Format("2006-01-02")
 	hourKey := timestamp.Hour()
+	requestDetail := RequestDetail{
+		Timestamp: timestamp,
+		Source:    record.Source,
+		AuthIndex: record.AuthIndex,
+		Tokens:    detail,
+		Failed:    failed,
+	}
+	s.persist(ctx, StoredRecord{APIKey: statsKey, Model: modelName, RequestDetail: requestDetail})
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -196,13 +239,7 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 		stats = &apiStats{Models: make(map[string]*modelStats)}
 		s.apis[statsKey] = stats
 	}
-	s.updateAPIStats(stats, modelName, RequestDetail{
-		Timestamp: timestamp,
-		Source:    record.Source,
-		AuthIndex: record.AuthIndex,
-		Tokens:    detail,
-		Failed:    failed,
-	})
+	s.updateAPIStats(stats, modelName, requestDetail)
 
 	s.requestsByDay[dayKey]++
 	s.requestsByHour[hourKey]++
@@ -210,6 +247,18 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 	s.tokensByHour[hourKey] += totalTokens
 }
 
+// RecordCacheLookup counts a response cache hit or miss.
+func (s *RequestStatistics) RecordCacheLookup(hit bool) {
+	if s == nil || !statisticsEnabled.Load() {
+		return
+	}
+	if hit {
+		s.cacheHits.Add(1)
+	} else {
+		s.cacheMisses.Add(1)
+	}
+}
+
 func (s *RequestStatistics) updateAPIStats(stats *apiStats, model string, detail RequestDetail) {
 	stats.TotalRequests++
 	stats.TotalTokens += detail.Tokens.TotalTokens
@@ -237,8 +286,12 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 	result.SuccessCount = s.successCount
 	result.FailureCount = s.failureCount
 	result.TotalTokens = s.totalTokens
+	result.ResponseCache = ResponseCacheSnapshot{Hits: s.cacheHits.Load(), Misses: s.cacheMisses.Load()}
 
+	pricing := Pricing()
 	result.APIs = make(map[string]APISnapshot, len(s.apis))
+	result.Credentials = make(map[string]CredentialSnapshot)
+	result.CostByDay = make(map[string]float64)
 	for apiName, stats := range s.apis {
 		apiSnapshot := APISnapshot{
 			TotalRequests: stats.TotalRequests,
@@ -248,12 +301,31 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 		for modelName, modelStatsValue := range stats.Models {
 			requestDetails := make([]RequestDetail, len(modelStatsValue.Details))
 			copy(requestDetails, modelStatsValue.Details)
+			var modelCost float64
+			for _, detail := range requestDetails {
+				cost := pricing.Cost(modelName, detail.Tokens)
+				modelCost += cost
+				result.CostByDay[detail.Timestamp.Format("2006-01-02")] += cost
+				if detail.AuthIndex != "" {
+					credential := result.Credentials[detail.AuthIndex]
+					if credential.Source == "" {
+						credential.Source = detail.Source
+					}
+					credential.TotalRequests++
+					credential.TotalTokens += detail.Tokens.TotalTokens
+					credential.TotalCost += cost
+					result.Credentials[detail.AuthIndex] = credential
+				}
+			}
+			apiSnapshot.TotalCost += modelCost
 			apiSnapshot.Models[modelName] = ModelSnapshot{
 				TotalRequests: modelStatsValue.TotalRequests,
 				TotalTokens:   modelStatsValue.TotalTokens,
+				TotalCost:     modelCost,
 				Details:       requestDetails,
 			}
 		}
+		result.TotalCost += apiSnapshot.TotalCost
 		result.APIs[apiName] = apiSnapshot
 	}
 
@@ -290,9 +362,18 @@ type MergeResult struct {
 // MergeSnapshot merges an exported statistics snapshot into the current store.
 // Existing data is preserved and duplicate request details are skipped.
 func (s *RequestStatistics) MergeSnapshot(snapshot StatisticsSnapshot) MergeResult {
+	result, added := s.mergeSnapshot(snapshot)
+	if len(added) > 0 {
+		s.persist(context.Background(), added...)
+	}
+	return result
+}
+
+func (s *RequestStatistics) mergeSnapshot(snapshot StatisticsSnapshot) (MergeResult, []StoredRecord) {
 	result := MergeResult{}
+	var added []StoredRecord
 	if s == nil {
-		return result
+		return result, added
 	}
 
 	s.mu.Lock()
@@ -342,12 +423,125 @@ func (s *RequestStatistics) MergeSnapshot(snapshot StatisticsSnapshot) MergeResu
 				}
 				seen[key] = struct{}{}
 				s.recordImported(apiName, modelName, stats, detail)
+				added = append(added, StoredRecord{APIKey: apiName, Model: modelName, RequestDetail: detail})
 				result.Added++
 			}
 		}
 	}
 
-	return result
+	return result, added
+}
+
+// AttachStore loads previously persisted records into memory and persists every subsequent record to store.
+// Records already present in memory are not duplicated. It returns the number of records loaded.
+func (s *RequestStatistics) AttachStore(ctx context.Context, store Store) (int64, error) {
+	if s == nil || store == nil {
+		return 0, nil
+	}
+	records, err := store.Query(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		return 0, err
+	}
+	result, _ := s.mergeSnapshot(snapshotFromRecords(records))
+
+	s.storeMu.Lock()
+	previous := s.store
+	s.store = store
+	s.storeMu.Unlock()
+	if previous != nil && previous != store {
+		_ = previous.Close()
+	}
+	return result.Added, nil
+}
+
+// DetachStore stops persisting records and closes the current store.
+func (s *RequestStatistics) DetachStore() error {
+	if s == nil {
+		return nil
+	}
+	s.storeMu.Lock()
+	previous := s.store
+	s.store = nil
+	s.storeMu.Unlock()
+	if previous == nil {
+		return nil
+	}
+	return previous.Close()
+}
+
+// SnapshotRange returns statistics for requests within [from, to). Zero bounds are open.
+// When a durable store is attached it is queried, so the range may reach beyond the current process lifetime.
+func (s *RequestStatistics) SnapshotRange(ctx context.Context, from, to time.Time) (StatisticsSnapshot, error) {
+	if s == nil {
+		return StatisticsSnapshot{}, nil
+	}
+	s.storeMu.RLock()
+	store := s.store
+	s.storeMu.RUnlock()
+	if store != nil {
+		records, err := store.Query(ctx, from, to)
+		if err != nil {
+			return StatisticsSnapshot{}, err
+		}
+		return snapshotFromRecords(records), nil
+	}
+
+	s.mu.RLock()
+	var records []StoredRecord
+	for apiName, stats := range s.apis {
+		for modelName, modelStatsValue := range stats.Models {
+			for _, detail := range modelStatsValue.Details {
+				if inRange(detail.Timestamp, from, to) {
+					records = append(records, StoredRecord{APIKey: apiName, Model: modelName, RequestDetail: detail})
+				}
+			}
+		}
+	}
+	s.mu.RUnlock()
+	return snapshotFromRecords(records), nil
+}
+
+func (s *RequestStatistics) persist(ctx context.Context, records ...StoredRecord) {
+	s.storeMu.RLock()
+	store := s.store
+	s.storeMu.RUnlock()
+	if store == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Records are usually emitted when the request finishes, so the write must not be
+	// cancelled together with the request.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), persistTimeout)
+	defer cancel()
+	if err := store.Append(ctx, records...); err != nil {
+		log.Errorf("usage: failed to persist %d record(s): %v", len(records), err)
+	}
+}
+
+// snapshotFromRecords aggregates stored records into a snapshot with the same shape as Snapshot.
+func snapshotFromRecords(records []StoredRecord) StatisticsSnapshot {
+	stats := NewRequestStatistics()
+	for _, record := range records {
+		apiName := strings.TrimSpace(record.APIKey)
+		if apiName == "" {
+			apiName = "unknown"
+		}
+		modelName := strings.TrimSpace(record.Model)
+		if modelName == "" {
+			modelName = "unknown"
+		}
+		api, ok := stats.apis[apiName]
+		if !ok {
+			api = &apiStats{Models: make(map[string]*modelStats)}
+			stats.apis[apiName] = api
+		}
+		detail := record.RequestDetail
+		detail.Tokens = normaliseTokenStats(detail.Tokens)
+		stats.recordImported(apiName, modelName, api, detail)
+	}
+	return stats.Snapshot()
 }
 
 func (s *RequestStatistics) recordImported(apiName, modelName string, stats *apiStats, detail RequestDetail) {
@@ -436,7 +630,10 @@ func resolveSuccess(ctx context.Context) bool {
 
 const httpStatusBadRequest = 400
 
-func normaliseDetail(detail coreusage.Detail) TokenStats {
+// normaliseDetail converts a provider usage report to TokenStats. Claude reports cache reads
+// apart from input tokens and the Gemini family reports thoughts apart from output tokens, so
+// those are folded in to match the OpenAI convention the other providers use.
+func normaliseDetail(provider string, detail coreusage.Detail) TokenStats {
 	tokens := TokenStats{
 		InputTokens:     detail.InputTokens,
 		OutputTokens:    detail.OutputTokens,
@@ -450,6 +647,12 @@ func normaliseDetail(detail coreusage.Detail) TokenStats {
 	if tokens.TotalTokens == 0 {
 		tokens.TotalTokens = detail.InputTokens + detail.OutputTokens + detail.ReasoningTokens + detail.CachedTokens
 	}
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "claude":
+		tokens.InputTokens += tokens.CachedTokens
+	case "gemini", "gemini-cli", "vertex", "aistudio", "antigravity":
+		tokens.OutputTokens += tokens.ReasoningTokens
+	}
 	return tokens
 }
 
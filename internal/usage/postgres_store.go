@@ -0,0 +1,152 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/store"
+)
+
+const defaultUsageTable = "usage_records"
+
+// PostgresStore persists usage records in PostgreSQL using a connection shared with the
+// Postgres-backed token store.
+type PostgresStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewPostgresStore prepares the usage table on an existing connection. The caller retains
+// ownership of db; Close does not close it.
+func NewPostgresStore(ctx context.Context, db *sql.DB, schema string) (*PostgresStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("usage postgres store: database connection is required")
+	}
+	table := store.QuoteIdentifier(defaultUsageTable)
+	if schema = strings.TrimSpace(schema); schema != "" {
+		table = store.QuoteIdentifier(schema) + "." + table
+	}
+	ps := &PostgresStore{db: db, table: table}
+	if err := ps.ensureSchema(ctx); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+func (s *PostgresStore) ensureSchema(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGSERIAL PRIMARY KEY,
+			api_key TEXT NOT NULL,
+			model TEXT NOT NULL,
+			requested_at TIMESTAMPTZ NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			auth_index TEXT NOT NULL DEFAULT '',
+			failed BOOLEAN NOT NULL DEFAULT FALSE,
+			input_tokens BIGINT NOT NULL DEFAULT 0,
+			output_tokens BIGINT NOT NULL DEFAULT 0,
+			reasoning_tokens BIGINT NOT NULL DEFAULT 0,
+			cached_tokens BIGINT NOT NULL DEFAULT 0,
+			total_tokens BIGINT NOT NULL DEFAULT 0
+		)
+	`, s.table)); err != nil {
+		return fmt.Errorf("usage postgres store: create table: %w", err)
+	}
+	index := store.QuoteIdentifier(defaultUsageTable + "_requested_at_idx")
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (requested_at)", index, s.table)); err != nil {
+		return fmt.Errorf("usage postgres store: create index: %w", err)
+	}
+	return nil
+}
+
+// Append implements Store.
+func (s *PostgresStore) Append(ctx context.Context, records ...StoredRecord) error {
+	if s == nil || len(records) == 0 {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("usage postgres store: begin transaction: %w", err)
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (api_key, model, requested_at, source, auth_index, failed,
+			input_tokens, output_tokens, reasoning_tokens, cached_tokens, total_tokens)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, s.table)
+	for _, record := range records {
+		tokens := record.Tokens
+		if _, err = tx.ExecContext(ctx, query,
+			record.APIKey, record.Model, record.Timestamp.UTC(), record.Source, record.AuthIndex, record.Failed,
+			tokens.InputTokens, tokens.OutputTokens, tokens.ReasoningTokens, tokens.CachedTokens, tokens.TotalTokens,
+		); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("usage postgres store: insert record: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("usage postgres store: commit: %w", err)
+	}
+	return nil
+}
+
+// Query implements Store.
+func (s *PostgresStore) Query(ctx context.Context, from, to time.Time) ([]StoredRecord, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var (
+		conditions []string
+		args       []any
+	)
+	if !from.IsZero() {
+		args = append(args, from.UTC())
+		conditions = append(conditions, fmt.Sprintf("requested_at >= $%d", len(args)))
+	}
+	if !to.IsZero() {
+		args = append(args, to.UTC())
+		conditions = append(conditions, fmt.Sprintf("requested_at < $%d", len(args)))
+	}
+	query := fmt.Sprintf(`
+		SELECT api_key, model, requested_at, source, auth_index, failed,
+			input_tokens, output_tokens, reasoning_tokens, cached_tokens, total_tokens
+		FROM %s`, s.table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY requested_at, id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("usage postgres store: query records: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var records []StoredRecord
+	for rows.Next() {
+		var record StoredRecord
+		if err = rows.Scan(
+			&record.APIKey, &record.Model, &record.Timestamp, &record.Source, &record.AuthIndex, &record.Failed,
+			&record.Tokens.InputTokens, &record.Tokens.OutputTokens, &record.Tokens.ReasoningTokens,
+			&record.Tokens.CachedTokens, &record.Tokens.TotalTokens,
+		); err != nil {
+			return nil, fmt.Errorf("usage postgres store: scan record: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("usage postgres store: iterate records: %w", err)
+	}
+	return records, nil
+}
+
+// Close implements Store. The shared connection is owned by the token store and left open.
+func (s *PostgresStore) Close() error { return nil }
@@ -0,0 +1,121 @@
+package usage
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+// tokensPerPriceUnit is the token count prices are quoted for.
+const tokensPerPriceUnit = 1_000_000
+
+var currentPriceTable atomic.Pointer[PriceTable]
+
+// SetPricing replaces the active price table used for spend reporting and cost budgets.
+func SetPricing(prices []config.ModelPrice) { currentPriceTable.Store(NewPriceTable(prices)) }
+
+// Pricing returns the active price table. It is never nil.
+func Pricing() *PriceTable {
+	if table := currentPriceTable.Load(); table != nil {
+		return table
+	}
+	return &PriceTable{}
+}
+
+// CostOf prices a usage record with the active price table.
+func CostOf(record coreusage.Record) float64 {
+	return Pricing().Cost(record.Model, normaliseDetail(record.Provider, record.Detail))
+}
+
+// PriceTable resolves per-model token prices.
+type PriceTable struct {
+	exact    map[string]modelRate
+	patterns []patternRate
+}
+
+type modelRate struct {
+	input       float64
+	output      float64
+	cachedInput float64
+	reasoning   float64
+}
+
+type patternRate struct {
+	pattern string
+	rate    modelRate
+}
+
+// NewPriceTable builds a price table from configuration entries.
+func NewPriceTable(prices []config.ModelPrice) *PriceTable {
+	table := &PriceTable{exact: make(map[string]modelRate)}
+	for _, price := range prices {
+		name := strings.TrimSpace(price.Model)
+		if name == "" {
+			continue
+		}
+		rate := modelRate{
+			input:       max(price.Input, 0),
+			output:      max(price.Output, 0),
+			cachedInput: max(price.Input, 0),
+			reasoning:   max(price.Output, 0),
+		}
+		if price.CachedInput != nil {
+			rate.cachedInput = max(*price.CachedInput, 0)
+		}
+		if price.Reasoning != nil {
+			rate.reasoning = max(*price.Reasoning, 0)
+		}
+		if strings.Contains(name, "*") {
+			table.patterns = append(table.patterns, patternRate{pattern: name, rate: rate})
+			continue
+		}
+		if _, exists := table.exact[name]; !exists {
+			table.exact[name] = rate
+		}
+	}
+	return table
+}
+
+func (t *PriceTable) rateFor(model string) (modelRate, bool) {
+	if t == nil {
+		return modelRate{}, false
+	}
+	model = strings.TrimSpace(model)
+	if rate, ok := t.exact[model]; ok {
+		return rate, true
+	}
+	for _, entry := range t.patterns {
+		if util.MatchModelPattern(entry.pattern, model) {
+			return entry.rate, true
+		}
+	}
+	return modelRate{}, false
+}
+
+// Priced reports whether model has a configured price.
+func (t *PriceTable) Priced(model string) bool {
+	_, ok := t.rateFor(model)
+	return ok
+}
+
+// Cost returns the USD cost of tokens for model, or 0 when the model has no price.
+// tokens follow the TokenStats convention: cached tokens are the part of the input billed at the
+// cached rate and reasoning tokens the part of the output billed at the reasoning rate.
+func (t *PriceTable) Cost(model string, tokens TokenStats) float64 {
+	rate, ok := t.rateFor(model)
+	if !ok {
+		return 0
+	}
+	input := max(tokens.InputTokens, 0)
+	output := max(tokens.OutputTokens, 0)
+	cached := min(max(tokens.CachedTokens, 0), input)
+	reasoning := min(max(tokens.ReasoningTokens, 0), output)
+	cost := float64(input-cached)*rate.input +
+		float64(cached)*rate.cachedInput +
+		float64(output-reasoning)*rate.output +
+		float64(reasoning)*rate.reasoning
+	return cost / tokensPerPriceUnit
+}
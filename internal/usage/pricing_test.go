@@ -0,0 +1,105 @@
+package usage
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestPriceTableMatching(t *testing.T) {
+	zero := 0.0
+	cached := 0.5
+	table := NewPriceTable([]config.ModelPrice{
+		{Model: "gemini-*", Input: 1, Output: 2},
+		{Model: "gemini-2.5-pro", Input: 2, Output: 8, CachedInput: &cached},
+		{Model: "gpt-*", Input: 1, Output: 10},
+		{Model: "o*", Input: 1, Output: 10, Reasoning: &zero},
+	})
+
+	tokens := TokenStats{InputTokens: 1_000_000, CachedTokens: 400_000, OutputTokens: 500_000, ReasoningTokens: 100_000}
+	cases := []struct {
+		model string
+		want  float64
+	}{
+		// exact entry wins over the earlier pattern: 0.6*2 + 0.4*0.5 + 0.4*8 + 0.1*8
+		{model: "gemini-2.5-pro", want: 5.4},
+		// pattern entry, cached falls back to input and reasoning to output: 1 + 0.5*2
+		{model: "gemini-2.5-flash", want: 2},
+		// reasoning is part of the output and not billed twice: 1 + 0.5*10
+		{model: "gpt-5", want: 6},
+		// reasoning explicitly free: 1 + 0.4*10
+		{model: "o3", want: 5},
+		{model: "claude-sonnet-4", want: 0},
+	}
+	for _, tc := range cases {
+		if got := table.Cost(tc.model, tokens); math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("Cost(%q) = %v, want %v", tc.model, got, tc.want)
+		}
+	}
+	if table.Priced("claude-sonnet-4") {
+		t.Fatalf("Priced(claude-sonnet-4) = true, want false")
+	}
+}
+
+func TestCostOfNormalisesProviderUsage(t *testing.T) {
+	cached := 0.3
+	SetPricing([]config.ModelPrice{
+		{Model: "claude-*", Input: 3, Output: 15, CachedInput: &cached},
+		{Model: "gemini-*", Input: 1, Output: 2},
+		{Model: "gpt-*", Input: 1, Output: 10},
+	})
+	defer SetPricing(nil)
+
+	cases := []struct {
+		name   string
+		record coreusage.Record
+		want   float64
+	}{
+		{
+			// cache reads are reported apart from input: 2*3 + 3*0.3 + 1*15
+			name:   "claude cache reads",
+			record: coreusage.Record{Provider: "claude", Model: "claude-sonnet-4", Detail: coreusage.Detail{InputTokens: 2_000_000, CachedTokens: 3_000_000, OutputTokens: 1_000_000}},
+			want:   21.9,
+		},
+		{
+			// thoughts are reported apart from output: 1*2 + 1*2
+			name:   "gemini thoughts",
+			record: coreusage.Record{Provider: "gemini-cli", Model: "gemini-2.5-pro", Detail: coreusage.Detail{OutputTokens: 1_000_000, ReasoningTokens: 1_000_000}},
+			want:   4,
+		},
+		{
+			// reasoning is already part of the output: 1*10
+			name:   "codex reasoning",
+			record: coreusage.Record{Provider: "codex", Model: "gpt-5", Detail: coreusage.Detail{OutputTokens: 1_000_000, ReasoningTokens: 500_000}},
+			want:   10,
+		},
+	}
+	for _, tc := range cases {
+		if got := CostOf(tc.record); math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("%s: CostOf() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSnapshotReportsCost(t *testing.T) {
+	SetStatisticsEnabled(true)
+	SetPricing([]config.ModelPrice{{Model: "m*", Input: 1, Output: 1}})
+	defer SetPricing(nil)
+
+	stats := NewRequestStatistics()
+	ctx := context.Background()
+	stats.Record(ctx, coreusage.Record{APIKey: "k1", Model: "m1", AuthIndex: "a1", Source: "s1", Detail: coreusage.Detail{InputTokens: 1_000_000}})
+	stats.Record(ctx, coreusage.Record{APIKey: "k1", Model: "m1", AuthIndex: "a1", Source: "s1", Detail: coreusage.Detail{OutputTokens: 2_000_000}})
+
+	snapshot := stats.Snapshot()
+	if snapshot.TotalCost != 3 || snapshot.APIs["k1"].TotalCost != 3 || snapshot.APIs["k1"].Models["m1"].TotalCost != 3 {
+		t.Fatalf("costs = %v / %v / %v, want 3", snapshot.TotalCost, snapshot.APIs["k1"].TotalCost, snapshot.APIs["k1"].Models["m1"].TotalCost)
+	}
+	credential := snapshot.Credentials["a1"]
+	if credential.TotalRequests != 2 || credential.TotalCost != 3 || credential.Source != "s1" {
+		t.Fatalf("credential = %+v, want 2 requests, cost 3, source s1", credential)
+	}
+}
@@ -0,0 +1,174 @@
+package usage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStoreFileName is the file used by the file-backed usage store when no path is configured.
+const DefaultStoreFileName = "usage.jsonl"
+
+// StoredRecord is the durable representation of a single aggregated request.
+type StoredRecord struct {
+	APIKey string `json:"api_key"`
+	Model  string `json:"model"`
+	RequestDetail
+}
+
+// Store persists usage records so statistics survive restarts.
+type Store interface {
+	// Append durably stores the records.
+	Append(ctx context.Context, records ...StoredRecord) error
+	// Query returns records whose timestamp falls within [from, to). Zero bounds are open.
+	Query(ctx context.Context, from, to time.Time) ([]StoredRecord, error)
+	// Close releases resources held by the store.
+	Close() error
+}
+
+// ResolveStorePath returns the file store location. Relative paths are resolved against baseDir;
+// an empty path selects <baseDir>/usage/usage.jsonl.
+func ResolveStorePath(path, baseDir string) string {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return filepath.Join(baseDir, "usage", DefaultStoreFileName)
+	}
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// FileStore persists usage records as append-only JSON lines.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileStore opens (or creates) the JSON-lines usage file at path.
+func NewFileStore(path string) (*FileStore, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("usage file store: path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("usage file store: create directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("usage file store: open file: %w", err)
+	}
+	return &FileStore{path: path, file: file}, nil
+}
+
+// Path returns the backing file path.
+func (s *FileStore) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// Append implements Store.
+func (s *FileStore) Append(_ context.Context, records ...StoredRecord) error {
+	if s == nil || len(records) == 0 {
+		return nil
+	}
+	var buf []byte
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("usage file store: encode record: %w", err)
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return fmt.Errorf("usage file store: closed")
+	}
+	if _, err := s.file.Write(buf); err != nil {
+		return fmt.Errorf("usage file store: write records: %w", err)
+	}
+	return nil
+}
+
+// Query implements Store.
+func (s *FileStore) Query(ctx context.Context, from, to time.Time) ([]StoredRecord, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("usage file store: open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var records []StoredRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if ctx != nil {
+			if errCtx := ctx.Err(); errCtx != nil {
+				return nil, errCtx
+			}
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record StoredRecord
+		if errUnmarshal := json.Unmarshal(line, &record); errUnmarshal != nil {
+			// Skip partially written lines rather than failing the whole query.
+			continue
+		}
+		if !inRange(record.Timestamp, from, to) {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("usage file store: read file: %w", err)
+	}
+	return records, nil
+}
+
+// Close implements Store.
+func (s *FileStore) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func inRange(ts, from, to time.Time) bool {
+	if !from.IsZero() && ts.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !ts.Before(to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package usage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestFileStorePersistsAcrossRestart(t *testing.T) {
+	SetStatisticsEnabled(true)
+	path := filepath.Join(t.TempDir(), "usage", DefaultStoreFileName)
+	ctx := context.Background()
+	day1 := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	stats := NewRequestStatistics()
+	if _, err = stats.AttachStore(ctx, store); err != nil {
+		t.Fatalf("AttachStore() error = %v", err)
+	}
+	stats.Record(ctx, coreusage.Record{APIKey: "k1", Model: "m1", RequestedAt: day1, Detail: coreusage.Detail{InputTokens: 3, OutputTokens: 4}})
+	stats.Record(ctx, coreusage.Record{APIKey: "k1", Model: "m1", RequestedAt: day2, Detail: coreusage.Detail{TotalTokens: 10}})
+	if err = stats.DetachStore(); err != nil {
+		t.Fatalf("DetachStore() error = %v", err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() reopen error = %v", err)
+	}
+	restored := NewRequestStatistics()
+	loaded, err := restored.AttachStore(ctx, reopened)
+	if err != nil {
+		t.Fatalf("AttachStore() reopen error = %v", err)
+	}
+	defer func() { _ = restored.DetachStore() }()
+	if loaded != 2 {
+		t.Fatalf("loaded = %d, want 2", loaded)
+	}
+	if snapshot := restored.Snapshot(); snapshot.TotalRequests != 2 || snapshot.TotalTokens != 17 {
+		t.Fatalf("snapshot totals = %d requests / %d tokens, want 2 / 17", snapshot.TotalRequests, snapshot.TotalTokens)
+	}
+
+	ranged, err := restored.SnapshotRange(ctx, day2.Truncate(24*time.Hour), time.Time{})
+	if err != nil {
+		t.Fatalf("SnapshotRange() error = %v", err)
+	}
+	if ranged.TotalRequests != 1 || ranged.TotalTokens != 10 {
+		t.Fatalf("ranged totals = %d requests / %d tokens, want 1 / 10", ranged.TotalRequests, ranged.TotalTokens)
+	}
+	if got := ranged.APIs["k1"].Models["m1"].TotalRequests; got != 1 {
+		t.Fatalf("ranged model requests = %d, want 1", got)
+	}
+}
@@ -0,0 +1,26 @@
+package util
+
+import "testing"
+
+func TestMatchModelPattern(t *testing.T) {
+	cases := []struct {
+		pattern, model string
+		want           bool
+	}{
+		{"*", "gpt-5", true},
+		{"gpt-5", "gpt-5", true},
+		{"gpt-*", "gpt-5", true},
+		{"*-5", "gpt-5", true},
+		{"gemini-*-pro", "gemini-2.5-pro", true},
+		{"gemini-*-pro", "gemini-2.5-flash", false},
+		{"a*b*c", "abxbc", true},
+		{"gpt-*", "claude-sonnet-4", false},
+		{"GPT-*", "gpt-5", false},
+		{"", "gpt-5", false},
+	}
+	for _, tc := range cases {
+		if got := MatchModelPattern(tc.pattern, tc.model); got != tc.want {
+			t.Fatalf("MatchModelPattern(%q, %q) = %t, want %t", tc.pattern, tc.model, got, tc.want)
+		}
+	}
+}
@@ -94,6 +94,10 @@ func GetModelThinkingLevels(model string) []string {
 		return nil
 	}
 	info := registry.GetGlobalRegistry().GetModelInfo(model)
+	if info == nil {
+		// Fallback: static definitions with the operator model catalog applied
+		info = registry.LookupStaticModelInfo(model)
+	}
 	if info == nil || info.Thinking == nil {
 		return nil
 	}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -74,7 +75,7 @@ func CountAuthFiles(authDir string) int {
 		if d.IsDir() {
 			return nil
 		}
-		if strings.HasSuffix(strings.ToLower(d.Name()), ".json") {
+		if strings.HasSuffix(strings.ToLower(d.Name()), ".json") && !misc.IsAuthDirStateFile(path) {
 			count++
 		}
 		return nil
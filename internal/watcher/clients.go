@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
@@ -79,7 +80,7 @@ func (w *Watcher) reloadClients(rescanAuth bool, affectedOAuthProviders []string
 				if err != nil {
 					return nil
 				}
-				if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".json") {
+				if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".json") && !misc.IsAuthDirStateFile(path) {
 					if data, errReadFile := os.ReadFile(path); errReadFile == nil && len(data) > 0 {
 						sum := sha256.Sum256(data)
 						normalizedPath := w.normalizeAuthPath(path)
@@ -190,7 +191,7 @@ func (w *Watcher) loadFileClients(cfg *config.Config) int {
 			log.Debugf("error accessing path %s: %v", path, err)
 			return err
 		}
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".json") {
+		if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".json") && !misc.IsAuthDirStateFile(path) {
 			authFileCount++
 			log.Debugf("processing auth file %d: %s", authFileCount, filepath.Base(path))
 			if data, errCreate := os.ReadFile(path); errCreate == nil && len(data) > 0 {
@@ -23,6 +23,10 @@ func (w *Watcher) stopConfigReloadTimer() {
 		w.configReloadTimer.Stop()
 		w.configReloadTimer = nil
 	}
+	if w.modelCatalogTimer != nil {
+		w.modelCatalogTimer.Stop()
+		w.modelCatalogTimer = nil
+	}
 	w.configReloadMu.Unlock()
 }
 
@@ -128,6 +132,9 @@ func (w *Watcher) reloadConfig() bool {
 
 	authDirChanged := oldConfig == nil || oldConfig.AuthDir != newConfig.AuthDir
 	forceAuthRefresh := oldConfig != nil && (oldConfig.ForceModelPrefix != newConfig.ForceModelPrefix || !reflect.DeepEqual(oldConfig.OAuthModelMappings, newConfig.OAuthModelMappings))
+	if w.syncModelCatalog(newConfig) {
+		forceAuthRefresh = true
+	}
 
 	log.Infof("config successfully reloaded, triggering client reload")
 	w.reloadClients(authDirChanged, affectedOAuthProviders, forceAuthRefresh)
@@ -30,9 +30,53 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.LoggingToFile != newCfg.LoggingToFile {
 		changes = append(changes, fmt.Sprintf("logging-to-file: %t -> %t", oldCfg.LoggingToFile, newCfg.LoggingToFile))
 	}
+	if oldCfg.AccessLog != newCfg.AccessLog {
+		changes = append(changes, fmt.Sprintf("access-log: enable=%t max-size-mb=%d -> enable=%t max-size-mb=%d", oldCfg.AccessLog.Enable, oldCfg.AccessLog.MaxSizeMB, newCfg.AccessLog.Enable, newCfg.AccessLog.MaxSizeMB))
+	}
+	if !reflect.DeepEqual(oldCfg.RequestLogRedaction, newCfg.RequestLogRedaction) {
+		o, n := oldCfg.RequestLogRedaction, newCfg.RequestLogRedaction
+		changes = append(changes, fmt.Sprintf("request-log-redaction: prompts=%t tool-arguments=%t auth-headers=%t headers=%d fields=%d -> prompts=%t tool-arguments=%t auth-headers=%t headers=%d fields=%d",
+			o.Prompts, o.ToolArguments, o.AuthHeaders, len(o.Headers), len(o.Fields), n.Prompts, n.ToolArguments, n.AuthHeaders, len(n.Headers), len(n.Fields)))
+	}
+	if !reflect.DeepEqual(oldCfg.RequestLogSampling, newCfg.RequestLogSampling) {
+		changes = append(changes, fmt.Sprintf("request-log-sampling: rules=%d max-body-bytes=%d -> rules=%d max-body-bytes=%d", len(oldCfg.RequestLogSampling.Rules), oldCfg.RequestLogSampling.MaxBodyBytes, len(newCfg.RequestLogSampling.Rules), newCfg.RequestLogSampling.MaxBodyBytes))
+	}
 	if oldCfg.UsageStatisticsEnabled != newCfg.UsageStatisticsEnabled {
 		changes = append(changes, fmt.Sprintf("usage-statistics-enabled: %t -> %t", oldCfg.UsageStatisticsEnabled, newCfg.UsageStatisticsEnabled))
 	}
+	if oldCfg.UsagePersistence != newCfg.UsagePersistence {
+		changes = append(changes, fmt.Sprintf("usage-persistence: enable=%t path=%s -> enable=%t path=%s (restart required)", oldCfg.UsagePersistence.Enable, oldCfg.UsagePersistence.Path, newCfg.UsagePersistence.Enable, newCfg.UsagePersistence.Path))
+	}
+	if !reflect.DeepEqual(oldCfg.Pricing, newCfg.Pricing) {
+		changes = append(changes, fmt.Sprintf("pricing: updated (%d -> %d entries)", len(oldCfg.Pricing), len(newCfg.Pricing)))
+	}
+	if oldCfg.Metrics.Enable != newCfg.Metrics.Enable {
+		changes = append(changes, fmt.Sprintf("metrics.enable: %t -> %t", oldCfg.Metrics.Enable, newCfg.Metrics.Enable))
+	}
+	if oldCfg.Metrics.Path != newCfg.Metrics.Path {
+		changes = append(changes, fmt.Sprintf("metrics.path: %s -> %s", oldCfg.Metrics.Path, newCfg.Metrics.Path))
+	}
+	if oldCfg.Tracing.Enable != newCfg.Tracing.Enable {
+		changes = append(changes, fmt.Sprintf("tracing.enable: %t -> %t", oldCfg.Tracing.Enable, newCfg.Tracing.Enable))
+	}
+	if oldCfg.Tracing.Protocol != newCfg.Tracing.Protocol || oldCfg.Tracing.Endpoint != newCfg.Tracing.Endpoint {
+		changes = append(changes, fmt.Sprintf("tracing.endpoint: %s %s -> %s %s", oldCfg.Tracing.Protocol, oldCfg.Tracing.Endpoint, newCfg.Tracing.Protocol, newCfg.Tracing.Endpoint))
+	}
+	if oldCfg.Tracing.SampleRatio != newCfg.Tracing.SampleRatio {
+		changes = append(changes, fmt.Sprintf("tracing.sample-ratio: %g -> %g", oldCfg.Tracing.SampleRatio, newCfg.Tracing.SampleRatio))
+	}
+	if oldCfg.Tracing.ServiceName != newCfg.Tracing.ServiceName || oldCfg.Tracing.Insecure != newCfg.Tracing.Insecure || !reflect.DeepEqual(oldCfg.Tracing.Headers, newCfg.Tracing.Headers) {
+		changes = append(changes, "tracing: exporter settings updated (headers redacted)")
+	}
+	if oldCfg.Routing.Strategy != newCfg.Routing.Strategy {
+		changes = append(changes, fmt.Sprintf("routing.strategy: %s -> %s", oldCfg.Routing.Strategy, newCfg.Routing.Strategy))
+	}
+	if oldCfg.Routing.Affinity.Header != newCfg.Routing.Affinity.Header {
+		changes = append(changes, fmt.Sprintf("routing.affinity.header: %s -> %s", oldCfg.Routing.Affinity.Header, newCfg.Routing.Affinity.Header))
+	}
+	if oldCfg.Routing.Affinity.MaxEntries != newCfg.Routing.Affinity.MaxEntries {
+		changes = append(changes, fmt.Sprintf("routing.affinity.max-entries: %d -> %d", oldCfg.Routing.Affinity.MaxEntries, newCfg.Routing.Affinity.MaxEntries))
+	}
 	if oldCfg.DisableCooling != newCfg.DisableCooling {
 		changes = append(changes, fmt.Sprintf("disable-cooling: %t -> %t", oldCfg.DisableCooling, newCfg.DisableCooling))
 	}
@@ -69,6 +113,24 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	} else if !reflect.DeepEqual(trimStrings(oldCfg.APIKeys), trimStrings(newCfg.APIKeys)) {
 		changes = append(changes, "api-keys: values updated (count unchanged, redacted)")
 	}
+	if !reflect.DeepEqual(oldCfg.ClientKeys, newCfg.ClientKeys) {
+		changes = append(changes, fmt.Sprintf("client-keys: updated (%d -> %d entries, keys redacted)", len(oldCfg.ClientKeys), len(newCfg.ClientKeys)))
+	}
+	if !reflect.DeepEqual(oldCfg.APIKeyLimits, newCfg.APIKeyLimits) {
+		changes = append(changes, fmt.Sprintf("api-key-limits: updated (%d -> %d entries)", len(oldCfg.APIKeyLimits), len(newCfg.APIKeyLimits)))
+	}
+	if oldCfg.ResponseCache.Enabled != newCfg.ResponseCache.Enabled {
+		changes = append(changes, fmt.Sprintf("response-cache.enabled: %t -> %t", oldCfg.ResponseCache.Enabled, newCfg.ResponseCache.Enabled))
+	}
+	if oldCfg.ResponseCache.TTLSeconds != newCfg.ResponseCache.TTLSeconds {
+		changes = append(changes, fmt.Sprintf("response-cache.ttl-seconds: %d -> %d", oldCfg.ResponseCache.TTLSeconds, newCfg.ResponseCache.TTLSeconds))
+	}
+	if oldCfg.ResponseCache.MaxEntries != newCfg.ResponseCache.MaxEntries {
+		changes = append(changes, fmt.Sprintf("response-cache.max-entries: %d -> %d", oldCfg.ResponseCache.MaxEntries, newCfg.ResponseCache.MaxEntries))
+	}
+	if oldCfg.ResponseCache.MaxSizeMB != newCfg.ResponseCache.MaxSizeMB {
+		changes = append(changes, fmt.Sprintf("response-cache.max-size-mb: %d -> %d", oldCfg.ResponseCache.MaxSizeMB, newCfg.ResponseCache.MaxSizeMB))
+	}
 	if len(oldCfg.GeminiKey) != len(newCfg.GeminiKey) {
 		changes = append(changes, fmt.Sprintf("gemini-api-key count: %d -> %d", len(oldCfg.GeminiKey), len(newCfg.GeminiKey)))
 	} else {
@@ -84,6 +146,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if strings.TrimSpace(o.Prefix) != strings.TrimSpace(n.Prefix) {
 				changes = append(changes, fmt.Sprintf("gemini[%d].prefix: %s -> %s", i, strings.TrimSpace(o.Prefix), strings.TrimSpace(n.Prefix)))
 			}
+			if o.Weight != n.Weight {
+				changes = append(changes, fmt.Sprintf("gemini[%d].weight: %d -> %d", i, o.Weight, n.Weight))
+			}
 			if strings.TrimSpace(o.APIKey) != strings.TrimSpace(n.APIKey) {
 				changes = append(changes, fmt.Sprintf("gemini[%d].api-key: updated", i))
 			}
@@ -119,6 +184,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if strings.TrimSpace(o.Prefix) != strings.TrimSpace(n.Prefix) {
 				changes = append(changes, fmt.Sprintf("claude[%d].prefix: %s -> %s", i, strings.TrimSpace(o.Prefix), strings.TrimSpace(n.Prefix)))
 			}
+			if o.Weight != n.Weight {
+				changes = append(changes, fmt.Sprintf("claude[%d].weight: %d -> %d", i, o.Weight, n.Weight))
+			}
 			if strings.TrimSpace(o.APIKey) != strings.TrimSpace(n.APIKey) {
 				changes = append(changes, fmt.Sprintf("claude[%d].api-key: updated", i))
 			}
@@ -154,6 +222,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if strings.TrimSpace(o.Prefix) != strings.TrimSpace(n.Prefix) {
 				changes = append(changes, fmt.Sprintf("codex[%d].prefix: %s -> %s", i, strings.TrimSpace(o.Prefix), strings.TrimSpace(n.Prefix)))
 			}
+			if o.Weight != n.Weight {
+				changes = append(changes, fmt.Sprintf("codex[%d].weight: %d -> %d", i, o.Weight, n.Weight))
+			}
 			if strings.TrimSpace(o.APIKey) != strings.TrimSpace(n.APIKey) {
 				changes = append(changes, fmt.Sprintf("codex[%d].api-key: updated", i))
 			}
@@ -212,6 +283,45 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if entries, _ := DiffOAuthModelMappingChanges(oldCfg.OAuthModelMappings, newCfg.OAuthModelMappings); len(entries) > 0 {
 		changes = append(changes, entries...)
 	}
+	if oldCfg.HealthProbe.Enable != newCfg.HealthProbe.Enable {
+		changes = append(changes, fmt.Sprintf("health-probe.enable: %t -> %t", oldCfg.HealthProbe.Enable, newCfg.HealthProbe.Enable))
+	}
+	if oldCfg.HealthProbe.IntervalSeconds != newCfg.HealthProbe.IntervalSeconds {
+		changes = append(changes, fmt.Sprintf("health-probe.interval-seconds: %d -> %d", oldCfg.HealthProbe.IntervalSeconds, newCfg.HealthProbe.IntervalSeconds))
+	}
+	if oldCfg.HealthProbe.TimeoutSeconds != newCfg.HealthProbe.TimeoutSeconds {
+		changes = append(changes, fmt.Sprintf("health-probe.timeout-seconds: %d -> %d", oldCfg.HealthProbe.TimeoutSeconds, newCfg.HealthProbe.TimeoutSeconds))
+	}
+	if oldCfg.HealthProbe.Concurrency != newCfg.HealthProbe.Concurrency {
+		changes = append(changes, fmt.Sprintf("health-probe.concurrency: %d -> %d", oldCfg.HealthProbe.Concurrency, newCfg.HealthProbe.Concurrency))
+	}
+	if oldCfg.HealthProbe.Mode != newCfg.HealthProbe.Mode {
+		changes = append(changes, fmt.Sprintf("health-probe.mode: %s -> %s", oldCfg.HealthProbe.Mode, newCfg.HealthProbe.Mode))
+	}
+	if !reflect.DeepEqual(oldCfg.HealthProbe.Models, newCfg.HealthProbe.Models) {
+		changes = append(changes, fmt.Sprintf("health-probe.models: updated (%d -> %d entries)", len(oldCfg.HealthProbe.Models), len(newCfg.HealthProbe.Models)))
+	}
+	if oldCfg.ModelDiscovery.Enable != newCfg.ModelDiscovery.Enable {
+		changes = append(changes, fmt.Sprintf("model-discovery.enable: %t -> %t", oldCfg.ModelDiscovery.Enable, newCfg.ModelDiscovery.Enable))
+	}
+	if oldCfg.ModelDiscovery.IntervalSeconds != newCfg.ModelDiscovery.IntervalSeconds {
+		changes = append(changes, fmt.Sprintf("model-discovery.interval-seconds: %d -> %d", oldCfg.ModelDiscovery.IntervalSeconds, newCfg.ModelDiscovery.IntervalSeconds))
+	}
+	if !reflect.DeepEqual(oldCfg.ModelDiscovery.Providers, newCfg.ModelDiscovery.Providers) || !reflect.DeepEqual(oldCfg.ModelDiscovery.Include, newCfg.ModelDiscovery.Include) || !reflect.DeepEqual(oldCfg.ModelDiscovery.Exclude, newCfg.ModelDiscovery.Exclude) {
+		changes = append(changes, "model-discovery: provider or model filters updated")
+	}
+	if oldCfg.ModelCatalog != newCfg.ModelCatalog {
+		changes = append(changes, fmt.Sprintf("model-catalog: %s -> %s", oldCfg.ModelCatalog, newCfg.ModelCatalog))
+	}
+	if oldCfg.Notifications.RefreshFailureThreshold != newCfg.Notifications.RefreshFailureThreshold {
+		changes = append(changes, fmt.Sprintf("notifications.refresh-failure-threshold: %d -> %d", oldCfg.Notifications.RefreshFailureThreshold, newCfg.Notifications.RefreshFailureThreshold))
+	}
+	if !reflect.DeepEqual(oldCfg.Notifications.Webhooks, newCfg.Notifications.Webhooks) {
+		changes = append(changes, fmt.Sprintf("notifications.webhooks: updated (%d -> %d entries, secrets redacted)", len(oldCfg.Notifications.Webhooks), len(newCfg.Notifications.Webhooks)))
+	}
+	if !reflect.DeepEqual(oldCfg.ModelFallbacks, newCfg.ModelFallbacks) {
+		changes = append(changes, fmt.Sprintf("model-fallbacks: updated (%d -> %d chains)", len(oldCfg.ModelFallbacks), len(newCfg.ModelFallbacks)))
+	}
 
 	// Remote management (never print the key)
 	if oldCfg.RemoteManagement.AllowRemote != newCfg.RemoteManagement.AllowRemote {
@@ -235,6 +345,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			changes = append(changes, "remote-management.secret-key: updated")
 		}
 	}
+	if !reflect.DeepEqual(oldCfg.RemoteManagement.Tokens, newCfg.RemoteManagement.Tokens) {
+		changes = append(changes, fmt.Sprintf("remote-management.tokens: updated (%d -> %d entries, redacted)", len(oldCfg.RemoteManagement.Tokens), len(newCfg.RemoteManagement.Tokens)))
+	}
 
 	// OpenAI compatibility providers (summarized)
 	if compat := DiffOpenAICompatibility(oldCfg.OpenAICompatibility, newCfg.OpenAICompatibility); len(compat) > 0 {
@@ -260,6 +373,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if strings.TrimSpace(o.Prefix) != strings.TrimSpace(n.Prefix) {
 				changes = append(changes, fmt.Sprintf("vertex[%d].prefix: %s -> %s", i, strings.TrimSpace(o.Prefix), strings.TrimSpace(n.Prefix)))
 			}
+			if o.Weight != n.Weight {
+				changes = append(changes, fmt.Sprintf("vertex[%d].weight: %d -> %d", i, o.Weight, n.Weight))
+			}
 			if strings.TrimSpace(o.APIKey) != strings.TrimSpace(n.APIKey) {
 				changes = append(changes, fmt.Sprintf("vertex[%d].api-key: updated", i))
 			}
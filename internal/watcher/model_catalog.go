@@ -0,0 +1,150 @@
+// model_catalog.go implements loading and hot reload of the operator model catalog file.
+// A changed catalog forces an auth refresh so registered models pick up the new metadata.
+package watcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	log "github.com/sirupsen/logrus"
+)
+
+// resolveModelCatalogPath returns the catalog path configured in cfg, resolving relative paths
+// against the config file directory.
+func (w *Watcher) resolveModelCatalogPath(cfg *config.Config) string {
+	if cfg == nil {
+		return ""
+	}
+	path := strings.TrimSpace(cfg.ModelCatalog)
+	if path == "" {
+		return ""
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(w.configPath), path)
+	}
+	return filepath.Clean(path)
+}
+
+// syncModelCatalog watches and loads the catalog configured in cfg and reports whether the
+// active catalog changed.
+func (w *Watcher) syncModelCatalog(cfg *config.Config) bool {
+	path := w.resolveModelCatalogPath(cfg)
+	w.clientsMutex.Lock()
+	previous := w.modelCatalogPath
+	w.modelCatalogPath = path
+	w.clientsMutex.Unlock()
+
+	// The parent directory is watched rather than the file so a catalog created after startup, or
+	// replaced by an editor, is still picked up; events are filtered by name in handleEvent.
+	previousDir, dir := modelCatalogDir(previous), modelCatalogDir(path)
+	if previousDir != dir {
+		if previousDir != "" && !w.isSharedWatchDir(previousDir) {
+			_ = w.watcher.Remove(previousDir)
+		}
+		if dir != "" && !w.isSharedWatchDir(dir) {
+			if errAdd := w.watcher.Add(dir); errAdd != nil {
+				log.Errorf("failed to watch model catalog directory %s: %v", dir, errAdd)
+			} else {
+				log.Debugf("watching model catalog directory: %s", dir)
+			}
+		}
+	}
+	return w.loadModelCatalog(path)
+}
+
+func modelCatalogDir(path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Dir(path)
+}
+
+// isSharedWatchDir reports whether dir is already watched for auth files, in which case the
+// catalog must neither add nor remove that watch.
+func (w *Watcher) isSharedWatchDir(dir string) bool {
+	return w.normalizeAuthPath(dir) == w.normalizeAuthPath(w.authDir)
+}
+
+// loadModelCatalog installs the catalog at path when its content changed and reports whether it
+// did. A file that cannot be read or parsed keeps the previous catalog active.
+func (w *Watcher) loadModelCatalog(path string) bool {
+	if path == "" {
+		w.clientsMutex.Lock()
+		hadCatalog := w.modelCatalogHash != ""
+		w.modelCatalogHash = ""
+		w.clientsMutex.Unlock()
+		if hadCatalog {
+			registry.SetModelCatalog(nil)
+			log.Info("model catalog cleared")
+		}
+		return hadCatalog
+	}
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		if os.IsNotExist(errRead) {
+			log.Warnf("model catalog %s does not exist yet; it is loaded once created", path)
+			return false
+		}
+		log.Errorf("failed to read model catalog %s: %v", path, errRead)
+		return false
+	}
+	if len(data) == 0 {
+		log.Debugf("ignoring empty model catalog file: %s", path)
+		return false
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	w.clientsMutex.RLock()
+	unchanged := hash == w.modelCatalogHash
+	w.clientsMutex.RUnlock()
+	if unchanged {
+		return false
+	}
+	catalog, errParse := registry.ParseModelCatalog(data)
+	if errParse != nil {
+		log.Errorf("failed to load model catalog %s: %v", path, errParse)
+		return false
+	}
+	registry.SetModelCatalog(catalog)
+	w.clientsMutex.Lock()
+	w.modelCatalogHash = hash
+	w.clientsMutex.Unlock()
+	log.Infof("model catalog loaded from %s (%d entries)", path, len(catalog.Models))
+	return true
+}
+
+func (w *Watcher) isModelCatalogEvent(normalizedName string) bool {
+	w.clientsMutex.RLock()
+	path := w.modelCatalogPath
+	w.clientsMutex.RUnlock()
+	return path != "" && normalizedName == w.normalizeAuthPath(path)
+}
+
+func (w *Watcher) scheduleModelCatalogReload() {
+	w.configReloadMu.Lock()
+	defer w.configReloadMu.Unlock()
+	if w.modelCatalogTimer != nil {
+		w.modelCatalogTimer.Stop()
+	}
+	w.modelCatalogTimer = time.AfterFunc(configReloadDebounce, func() {
+		w.configReloadMu.Lock()
+		w.modelCatalogTimer = nil
+		w.configReloadMu.Unlock()
+
+		w.clientsMutex.RLock()
+		path := w.modelCatalogPath
+		w.clientsMutex.RUnlock()
+		if path == "" {
+			return
+		}
+		if w.loadModelCatalog(path) {
+			w.refreshAuthState(true)
+		}
+	})
+}
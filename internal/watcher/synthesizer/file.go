@@ -101,6 +101,7 @@ func (s *FileSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth, e
 			CreatedAt: now,
 			UpdatedAt: now,
 		}
+		a.ApplyMetadataSettings()
 		ApplyAuthExcludedModelsMeta(a, cfg, nil, "oauth")
 		if provider == "gemini-cli" {
 			if virtuals := SynthesizeGeminiVirtualAuths(a, metadata, now); len(virtuals) > 0 {
@@ -129,6 +130,8 @@ func SynthesizeGeminiVirtualAuths(primary *coreauth.Auth, metadata map[string]an
 	}
 	email, _ := metadata["email"].(string)
 	shared := geminicli.NewSharedCredential(primary.ID, email, metadata, projects)
+	// Virtual auths inherit an operator disable applied to the primary credential.
+	operatorDisabled := primary.Disabled
 	primary.Disabled = true
 	primary.Status = coreauth.StatusDisabled
 	primary.Runtime = shared
@@ -171,6 +174,11 @@ func SynthesizeGeminiVirtualAuths(primary *coreauth.Auth, metadata map[string]an
 		if proxy != "" {
 			metadataCopy["proxy_url"] = proxy
 		}
+		for _, key := range []string{"priority", "weight"} {
+			if v, ok := metadata[key]; ok {
+				metadataCopy[key] = v
+			}
+		}
 		virtual := &coreauth.Auth{
 			ID:         buildGeminiVirtualID(primary.ID, projectID),
 			Provider:   originalProvider,
@@ -184,6 +192,10 @@ func SynthesizeGeminiVirtualAuths(primary *coreauth.Auth, metadata map[string]an
 			UpdatedAt:  primary.UpdatedAt,
 			Runtime:    geminicli.NewVirtualCredential(projectID, shared),
 		}
+		if operatorDisabled {
+			virtual.Disabled = true
+			virtual.Status = coreauth.StatusDisabled
+		}
 		virtuals = append(virtuals, virtual)
 	}
 	return virtuals
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
@@ -108,3 +109,11 @@ func addConfigHeadersToAttrs(headers map[string]string, attrs map[string]string)
 		attrs["header:"+key] = val
 	}
 }
+
+// addWeightToAttrs records a configured routing weight for weighted credential selection.
+func addWeightToAttrs(weight int, attrs map[string]string) {
+	if weight <= 0 || attrs == nil {
+		return
+	}
+	attrs["weight"] = strconv.Itoa(weight)
+}
@@ -51,6 +51,9 @@ type Watcher struct {
 	storePersister    storePersister
 	mirroredAuthDir   string
 	oldConfigYaml     []byte
+	modelCatalogPath  string
+	modelCatalogHash  string
+	modelCatalogTimer *time.Timer
 }
 
 // AuthUpdateAction represents the type of change detected in auth sources.
@@ -15,6 +15,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/synthesizer"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
@@ -800,6 +801,37 @@ func TestHandleEventIgnoresUnrelatedFiles(t *testing.T) {
 	}
 }
 
+func TestHandleEventIgnoresAuthDirStateFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	authDir := filepath.Join(tmpDir, "auth")
+	if err := os.MkdirAll(authDir, 0o755); err != nil {
+		t.Fatalf("failed to create auth dir: %v", err)
+	}
+
+	var reloads int32
+	w := &Watcher{
+		authDir:        authDir,
+		configPath:     filepath.Join(tmpDir, "config.yaml"),
+		lastAuthHashes: make(map[string]string),
+		reloadCallback: func(*config.Config) { atomic.AddInt32(&reloads, 1) },
+	}
+	w.SetConfig(&config.Config{AuthDir: authDir})
+
+	for _, name := range []string{misc.DonationBindingsFileName, misc.DonationLedgerFileName, misc.DonationSessionsFileName, misc.ClientKeysUsageFileName} {
+		stateFile := filepath.Join(authDir, name)
+		if err := os.WriteFile(stateFile, []byte(`{"type":"demo"}`), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		w.handleEvent(fsnotify.Event{Name: stateFile, Op: fsnotify.Write})
+	}
+	if atomic.LoadInt32(&reloads) != 0 {
+		t.Fatalf("expected no reloads for state files, got %d", reloads)
+	}
+	if count := w.loadFileClients(&config.Config{AuthDir: authDir}); count != 0 {
+		t.Fatalf("loadFileClients counted %d state files as auth files", count)
+	}
+}
+
 func TestHandleEventConfigChangeSchedulesReload(t *testing.T) {
 	tmpDir := t.TempDir()
 	authDir := filepath.Join(tmpDir, "auth")
@@ -1488,3 +1520,36 @@ func TestScheduleProcessEventsStopsOnContextDone(t *testing.T) {
 func hexString(data []byte) string {
 	return strings.ToLower(fmt.Sprintf("%x", data))
 }
+
+func TestSyncModelCatalogWatchesDirectoryOfMissingFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	authDir := filepath.Join(tmpDir, "auth")
+	catalogDir := filepath.Join(tmpDir, "catalog")
+	for _, dir := range []string{authDir, catalogDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	w, err := NewWatcher(filepath.Join(tmpDir, "config.yaml"), authDir, nil)
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	defer w.watcher.Close()
+
+	catalogPath := filepath.Join(catalogDir, "models.yaml")
+	if w.syncModelCatalog(&config.Config{ModelCatalog: catalogPath}) {
+		t.Fatalf("expected no catalog change while the file is missing")
+	}
+	watched := w.watcher.WatchList()
+	if len(watched) != 1 || watched[0] != catalogDir {
+		t.Fatalf("watch list = %v, want the catalog directory", watched)
+	}
+	if !w.isModelCatalogEvent(w.normalizeAuthPath(catalogPath)) || w.isModelCatalogEvent(w.normalizeAuthPath(filepath.Join(catalogDir, "other.yaml"))) {
+		t.Fatalf("catalog events must be filtered by file name")
+	}
+
+	w.syncModelCatalog(&config.Config{})
+	if watched = w.watcher.WatchList(); len(watched) != 0 {
+		t.Fatalf("watch list after clearing the catalog = %v, want none", watched)
+	}
+}
@@ -0,0 +1,130 @@
+package access
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+)
+
+const (
+	// ClientPolicyMetadataKey is the Result.Metadata key carrying the encoded ClientPolicy.
+	// The same key holds the decoded *ClientPolicy in execution metadata.
+	ClientPolicyMetadataKey = "client_policy"
+	// ClientNameMetadataKey is the Result.Metadata key carrying the client key name.
+	ClientNameMetadataKey = "client_name"
+)
+
+// ClientPolicy restricts what an authenticated client key may use.
+// Empty allow-lists place no restriction.
+type ClientPolicy struct {
+	Name             string   `json:"name,omitempty"`
+	AllowedModels    []string `json:"allowed_models,omitempty"`
+	AllowedProviders []string `json:"allowed_providers,omitempty"`
+	AllowedPrefixes  []string `json:"allowed_prefixes,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+}
+
+// Restricted reports whether the policy limits models, providers or prefixes.
+func (p *ClientPolicy) Restricted() bool {
+	return p != nil && (len(p.AllowedModels) > 0 || len(p.AllowedProviders) > 0 || len(p.AllowedPrefixes) > 0)
+}
+
+// AllowsModel reports whether model matches one of the allowed model patterns.
+// Patterns may use '*' wildcards (e.g. "gemini-*-pro") and are compared case-insensitively.
+func (p *ClientPolicy) AllowsModel(model string) bool {
+	if p == nil || len(p.AllowedModels) == 0 {
+		return true
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	for _, pattern := range p.AllowedModels {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if util.MatchModelPattern(pattern, model) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsProvider reports whether the provider identifier is allowed.
+func (p *ClientPolicy) AllowsProvider(provider string) bool {
+	if p == nil || len(p.AllowedProviders) == 0 {
+		return true
+	}
+	provider = strings.TrimSpace(provider)
+	for _, allowed := range p.AllowedProviders {
+		if strings.EqualFold(strings.TrimSpace(allowed), provider) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsPrefix reports whether a credential with the given prefix may serve the client.
+// An empty prefix denotes an unprefixed credential.
+func (p *ClientPolicy) AllowsPrefix(prefix string) bool {
+	if p == nil || len(p.AllowedPrefixes) == 0 {
+		return true
+	}
+	prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+	for _, allowed := range p.AllowedPrefixes {
+		if strings.EqualFold(strings.Trim(strings.TrimSpace(allowed), "/"), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterProviders returns the providers the policy allows, preserving order.
+func (p *ClientPolicy) FilterProviders(providers []string) []string {
+	if p == nil || len(p.AllowedProviders) == 0 {
+		return providers
+	}
+	out := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if p.AllowsProvider(provider) {
+			out = append(out, provider)
+		}
+	}
+	return out
+}
+
+// EncodeClientPolicy stores the policy in access result metadata.
+func EncodeClientPolicy(metadata map[string]string, p *ClientPolicy) {
+	if metadata == nil || p == nil {
+		return
+	}
+	if p.Name != "" {
+		metadata[ClientNameMetadataKey] = p.Name
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	metadata[ClientPolicyMetadataKey] = string(data)
+}
+
+// ClientPolicyFromMetadata decodes the policy stored by EncodeClientPolicy.
+// It returns nil when the metadata carries no policy.
+func ClientPolicyFromMetadata(metadata map[string]string) *ClientPolicy {
+	raw := metadata[ClientPolicyMetadataKey]
+	if raw == "" {
+		return nil
+	}
+	var p ClientPolicy
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return nil
+	}
+	return &p
+}
+
+// ClientPolicyFromExecution returns the policy attached to execution metadata, if any.
+func ClientPolicyFromExecution(metadata map[string]any) *ClientPolicy {
+	if p, ok := metadata[ClientPolicyMetadataKey].(*ClientPolicy); ok {
+		return p
+	}
+	return nil
+}
@@ -13,9 +13,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/access/ratelimit"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
@@ -233,6 +237,9 @@ func (h *BaseAPIHandler) GetContextWithCancel(handler interfaces.APIHandler, c *
 		requestCtx = c.Request.Context()
 	}
 
+	if requestCtx != nil {
+		parentCtx = tracing.ContextWithRequestSpan(parentCtx, requestCtx)
+	}
 	if requestCtx != nil && logging.GetRequestID(parentCtx) == "" {
 		if requestID := logging.GetRequestID(requestCtx); requestID != "" {
 			parentCtx = logging.WithRequestID(parentCtx, requestID)
@@ -318,10 +325,19 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 // ExecuteWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	logging.AccessRecordFromContext(ctx).SetRequest(handlerType, modelName)
+	ctx, span := tracing.Start(ctx, "cliproxy.handler", tracing.AttrHandler.String(handlerType), tracing.AttrModel.String(modelName))
+	defer span.End()
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	cacheKey := responseCacheKey(ctx, handlerType, modelName, alt, rawJSON)
+	if cacheKey != "" {
+		if cached, hit := lookupCachedResponse(ctx, cacheKey); hit {
+			return cached, nil
+		}
+	}
 	reqMeta := requestExecutionMetadata(ctx)
 	req := coreexecutor.Request{
 		Model:   normalizedModel,
@@ -351,15 +367,22 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 				addon = hdr.Clone()
 			}
 		}
+		tracing.RecordError(span, err)
 		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
 	}
+	if cacheKey != "" {
+		cache.DefaultResponseCache().Set(cacheKey, resp.Payload)
+	}
 	return cloneBytes(resp.Payload), nil
 }
 
 // ExecuteCountWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	logging.AccessRecordFromContext(ctx).SetRequest(handlerType, modelName)
+	ctx, span := tracing.Start(ctx, "cliproxy.handler", tracing.AttrHandler.String(handlerType), tracing.AttrModel.String(modelName))
+	defer span.End()
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
 	if errMsg != nil {
 		return nil, errMsg
 	}
@@ -392,6 +415,55 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 				addon = hdr.Clone()
 			}
 		}
+		tracing.RecordError(span, err)
+		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	}
+	return cloneBytes(resp.Payload), nil
+}
+
+// ExecuteEmbedWithAuthManager executes an embedding request via the core auth manager.
+// action carries the Gemini method name (embedContent/batchEmbedContents) and is empty for OpenAI requests.
+func (h *BaseAPIHandler) ExecuteEmbedWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, action string) ([]byte, *interfaces.ErrorMessage) {
+	logging.AccessRecordFromContext(ctx).SetRequest(handlerType, modelName)
+	ctx, span := tracing.Start(ctx, "cliproxy.handler", tracing.AttrHandler.String(handlerType), tracing.AttrModel.String(modelName))
+	defer span.End()
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	reqMeta := requestExecutionMetadata(ctx)
+	req := coreexecutor.Request{
+		Model:   normalizedModel,
+		Payload: cloneBytes(rawJSON),
+	}
+	req.Metadata = cloneMetadata(metadata)
+	if action != "" {
+		if req.Metadata == nil {
+			req.Metadata = make(map[string]any, 1)
+		}
+		req.Metadata["action"] = action
+	}
+	opts := coreexecutor.Options{
+		Stream:          false,
+		OriginalRequest: cloneBytes(rawJSON),
+		SourceFormat:    sdktranslator.FromString(handlerType),
+	}
+	opts.Metadata = mergeMetadata(cloneMetadata(metadata), reqMeta)
+	resp, err := h.AuthManager.ExecuteEmbed(ctx, providers, req, opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
+			if code := se.StatusCode(); code > 0 {
+				status = code
+			}
+		}
+		var addon http.Header
+		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
+			if hdr := he.Headers(); hdr != nil {
+				addon = hdr.Clone()
+			}
+		}
+		tracing.RecordError(span, err)
 		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
 	}
 	return cloneBytes(resp.Payload), nil
@@ -400,8 +472,11 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 // ExecuteStreamWithAuthManager executes a streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	logging.AccessRecordFromContext(ctx).SetRequest(handlerType, modelName)
+	ctx, span := tracing.Start(ctx, "cliproxy.handler", tracing.AttrHandler.String(handlerType), tracing.AttrModel.String(modelName), tracing.AttrStream.Bool(true))
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
 	if errMsg != nil {
+		tracing.End(span, errMsg.Error)
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
 		close(errChan)
@@ -422,8 +497,18 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		SourceFormat:    sdktranslator.FromString(handlerType),
 	}
 	opts.Metadata = mergeMetadata(cloneMetadata(metadata), reqMeta)
+	releaseStream, limitErr := ratelimit.Default().AcquireStream(clientAPIKey(ctx))
+	if limitErr != nil {
+		tracing.End(span, limitErr)
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- &interfaces.ErrorMessage{StatusCode: http.StatusTooManyRequests, Error: limitErr, Addon: limitErr.Headers()}
+		close(errChan)
+		return nil, errChan
+	}
 	chunks, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 	if err != nil {
+		releaseStream()
+		tracing.End(span, err)
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
@@ -444,8 +529,11 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	dataChan := make(chan []byte)
 	errChan := make(chan *interfaces.ErrorMessage, 1)
 	go func() {
+		defer releaseStream()
 		defer close(dataChan)
 		defer close(errChan)
+		var streamErr error
+		defer func() { tracing.End(span, streamErr) }()
 		sentPayload := false
 		bootstrapRetries := 0
 		maxBootstrapRetries := StreamingBootstrapRetries(h.Cfg)
@@ -482,7 +570,7 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 					return
 				}
 				if chunk.Err != nil {
-					streamErr := chunk.Err
+					streamErr = chunk.Err
 					// Safe bootstrap recovery: if the upstream fails before any payload bytes are sent,
 					// retry a few times (to allow auth rotation / transient recovery) and then attempt model fallback.
 					if !sentPayload {
@@ -491,6 +579,7 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 							retryChunks, retryErr := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 							if retryErr == nil {
 								chunks = retryChunks
+								streamErr = nil
 								continue outer
 							}
 							streamErr = retryErr
@@ -522,6 +611,23 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	return dataChan, errChan
 }
 
+// clientAPIKey returns the authenticated client API key stored on the gin context, if any.
+func clientAPIKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return ""
+	}
+	if v, exists := ginCtx.Get("apiKey"); exists {
+		if key, isString := v.(string); isString {
+			return key
+		}
+	}
+	return ""
+}
+
 func statusFromError(err error) int {
 	if err == nil {
 		return 0
@@ -534,7 +640,57 @@ func statusFromError(err error) int {
 	return 0
 }
 
-func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string, normalizedModel string, metadata map[string]any, err *interfaces.ErrorMessage) {
+// clientPolicy returns the restrictions attached to the authenticated client key, if any.
+func clientPolicy(ctx context.Context) *sdkaccess.ClientPolicy {
+	if ctx == nil {
+		return nil
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok {
+		return nil
+	}
+	return ginClientPolicy(ginCtx)
+}
+
+func ginClientPolicy(c *gin.Context) *sdkaccess.ClientPolicy {
+	if c == nil {
+		return nil
+	}
+	if v, exists := c.Get("accessMetadata"); exists {
+		if metadata, isMap := v.(map[string]string); isMap {
+			return sdkaccess.ClientPolicyFromMetadata(metadata)
+		}
+	}
+	return nil
+}
+
+// FilterModelsForClient drops models the authenticated client key may not use. A model is
+// kept when its "id" or "name" matches the allowed model patterns and at least one allowed
+// provider serves it.
+func (h *BaseAPIHandler) FilterModelsForClient(c *gin.Context, models []map[string]any) []map[string]any {
+	policy := ginClientPolicy(c)
+	if !policy.Restricted() {
+		return models
+	}
+	out := make([]map[string]any, 0, len(models))
+	for _, model := range models {
+		id, _ := model["id"].(string)
+		if id == "" {
+			name, _ := model["name"].(string)
+			id = strings.TrimPrefix(name, "models/")
+		}
+		if id == "" || !policy.AllowsModel(id) {
+			continue
+		}
+		if len(policy.AllowedProviders) > 0 && len(policy.FilterProviders(util.GetProviderName(id))) == 0 {
+			continue
+		}
+		out = append(out, model)
+	}
+	return out
+}
+
+func (h *BaseAPIHandler) getRequestDetails(ctx context.Context, modelName string) (providers []string, normalizedModel string, metadata map[string]any, err *interfaces.ErrorMessage) {
 	// Resolve "auto" model to an actual available model first
 	resolvedModelName := util.ResolveAutoModel(modelName)
 
@@ -561,6 +717,22 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 		return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("unknown provider for model %s", modelName)}
 	}
 
+	if policy := clientPolicy(ctx); policy != nil {
+		if !policy.AllowsModel(normalizedModel) && !policy.AllowsModel(modelName) {
+			return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("model %s is not allowed for this API key", modelName)}
+		}
+		providers = policy.FilterProviders(providers)
+		if len(providers) == 0 {
+			return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("no allowed provider serves model %s for this API key", modelName)}
+		}
+		if policy.Restricted() {
+			if metadata == nil {
+				metadata = make(map[string]any, 1)
+			}
+			metadata[sdkaccess.ClientPolicyMetadataKey] = policy
+		}
+	}
+
 	// If it's a dynamic model, the normalizedModel was already set to extractedModelName.
 	// If it's a non-dynamic model, normalizedModel was set by normalizeModelMetadata.
 	// So, normalizedModel is already correctly set at this point.
@@ -0,0 +1,176 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/images"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/sjson"
+)
+
+// maxImageUploadBytes bounds each image uploaded to /v1/images/edits.
+const maxImageUploadBytes = 20 << 20
+
+// ImageGenerations handles the /v1/images/generations endpoint.
+// The request is translated to a Gemini generateContent call on an image-capable model and
+// routed through the auth manager, so Gemini, Vertex, AI Studio and Antigravity credentials
+// can serve it.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) ImageGenerations(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		writeImagesBadRequest(c, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	req, err := images.ParseGenerationRequest(rawJSON)
+	if err != nil {
+		writeImagesBadRequest(c, err.Error())
+		return
+	}
+	h.handleImages(c, req)
+}
+
+// ImageEdits handles the /v1/images/edits endpoint.
+// It accepts the OpenAI multipart form (image or image[], optional mask, prompt, n, size,
+// response_format, model) and sends the uploaded images to the model with the prompt.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) ImageEdits(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		writeImagesBadRequest(c, fmt.Sprintf("Invalid request: expected multipart/form-data: %v", err))
+		return
+	}
+	req := images.Request{
+		Model:          formValue(form, "model"),
+		Prompt:         formValue(form, "prompt"),
+		Size:           formValue(form, "size"),
+		ResponseFormat: formValue(form, "response_format"),
+	}
+	if n := formValue(form, "n"); n != "" {
+		if req.N, err = strconv.Atoi(n); err != nil {
+			writeImagesBadRequest(c, "n must be an integer")
+			return
+		}
+	}
+	for _, key := range []string{"image", "image[]"} {
+		for _, header := range form.File[key] {
+			image, errRead := readUploadedImage(header)
+			if errRead != nil {
+				writeImagesBadRequest(c, errRead.Error())
+				return
+			}
+			req.Images = append(req.Images, image)
+		}
+	}
+	if len(req.Images) == 0 {
+		writeImagesBadRequest(c, "image is required")
+		return
+	}
+	if masks := form.File["mask"]; len(masks) > 0 {
+		mask, errRead := readUploadedImage(masks[0])
+		if errRead != nil {
+			writeImagesBadRequest(c, errRead.Error())
+			return
+		}
+		req.Mask = &mask
+	}
+	if err = req.Normalize(); err != nil {
+		writeImagesBadRequest(c, err.Error())
+		return
+	}
+	h.handleImages(c, req)
+}
+
+// handleImages runs one Gemini request per requested image and writes the OpenAI response.
+func (h *OpenAIAPIHandler) handleImages(c *gin.Context, req images.Request) {
+	c.Header("Content-Type", "application/json")
+	payload := images.ConvertOpenAIImagesRequestToGemini(req)
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	// Repeating an image request is expected to produce new images, so the response cache is
+	// skipped.
+	cliCtx = handlers.WithoutResponseCache(cliCtx)
+	responses := make([][]byte, 0, req.N)
+	for i := 0; i < req.N; i++ {
+		attempt := payload
+		if req.N > 1 {
+			// Distinct seeds keep the images of one request from being identical. The modulus
+			// leaves room for the offset so the seed stays within int32.
+			attempt, _ = sjson.SetBytes(payload, "generationConfig.seed", time.Now().UnixNano()%(1<<31-images.MaxImages)+int64(i))
+		}
+		resp, errMsg := h.ExecuteWithAuthManager(cliCtx, Gemini, req.Model, attempt, "")
+		if errMsg != nil {
+			h.WriteErrorResponse(c, errMsg)
+			cliCancel(errMsg.Error)
+			return
+		}
+		responses = append(responses, resp)
+	}
+	out, err := images.ConvertGeminiResponsesToOpenAIImages(responses, req.ResponseFormat, req.N, time.Now().Unix())
+	if err != nil {
+		errMsg := &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: err}
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(err)
+		return
+	}
+	_, _ = c.Writer.Write(out)
+	cliCancel()
+}
+
+func writeImagesBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: message,
+			Type:    "invalid_request_error",
+		},
+	})
+}
+
+func formValue(form *multipart.Form, key string) string {
+	if values := form.Value[key]; len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+// readUploadedImage reads an uploaded file, detecting its MIME type when the part has none.
+func readUploadedImage(header *multipart.FileHeader) (images.InlineImage, error) {
+	if header.Size > maxImageUploadBytes {
+		return images.InlineImage{}, fmt.Errorf("image %s exceeds %d bytes", header.Filename, maxImageUploadBytes)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return images.InlineImage{}, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(file, maxImageUploadBytes+1))
+	if err != nil {
+		return images.InlineImage{}, err
+	}
+	if len(data) == 0 {
+		return images.InlineImage{}, fmt.Errorf("image %s is empty", header.Filename)
+	}
+	mimeType := header.Header.Get("Content-Type")
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return images.InlineImage{}, fmt.Errorf("image %s has unsupported type %s", header.Filename, mimeType)
+	}
+	return images.InlineImage{MIMEType: mimeType, Data: data}, nil
+}
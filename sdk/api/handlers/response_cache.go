@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	"golang.org/x/net/context"
+)
+
+// ResponseCacheHeader is both the request header used to bypass the response cache
+// ("bypass") and the response header reporting the cache outcome ("HIT" or "MISS").
+const ResponseCacheHeader = "X-CLIProxy-Cache"
+
+type responseCacheBypassKey struct{}
+
+// WithoutResponseCache marks ctx so non-streaming executions skip the response cache, for
+// endpoints whose identical requests are expected to return different results.
+func WithoutResponseCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseCacheBypassKey{}, true)
+}
+
+// responseCacheKey returns the cache key for a non-streaming request, scoped to the authenticated
+// client, or "" when the cache is disabled or the client or handler asked to bypass it.
+func responseCacheKey(ctx context.Context, handlerType, modelName, alt string, rawJSON []byte) string {
+	if !cache.DefaultResponseCache().Enabled() {
+		return ""
+	}
+	if bypass, _ := ctx.Value(responseCacheBypassKey{}).(bool); bypass {
+		return ""
+	}
+	ginCtx, _ := ctx.Value("gin").(*gin.Context)
+	if ginCtx != nil && ginCtx.Request != nil && responseCacheBypassed(ginCtx) {
+		return ""
+	}
+	return cache.ResponseCacheKey(clientAPIKey(ctx), handlerType, modelName, alt, rawJSON)
+}
+
+// responseCacheBypassed reports whether the request opted out via X-CLIProxy-Cache or Cache-Control.
+func responseCacheBypassed(c *gin.Context) bool {
+	switch strings.ToLower(strings.TrimSpace(c.GetHeader(ResponseCacheHeader))) {
+	case "bypass", "no-cache", "no-store", "off":
+		return true
+	}
+	for _, directive := range strings.Split(c.GetHeader("Cache-Control"), ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "no-cache", "no-store":
+			return true
+		}
+	}
+	return false
+}
+
+// lookupCachedResponse returns the cached response for key and records the lookup outcome.
+func lookupCachedResponse(ctx context.Context, key string) ([]byte, bool) {
+	body, hit := cache.DefaultResponseCache().Get(key)
+	usage.GetRequestStatistics().RecordCacheLookup(hit)
+	if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil {
+		if hit {
+			ginCtx.Header(ResponseCacheHeader, "HIT")
+		} else {
+			ginCtx.Header(ResponseCacheHeader, "MISS")
+		}
+	}
+	return body, hit
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+)
+
+func TestFileTokenStoreListSkipsStateFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"claude-user.json":            `{"type":"claude","email":"user@example.com"}`,
+		misc.DonationBindingsFileName: `{"bindings":[]}`,
+		misc.DonationLedgerFileName:   `{"donations":[]}`,
+		misc.DonationSessionsFileName: `{"sessions":{}}`,
+		misc.ClientKeysUsageFileName:  `{}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	store := NewFileTokenStore()
+	store.SetBaseDir(dir)
+
+	auths, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(auths) != 1 || auths[0].ID != "claude-user.json" {
+		ids := make([]string, 0, len(auths))
+		for _, auth := range auths {
+			ids = append(ids, auth.ID)
+		}
+		t.Fatalf("List() = %v, want only claude-user.json", ids)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -14,9 +15,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ProviderExecutor defines the contract required by Manager to execute provider calls.
@@ -84,6 +89,17 @@ type Hook interface {
 	OnResult(ctx context.Context, result Result)
 }
 
+// RefreshFailureHook is implemented by hooks that also observe failed credential refreshes.
+type RefreshFailureHook interface {
+	OnRefreshFailed(ctx context.Context, auth *Auth, err error)
+}
+
+// ResultStateHook is implemented by hooks that also observe the credential state produced by
+// a recorded execution result, such as the cooldown it scheduled.
+type ResultStateHook interface {
+	OnResultApplied(ctx context.Context, result Result, auth *Auth)
+}
+
 // NoopHook provides optional hook defaults.
 type NoopHook struct{}
 
@@ -96,6 +112,48 @@ func (NoopHook) OnAuthUpdated(context.Context, *Auth) {}
 // OnResult implements Hook.
 func (NoopHook) OnResult(context.Context, Result) {}
 
+// multiHook fans lifecycle callbacks out to several hooks in registration order.
+type multiHook []Hook
+
+// OnAuthRegistered implements Hook.
+func (h multiHook) OnAuthRegistered(ctx context.Context, auth *Auth) {
+	for _, hook := range h {
+		hook.OnAuthRegistered(ctx, auth.Clone())
+	}
+}
+
+// OnAuthUpdated implements Hook.
+func (h multiHook) OnAuthUpdated(ctx context.Context, auth *Auth) {
+	for _, hook := range h {
+		hook.OnAuthUpdated(ctx, auth.Clone())
+	}
+}
+
+// OnResult implements Hook.
+func (h multiHook) OnResult(ctx context.Context, result Result) {
+	for _, hook := range h {
+		hook.OnResult(ctx, result)
+	}
+}
+
+// OnRefreshFailed implements RefreshFailureHook for the hooks that support it.
+func (h multiHook) OnRefreshFailed(ctx context.Context, auth *Auth, err error) {
+	for _, hook := range h {
+		if rh, ok := hook.(RefreshFailureHook); ok {
+			rh.OnRefreshFailed(ctx, auth.Clone(), err)
+		}
+	}
+}
+
+// OnResultApplied implements ResultStateHook for the hooks that support it.
+func (h multiHook) OnResultApplied(ctx context.Context, result Result, auth *Auth) {
+	for _, hook := range h {
+		if rh, ok := hook.(ResultStateHook); ok {
+			rh.OnResultApplied(ctx, result, auth.Clone())
+		}
+	}
+}
+
 // Manager orchestrates auth lifecycle, selection, execution, and persistence.
 type Manager struct {
 	store     Store
@@ -114,11 +172,24 @@ type Manager struct {
 	// modelNameMappings stores global model name alias mappings (alias -> upstream name) keyed by channel.
 	modelNameMappings atomic.Value
 
+	// modelFallbacks stores the cross-provider fallback chains keyed by requested model.
+	modelFallbacks atomic.Value
+
 	// Optional HTTP RoundTripper provider injected by host.
 	rtProvider RoundTripperProvider
 
+	// executionHooks run around every executor call; guarded by mu.
+	executionHooks []ExecutionHook
+
 	// Auto refresh state
 	refreshCancel context.CancelFunc
+
+	// translator is the pipeline executors translate payloads with; nil uses the default pipeline.
+	translator atomic.Pointer[sdktranslator.Pipeline]
+
+	// healthProbe stores the active *internalconfig.HealthProbeConfig.
+	healthProbe atomic.Value
+	prober      healthProber
 }
 
 // NewManager constructs a manager with optional custom selector and hook.
@@ -151,6 +222,49 @@ func (m *Manager) SetSelector(selector Selector) {
 	m.mu.Unlock()
 }
 
+// SetTranslatorPipeline sets the translator pipeline executors use for this manager's requests,
+// so middleware registered on it does not affect other managers. Nil restores the default pipeline.
+func (m *Manager) SetTranslatorPipeline(p *sdktranslator.Pipeline) {
+	if m == nil {
+		return
+	}
+	m.translator.Store(p)
+}
+
+// TranslatorPipeline returns the pipeline set by SetTranslatorPipeline, or nil.
+func (m *Manager) TranslatorPipeline() *sdktranslator.Pipeline {
+	if m == nil {
+		return nil
+	}
+	return m.translator.Load()
+}
+
+// AddHook registers an additional lifecycle hook alongside the existing one.
+func (m *Manager) AddHook(hook Hook) {
+	if m == nil || hook == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch current := m.hook.(type) {
+	case nil, NoopHook:
+		m.hook = hook
+	case multiHook:
+		m.hook = append(append(multiHook(nil), current...), hook)
+	default:
+		m.hook = multiHook{current, hook}
+	}
+}
+
+func (m *Manager) currentHook() Hook {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.hook == nil {
+		return NoopHook{}
+	}
+	return m.hook
+}
+
 // SetStore swaps the underlying persistence store.
 func (m *Manager) SetStore(store Store) {
 	m.mu.Lock()
@@ -214,7 +328,7 @@ func (m *Manager) Register(ctx context.Context, auth *Auth) (*Auth, error) {
 	m.auths[auth.ID] = auth.Clone()
 	m.mu.Unlock()
 	_ = m.persist(ctx, auth)
-	m.hook.OnAuthRegistered(ctx, auth.Clone())
+	m.currentHook().OnAuthRegistered(ctx, auth.Clone())
 	return auth.Clone(), nil
 }
 
@@ -232,7 +346,7 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 	m.auths[auth.ID] = auth.Clone()
 	m.mu.Unlock()
 	_ = m.persist(ctx, auth)
-	m.hook.OnAuthUpdated(ctx, auth.Clone())
+	m.currentHook().OnAuthUpdated(ctx, auth.Clone())
 	return auth.Clone(), nil
 }
 
@@ -260,12 +374,19 @@ func (m *Manager) Load(ctx context.Context) error {
 
 // Execute performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
+// When the model cannot be served, configured model fallback chains are tried in order.
 func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return executeWithModelFallbacks(ctx, m, providers, req, func(candidates []string, target cliproxyexecutor.Request) (cliproxyexecutor.Response, error) {
+		return m.execute(ctx, candidates, target, opts)
+	})
+}
+
+func (m *Manager) execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
-	rotated := m.rotateProviders(req.Model, normalized)
+	rotated := m.providerOrder(ctx, req.Model, opts, normalized)
 
 	retryTimes, maxWait := m.retrySettings()
 	attempts := retryTimes + 1
@@ -298,12 +419,19 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxye
 
 // ExecuteCount performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
+// When the model cannot be served, configured model fallback chains are tried in order.
 func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return executeWithModelFallbacks(ctx, m, providers, req, func(candidates []string, target cliproxyexecutor.Request) (cliproxyexecutor.Response, error) {
+		return m.executeCount(ctx, candidates, target, opts)
+	})
+}
+
+func (m *Manager) executeCount(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
-	rotated := m.rotateProviders(req.Model, normalized)
+	rotated := m.providerOrder(ctx, req.Model, opts, normalized)
 
 	retryTimes, maxWait := m.retrySettings()
 	attempts := retryTimes + 1
@@ -334,14 +462,65 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 	return cliproxyexecutor.Response{}, &Error{Code: "auth_not_found", Message: "no auth available"}
 }
 
+// ExecuteEmbed performs an embedding request using the configured selector and executor.
+// Providers whose executor does not implement EmbeddingExecutor are skipped.
+func (m *Manager) ExecuteEmbed(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	normalized := m.normalizeProviders(providers)
+	supported := make([]string, 0, len(normalized))
+	for _, provider := range normalized {
+		if _, ok := m.executorFor(provider).(EmbeddingExecutor); ok {
+			supported = append(supported, provider)
+		}
+	}
+	if len(supported) == 0 {
+		return cliproxyexecutor.Response{}, &Error{Code: "embeddings_not_supported", Message: fmt.Sprintf("model %s does not support embeddings", req.Model), HTTPStatus: http.StatusBadRequest}
+	}
+	rotated := m.providerOrder(ctx, req.Model, opts, supported)
+
+	retryTimes, maxWait := m.retrySettings()
+	attempts := retryTimes + 1
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		resp, errExec := m.executeProvidersOnce(ctx, rotated, func(execCtx context.Context, provider string) (cliproxyexecutor.Response, error) {
+			return m.executeEmbedWithProvider(execCtx, provider, req, opts)
+		})
+		if errExec == nil {
+			return resp, nil
+		}
+		lastErr = errExec
+		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, attempts, rotated, req.Model, maxWait)
+		if !shouldRetry {
+			break
+		}
+		if errWait := waitForCooldown(ctx, wait); errWait != nil {
+			return cliproxyexecutor.Response{}, errWait
+		}
+	}
+	if lastErr != nil {
+		return cliproxyexecutor.Response{}, lastErr
+	}
+	return cliproxyexecutor.Response{}, &Error{Code: "auth_not_found", Message: "no auth available"}
+}
+
 // ExecuteStream performs a streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
+// When the model cannot be served, configured model fallback chains are tried in order.
 func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	return executeWithModelFallbacks(ctx, m, providers, req, func(candidates []string, target cliproxyexecutor.Request) (<-chan cliproxyexecutor.StreamChunk, error) {
+		return m.executeStream(ctx, candidates, target, opts)
+	})
+}
+
+func (m *Manager) executeStream(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
-	rotated := m.rotateProviders(req.Model, normalized)
+	rotated := m.providerOrder(ctx, req.Model, opts, normalized)
 
 	retryTimes, maxWait := m.retrySettings()
 	attempts := retryTimes + 1
@@ -411,10 +590,20 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
 		}
+		execCtx = sdktranslator.WithPipeline(execCtx, m.TranslatorPipeline())
 		execReq := req
 		execReq.Model, execReq.Metadata = rewriteModelForAuth(routeModel, req.Metadata, auth)
 		execReq.Model, execReq.Metadata = m.applyOAuthModelMapping(auth, execReq.Model, execReq.Metadata)
-		resp, errExec := executor.Execute(execCtx, auth, execReq, opts)
+		execOpts := opts
+		hooks := m.executionHooksFor(provider, auth, len(tried), false)
+		execCtx = hooks.before(execCtx, &execReq, &execOpts)
+		tracker := m.trackExecution(auth.ID)
+		logging.AccessRecordFromContext(ctx).AddAttempt(provider, auth.ID, auth.EnsureIndex(), execReq.Model)
+		attemptCtx, attemptSpan := startAttemptSpan(execCtx, provider, auth, routeModel, execReq.Model, len(tried))
+		resp, errExec := executor.Execute(attemptCtx, auth, execReq, execOpts)
+		hooks.after(attemptCtx, resp, errExec)
+		tracing.End(attemptSpan, errExec)
+		tracker.done(errExec == nil)
 		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
 		if errExec != nil {
 			result.Error = &Error{Message: errExec.Error()}
@@ -473,10 +662,83 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
 		}
+		execCtx = sdktranslator.WithPipeline(execCtx, m.TranslatorPipeline())
 		execReq := req
 		execReq.Model, execReq.Metadata = rewriteModelForAuth(routeModel, req.Metadata, auth)
 		execReq.Model, execReq.Metadata = m.applyOAuthModelMapping(auth, execReq.Model, execReq.Metadata)
-		resp, errExec := executor.CountTokens(execCtx, auth, execReq, opts)
+		execOpts := opts
+		hooks := m.executionHooksFor(provider, auth, len(tried), false)
+		execCtx = hooks.before(execCtx, &execReq, &execOpts)
+		logging.AccessRecordFromContext(ctx).AddAttempt(provider, auth.ID, auth.EnsureIndex(), execReq.Model)
+		attemptCtx, attemptSpan := startAttemptSpan(execCtx, provider, auth, routeModel, execReq.Model, len(tried))
+		resp, errExec := executor.CountTokens(attemptCtx, auth, execReq, execOpts)
+		hooks.after(attemptCtx, resp, errExec)
+		tracing.End(attemptSpan, errExec)
+		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
+		if errExec != nil {
+			result.Error = &Error{Message: errExec.Error()}
+			var se cliproxyexecutor.StatusError
+			if errors.As(errExec, &se) && se != nil {
+				result.Error.HTTPStatus = se.StatusCode()
+			}
+			if ra := retryAfterFromError(errExec); ra != nil {
+				result.RetryAfter = ra
+			}
+			m.MarkResult(execCtx, result)
+			lastErr = errExec
+			continue
+		}
+		m.MarkResult(execCtx, result)
+		return resp, nil
+	}
+}
+
+func (m *Manager) executeEmbedWithProvider(ctx context.Context, provider string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	if provider == "" {
+		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "provider identifier is empty"}
+	}
+	routeModel := req.Model
+	tried := make(map[string]struct{})
+	var lastErr error
+	for {
+		auth, executor, errPick := m.pickNext(ctx, provider, routeModel, opts, tried)
+		if errPick != nil {
+			if lastErr != nil {
+				return cliproxyexecutor.Response{}, lastErr
+			}
+			return cliproxyexecutor.Response{}, errPick
+		}
+		embedder, ok := executor.(EmbeddingExecutor)
+		if !ok {
+			return cliproxyexecutor.Response{}, &Error{Code: "embeddings_not_supported", Message: fmt.Sprintf("provider %s does not support embeddings", provider), HTTPStatus: http.StatusBadRequest}
+		}
+
+		accountType, accountInfo := auth.AccountInfo()
+		entry := logEntryWithRequestID(ctx)
+		if accountType == "api_key" {
+			entry.Debugf("Use API key %s for embedding model %s", util.HideAPIKey(accountInfo), req.Model)
+		} else if accountType == "oauth" {
+			entry.Debugf("Use OAuth %s for embedding model %s", accountInfo, req.Model)
+		}
+
+		tried[auth.ID] = struct{}{}
+		execCtx := ctx
+		if rt := m.roundTripperFor(auth); rt != nil {
+			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+		}
+		execCtx = sdktranslator.WithPipeline(execCtx, m.TranslatorPipeline())
+		execReq := req
+		execReq.Model, execReq.Metadata = rewriteModelForAuth(routeModel, req.Metadata, auth)
+		execReq.Model, execReq.Metadata = m.applyOAuthModelMapping(auth, execReq.Model, execReq.Metadata)
+		execOpts := opts
+		hooks := m.executionHooksFor(provider, auth, len(tried), false)
+		execCtx = hooks.before(execCtx, &execReq, &execOpts)
+		logging.AccessRecordFromContext(ctx).AddAttempt(provider, auth.ID, auth.EnsureIndex(), execReq.Model)
+		attemptCtx, attemptSpan := startAttemptSpan(execCtx, provider, auth, routeModel, execReq.Model, len(tried))
+		resp, errExec := embedder.Embed(attemptCtx, auth, execReq, execOpts)
+		hooks.after(attemptCtx, resp, errExec)
+		tracing.End(attemptSpan, errExec)
 		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
 		if errExec != nil {
 			result.Error = &Error{Message: errExec.Error()}
@@ -535,11 +797,22 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
 		}
+		execCtx = sdktranslator.WithPipeline(execCtx, m.TranslatorPipeline())
 		execReq := req
 		execReq.Model, execReq.Metadata = rewriteModelForAuth(routeModel, req.Metadata, auth)
 		execReq.Model, execReq.Metadata = m.applyOAuthModelMapping(auth, execReq.Model, execReq.Metadata)
-		chunks, errStream := executor.ExecuteStream(execCtx, auth, execReq, opts)
+		execOpts := opts
+		hooks := m.executionHooksFor(provider, auth, len(tried), true)
+		execCtx = hooks.before(execCtx, &execReq, &execOpts)
+		tracker := m.trackExecution(auth.ID)
+		logging.AccessRecordFromContext(ctx).AddAttempt(provider, auth.ID, auth.EnsureIndex(), execReq.Model)
+		attemptCtx, attemptSpan := startAttemptSpan(execCtx, provider, auth, routeModel, execReq.Model, len(tried))
+		attemptSpan.SetAttributes(tracing.AttrStream.Bool(true))
+		chunks, errStream := executor.ExecuteStream(attemptCtx, auth, execReq, execOpts)
 		if errStream != nil {
+			hooks.after(attemptCtx, cliproxyexecutor.Response{}, errStream)
+			tracing.End(attemptSpan, errStream)
+			tracker.done(false)
 			rerr := &Error{Message: errStream.Error()}
 			var se cliproxyexecutor.StatusError
 			if errors.As(errStream, &se) && se != nil {
@@ -555,9 +828,13 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk) {
 			defer close(out)
 			var failed bool
+			var streamErr error
+			defer func() { tracing.End(attemptSpan, streamErr) }()
 			for chunk := range streamChunks {
+				tracker.markFirstChunk()
 				if chunk.Err != nil && !failed {
 					failed = true
+					streamErr = chunk.Err
 					rerr := &Error{Message: chunk.Err.Error()}
 					var se cliproxyexecutor.StatusError
 					if errors.As(chunk.Err, &se) && se != nil {
@@ -565,8 +842,11 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 					}
 					m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: false, Error: rerr})
 				}
+				hooks.chunk(streamCtx, chunk)
 				out <- chunk
 			}
+			hooks.after(streamCtx, cliproxyexecutor.Response{}, streamErr)
+			tracker.done(!failed)
 			if !failed {
 				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true})
 			}
@@ -644,14 +924,59 @@ func (m *Manager) normalizeProviders(providers []string) []string {
 	return result
 }
 
+// providerOrder returns the order in which providers are tried for a request: the rotation (or
+// ProviderOrderer order) from rotateProviders, with the provider named by a ProviderPreferrer
+// selector moved to the front.
+func (m *Manager) providerOrder(ctx context.Context, model string, opts cliproxyexecutor.Options, providers []string) []string {
+	ordered := m.rotateProviders(model, providers)
+	m.mu.RLock()
+	preferrer, ok := m.selector.(ProviderPreferrer)
+	m.mu.RUnlock()
+	if !ok {
+		return ordered
+	}
+	preferred := preferrer.PreferredProvider(ctx, model, opts)
+	for i, provider := range ordered {
+		if provider != preferred {
+			continue
+		}
+		if i == 0 {
+			return ordered
+		}
+		result := make([]string, 0, len(ordered))
+		result = append(result, provider)
+		result = append(result, ordered[:i]...)
+		return append(result, ordered[i+1:]...)
+	}
+	return ordered
+}
+
 // rotateProviders returns a rotated view of the providers list starting from the
 // current offset for the model, and atomically increments the offset for the next call.
-// This ensures concurrent requests get different starting providers.
+// This ensures concurrent requests get different starting providers. Selectors implementing
+// ProviderOrderer decide the order instead.
 func (m *Manager) rotateProviders(model string, providers []string) []string {
 	if len(providers) == 0 {
 		return nil
 	}
 
+	m.mu.RLock()
+	if orderer, ok := m.selector.(ProviderOrderer); ok {
+		candidates := make([]*Auth, 0, len(m.auths))
+		for _, candidate := range m.auths {
+			if !candidate.Disabled {
+				candidates = append(candidates, candidate)
+			}
+		}
+		ordered := orderer.OrderProviders(model, providers, candidates)
+		m.mu.RUnlock()
+		if len(ordered) == len(providers) {
+			return ordered
+		}
+		return providers
+	}
+	m.mu.RUnlock()
+
 	// Atomic read-and-increment: get current offset and advance cursor in one lock
 	m.mu.Lock()
 	offset := m.providerOffsets[model]
@@ -673,6 +998,53 @@ func (m *Manager) rotateProviders(model string, providers []string) []string {
 	return rotated
 }
 
+// executionTracker reports the lifecycle of one execution to an ExecutionObserver selector.
+// A nil tracker is valid and does nothing.
+type executionTracker struct {
+	observer ExecutionObserver
+	authID   string
+	started  time.Time
+	latency  time.Duration
+	once     sync.Once
+}
+
+// trackExecution notifies the selector, when it observes executions, that authID started serving a request.
+func (m *Manager) trackExecution(authID string) *executionTracker {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	observer, ok := m.selector.(ExecutionObserver)
+	m.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	observer.ExecutionStarted(authID)
+	return &executionTracker{observer: observer, authID: authID, started: time.Now()}
+}
+
+// markFirstChunk records the time to first chunk as the latency of a stream.
+func (t *executionTracker) markFirstChunk() {
+	if t == nil || t.latency > 0 {
+		return
+	}
+	t.latency = time.Since(t.started)
+}
+
+// done reports completion exactly once.
+func (t *executionTracker) done(success bool) {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() {
+		latency := t.latency
+		if latency <= 0 {
+			latency = time.Since(t.started)
+		}
+		t.observer.ExecutionFinished(t.authID, latency, success)
+	})
+}
+
 func (m *Manager) retrySettings() (int, time.Duration) {
 	if m == nil {
 		return 0, 0
@@ -744,6 +1116,8 @@ func waitForCooldown(ctx context.Context, wait time.Duration) error {
 	if wait <= 0 {
 		return nil
 	}
+	_, span := tracing.Start(ctx, "cliproxy.cooldown_wait", tracing.AttrWaitMs.Int64(wait.Milliseconds()))
+	defer span.End()
 	timer := time.NewTimer(wait)
 	defer timer.Stop()
 	select {
@@ -801,6 +1175,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	suspendReason := ""
 	clearModelQuota := false
 	setModelQuota := false
+	var snapshot *Auth
 
 	m.mu.Lock()
 	if auth, ok := m.auths[result.AuthID]; ok && auth != nil {
@@ -890,6 +1265,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 		}
 
 		_ = m.persist(ctx, auth)
+		snapshot = auth.Clone()
 	}
 	m.mu.Unlock()
 
@@ -905,7 +1281,11 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 		registry.GetGlobalRegistry().SuspendClientModel(result.AuthID, result.Model, suspendReason)
 	}
 
-	m.hook.OnResult(ctx, result)
+	hook := m.currentHook()
+	hook.OnResult(ctx, result)
+	if rh, ok := hook.(ResultStateHook); ok && snapshot != nil {
+		rh.OnResultApplied(ctx, result, snapshot)
+	}
 }
 
 func ensureModelState(auth *Auth, model string) *ModelState {
@@ -1180,6 +1560,27 @@ func (m *Manager) GetByID(id string) (*Auth, bool) {
 }
 
 func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
+	_, span := tracing.Start(ctx, "cliproxy.select", tracing.AttrProvider.String(provider), tracing.AttrModel.String(model))
+	auth, executor, err := m.pickNextCandidate(ctx, provider, model, opts, tried)
+	if auth != nil {
+		span.SetAttributes(tracing.AttrAuthIndex.String(auth.EnsureIndex()))
+	}
+	tracing.End(span, err)
+	return auth, executor, err
+}
+
+// startAttemptSpan opens the span covering one upstream attempt with a selected credential.
+func startAttemptSpan(ctx context.Context, provider string, auth *Auth, model, upstreamModel string, attempt int) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "cliproxy.attempt",
+		tracing.AttrProvider.String(provider),
+		tracing.AttrModel.String(model),
+		tracing.AttrUpstreamModel.String(upstreamModel),
+		tracing.AttrAuthIndex.String(auth.EnsureIndex()),
+		tracing.AttrAttempt.Int(attempt),
+	)
+}
+
+func (m *Manager) pickNextCandidate(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
 	m.mu.RLock()
 	executor, okExecutor := m.executors[provider]
 	if !okExecutor {
@@ -1189,10 +1590,14 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 	candidates := make([]*Auth, 0, len(m.auths))
 	modelKey := strings.TrimSpace(model)
 	registryRef := registry.GetGlobalRegistry()
+	policy := sdkaccess.ClientPolicyFromExecution(opts.Metadata)
 	for _, candidate := range m.auths {
 		if candidate.Provider != provider || candidate.Disabled {
 			continue
 		}
+		if !policy.AllowsPrefix(candidate.Prefix) {
+			continue
+		}
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
@@ -1551,13 +1956,18 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 	log.Debugf("refreshed %s, %s, %v", auth.Provider, auth.ID, err)
 	now := time.Now()
 	if err != nil {
+		var snapshot *Auth
 		m.mu.Lock()
 		if current := m.auths[id]; current != nil {
 			current.NextRefreshAfter = now.Add(refreshFailureBackoff)
 			current.LastError = &Error{Message: err.Error()}
 			m.auths[id] = current
+			snapshot = current.Clone()
 		}
 		m.mu.Unlock()
+		if rh, ok := m.currentHook().(RefreshFailureHook); ok && snapshot != nil {
+			rh.OnRefreshFailed(ctx, snapshot, err)
+		}
 		return
 	}
 	if updated == nil {
@@ -1600,6 +2010,18 @@ type RoundTripperProvider interface {
 	RoundTripperFor(auth *Auth) http.RoundTripper
 }
 
+// EmbeddingExecutor is an optional interface implemented by provider executors that can
+// serve embedding requests. The request action (e.g. "embedContent") travels in req.Metadata["action"].
+type EmbeddingExecutor interface {
+	Embed(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error)
+}
+
+// LocalTokenCounter is an optional interface implemented by provider executors whose
+// CountTokens estimates locally instead of calling the upstream API.
+type LocalTokenCounter interface {
+	CountsTokensLocally() bool
+}
+
 // RequestPreparer is an optional interface that provider executors can implement
 // to mutate outbound HTTP requests with provider credentials.
 type RequestPreparer interface {
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ExecutionAttempt describes a single executor call made by the Manager for one credential.
+// BeforeExecute hooks may replace Request, Options and RoundTripper; the changes apply to this
+// attempt only, so a retry with another credential starts from the original request again.
+type ExecutionAttempt struct {
+	// Provider is the executor identifier handling the attempt.
+	Provider string
+	// Auth is a snapshot of the selected credential.
+	Auth *Auth
+	// Request is the provider facing request after model rewriting.
+	Request cliproxyexecutor.Request
+	// Options carries the execution options passed to the executor.
+	Options cliproxyexecutor.Options
+	// RoundTripper is the per-auth transport, or nil when the executor uses its default.
+	RoundTripper http.RoundTripper
+	// Attempt counts the credentials tried for the request, starting at 1.
+	Attempt int
+	// Stream reports whether the attempt is a streaming execution.
+	Stream bool
+}
+
+// ExecutionHook observes and adjusts executor calls. Hooks run synchronously on the request
+// path in registration order and must be safe for concurrent use.
+type ExecutionHook interface {
+	// BeforeExecute runs before the executor is called.
+	BeforeExecute(ctx context.Context, attempt *ExecutionAttempt)
+	// AfterExecute runs once the attempt has finished. For streams it runs after the last chunk
+	// with an empty response and the first stream error, if any.
+	AfterExecute(ctx context.Context, attempt *ExecutionAttempt, resp cliproxyexecutor.Response, err error)
+	// OnStreamChunk runs for every chunk before it is forwarded to the caller.
+	OnStreamChunk(ctx context.Context, attempt *ExecutionAttempt, chunk cliproxyexecutor.StreamChunk)
+}
+
+// AddExecutionHook registers a hook invoked around every executor call.
+func (m *Manager) AddExecutionHook(hook ExecutionHook) {
+	if m == nil || hook == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executionHooks = append(append([]ExecutionHook(nil), m.executionHooks...), hook)
+}
+
+// attemptHooks binds the registered execution hooks to one attempt. A nil *attemptHooks is
+// valid and does nothing, which keeps the execute loops free of conditionals.
+type attemptHooks struct {
+	hooks   []ExecutionHook
+	attempt *ExecutionAttempt
+}
+
+// executionHooksFor returns the hooks for a new attempt, or nil when none are registered.
+func (m *Manager) executionHooksFor(provider string, auth *Auth, attempt int, stream bool) *attemptHooks {
+	m.mu.RLock()
+	hooks := m.executionHooks
+	m.mu.RUnlock()
+	if len(hooks) == 0 {
+		return nil
+	}
+	return &attemptHooks{
+		hooks: hooks,
+		attempt: &ExecutionAttempt{
+			Provider: provider,
+			Auth:     auth.Clone(),
+			Attempt:  attempt,
+			Stream:   stream,
+		},
+	}
+}
+
+// before runs BeforeExecute and applies the hooks' changes to req and opts. The returned
+// context carries the attempt's round tripper.
+func (h *attemptHooks) before(ctx context.Context, req *cliproxyexecutor.Request, opts *cliproxyexecutor.Options) context.Context {
+	if h == nil {
+		return ctx
+	}
+	h.attempt.Request = *req
+	h.attempt.Options = *opts
+	if rt, ok := ctx.Value(roundTripperContextKey{}).(http.RoundTripper); ok {
+		h.attempt.RoundTripper = rt
+	}
+	for _, hook := range h.hooks {
+		hook.BeforeExecute(ctx, h.attempt)
+	}
+	*req = h.attempt.Request
+	*opts = h.attempt.Options
+	if rt := h.attempt.RoundTripper; rt != nil {
+		ctx = context.WithValue(ctx, roundTripperContextKey{}, rt)
+		ctx = context.WithValue(ctx, "cliproxy.roundtripper", rt)
+	}
+	return ctx
+}
+
+func (h *attemptHooks) after(ctx context.Context, resp cliproxyexecutor.Response, err error) {
+	if h == nil {
+		return
+	}
+	for _, hook := range h.hooks {
+		hook.AfterExecute(ctx, h.attempt, resp, err)
+	}
+}
+
+func (h *attemptHooks) chunk(ctx context.Context, chunk cliproxyexecutor.StreamChunk) {
+	if h == nil {
+		return
+	}
+	for _, hook := range h.hooks {
+		hook.OnStreamChunk(ctx, h.attempt, chunk)
+	}
+}
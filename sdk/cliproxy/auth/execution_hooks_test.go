@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type recordingExecutionHook struct {
+	mu     sync.Mutex
+	before []string
+	after  []error
+}
+
+func (h *recordingExecutionHook) BeforeExecute(_ context.Context, attempt *ExecutionAttempt) {
+	h.mu.Lock()
+	h.before = append(h.before, attempt.Provider+"/"+attempt.Request.Model)
+	h.mu.Unlock()
+	attempt.Request.Payload = []byte(`{"hooked":true}`)
+}
+
+func (h *recordingExecutionHook) AfterExecute(_ context.Context, _ *ExecutionAttempt, _ cliproxyexecutor.Response, err error) {
+	h.mu.Lock()
+	h.after = append(h.after, err)
+	h.mu.Unlock()
+}
+
+func (h *recordingExecutionHook) OnStreamChunk(context.Context, *ExecutionAttempt, cliproxyexecutor.StreamChunk) {
+}
+
+func TestManagerExecuteInvokesExecutionHooksPerAttempt(t *testing.T) {
+	m, primary, secondary := newFallbackTestManager(t, fallbackStatusError{code: http.StatusTooManyRequests})
+	hook := &recordingExecutionHook{}
+	m.AddExecutionHook(hook)
+
+	req := cliproxyexecutor.Request{Model: "fallback-primary-model", Payload: []byte(`{}`)}
+	resp, err := m.Execute(context.Background(), []string{"fallback-primary"}, req, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := string(resp.Payload); got != `{"hooked":true}` {
+		t.Fatalf("payload = %s, want the hook's rewritten request", got)
+	}
+	if len(primary.models) != 1 || len(secondary.models) != 1 {
+		t.Fatalf("attempts = %v / %v, want one per provider", primary.models, secondary.models)
+	}
+	want := []string{"fallback-primary/fallback-primary-model", "fallback-secondary/fallback-secondary-model"}
+	if len(hook.before) != len(want) || hook.before[0] != want[0] || hook.before[1] != want[1] {
+		t.Fatalf("before = %v, want %v", hook.before, want)
+	}
+	if len(hook.after) != 2 || hook.after[0] == nil || hook.after[1] != nil {
+		t.Fatalf("after errors = %v, want the primary failure then success", hook.after)
+	}
+}
@@ -0,0 +1,293 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	defaultProbeInterval    = 15 * time.Minute
+	defaultProbeTimeout     = 30 * time.Second
+	defaultProbeConcurrency = 4
+	// probeTick is how often the loop checks whether a round is due, so interval changes
+	// and enabling the prober through a config reload take effect promptly.
+	probeTick = 30 * time.Second
+
+	probeModeCountTokens = "count-tokens"
+)
+
+// probePayload is the OpenAI chat request sent by generate probes.
+const probePayload = `{"model":"","messages":[{"role":"user","content":"ping"}],"max_tokens":1,"stream":false}`
+
+// ProbeResult is the outcome of the latest health probe of a credential.
+type ProbeResult struct {
+	At         time.Time     `json:"at"`
+	Model      string        `json:"model"`
+	Success    bool          `json:"success"`
+	Latency    time.Duration `json:"latency"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// healthProber holds the prober state owned by a Manager.
+type healthProber struct {
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	lastRound time.Time
+	results   map[string]ProbeResult
+}
+
+// SetHealthProbeConfig updates the settings used by the health probe loop.
+func (m *Manager) SetHealthProbeConfig(cfg internalconfig.HealthProbeConfig) {
+	if m == nil {
+		return
+	}
+	cfg.Models = append([]internalconfig.HealthProbeModel(nil), cfg.Models...)
+	m.healthProbe.Store(&cfg)
+}
+
+func (m *Manager) healthProbeConfig() *internalconfig.HealthProbeConfig {
+	cfg, _ := m.healthProbe.Load().(*internalconfig.HealthProbeConfig)
+	return cfg
+}
+
+// StartHealthProbe launches the background loop that probes every enabled credential once per
+// configured interval. The loop idles while probing is disabled. Starting it again restarts it.
+func (m *Manager) StartHealthProbe(parent context.Context) {
+	if m == nil {
+		return
+	}
+	m.prober.mu.Lock()
+	if m.prober.cancel != nil {
+		m.prober.cancel()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	m.prober.cancel = cancel
+	m.prober.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(probeTick)
+		defer ticker.Stop()
+		for {
+			m.maybeRunProbeRound(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StopHealthProbe cancels the background probe loop, if running.
+func (m *Manager) StopHealthProbe() {
+	if m == nil {
+		return
+	}
+	m.prober.mu.Lock()
+	defer m.prober.mu.Unlock()
+	if m.prober.cancel != nil {
+		m.prober.cancel()
+		m.prober.cancel = nil
+	}
+}
+
+// LastProbe returns the latest health probe result of the credential.
+func (m *Manager) LastProbe(authID string) (ProbeResult, bool) {
+	if m == nil {
+		return ProbeResult{}, false
+	}
+	m.prober.mu.Lock()
+	defer m.prober.mu.Unlock()
+	result, ok := m.prober.results[authID]
+	return result, ok
+}
+
+func (m *Manager) maybeRunProbeRound(ctx context.Context) {
+	cfg := m.healthProbeConfig()
+	if cfg == nil || !cfg.Enable {
+		return
+	}
+	interval := time.Duration(cfg.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultProbeInterval
+	}
+	m.prober.mu.Lock()
+	due := time.Since(m.prober.lastRound) >= interval
+	if due {
+		m.prober.lastRound = time.Now()
+	}
+	m.prober.mu.Unlock()
+	if due {
+		m.ProbeAll(ctx)
+	}
+}
+
+// ProbeAll probes every enabled credential once and waits for the round to finish.
+func (m *Manager) ProbeAll(ctx context.Context) {
+	cfg := m.healthProbeConfig()
+	if cfg == nil {
+		cfg = &internalconfig.HealthProbeConfig{}
+	}
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultProbeConcurrency
+	}
+	auths := m.snapshotAuths()
+	m.pruneProbeResults(auths)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, auth := range auths {
+		if auth.Disabled || auth.Status == StatusDisabled {
+			continue
+		}
+		model := probeModelFor(cfg, auth)
+		if model == "" {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(auth *Auth, model string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			m.probeAuth(ctx, cfg, auth, model)
+		}(auth, model)
+	}
+	wg.Wait()
+}
+
+// pruneProbeResults drops the results of credentials that were removed or disabled since
+// they are no longer probed.
+func (m *Manager) pruneProbeResults(auths []*Auth) {
+	current := make(map[string]struct{}, len(auths))
+	for _, auth := range auths {
+		if !auth.Disabled && auth.Status != StatusDisabled {
+			current[auth.ID] = struct{}{}
+		}
+	}
+	m.prober.mu.Lock()
+	defer m.prober.mu.Unlock()
+	for id := range m.prober.results {
+		if _, ok := current[id]; !ok {
+			delete(m.prober.results, id)
+		}
+	}
+}
+
+// probeModelFor returns the configured model for the credential's provider when the credential
+// serves it, or else the first model the credential registered.
+func probeModelFor(cfg *internalconfig.HealthProbeConfig, auth *Auth) string {
+	reg := registry.GetGlobalRegistry()
+	for _, entry := range cfg.Models {
+		model := strings.TrimSpace(entry.Model)
+		if strings.EqualFold(strings.TrimSpace(entry.Provider), auth.Provider) && model != "" && reg.ClientSupportsModel(auth.ID, model) {
+			return model
+		}
+	}
+	models := reg.GetModelsForClient(auth.ID)
+	if len(models) == 0 || models[0] == nil {
+		return ""
+	}
+	return models[0].ID
+}
+
+func (m *Manager) probeAuth(ctx context.Context, cfg *internalconfig.HealthProbeConfig, auth *Auth, model string) {
+	executor := m.executorFor(auth.Provider)
+	if executor == nil {
+		return
+	}
+	if state := auth.ModelStates[model]; state != nil && state.NextRetryAfter.After(time.Now()) {
+		// Already cooling down; real traffic will not use it before the retry time either.
+		return
+	}
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if rt := m.roundTripperFor(auth); rt != nil {
+		probeCtx = context.WithValue(probeCtx, roundTripperContextKey{}, rt)
+		probeCtx = context.WithValue(probeCtx, "cliproxy.roundtripper", rt)
+	}
+	probeCtx = sdktranslator.WithPipeline(probeCtx, m.TranslatorPipeline())
+
+	payload, _ := sjson.SetBytes([]byte(probePayload), "model", model)
+	req := cliproxyexecutor.Request{Model: model, Payload: payload}
+	req.Model, req.Metadata = rewriteModelForAuth(model, req.Metadata, auth)
+	req.Model, req.Metadata = m.applyOAuthModelMapping(auth, req.Model, req.Metadata)
+	opts := cliproxyexecutor.Options{
+		OriginalRequest: payload,
+		SourceFormat:    sdktranslator.FromString("openai"),
+	}
+
+	start := time.Now()
+	var err error
+	if strings.EqualFold(strings.TrimSpace(cfg.Mode), probeModeCountTokens) && !countsTokensLocally(executor) {
+		_, err = executor.CountTokens(probeCtx, auth, req, opts)
+	} else {
+		_, err = executor.Execute(probeCtx, auth, req, opts)
+	}
+	result := ProbeResult{At: start.UTC(), Model: model, Success: err == nil, Latency: time.Since(start)}
+	if err != nil {
+		result.Error = err.Error()
+		var se cliproxyexecutor.StatusError
+		if errors.As(err, &se) && se != nil {
+			result.StatusCode = se.StatusCode()
+		}
+	}
+
+	m.prober.mu.Lock()
+	if m.prober.results == nil {
+		m.prober.results = make(map[string]ProbeResult)
+	}
+	m.prober.results[auth.ID] = result
+	m.prober.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+	if err == nil {
+		m.MarkResult(ctx, Result{AuthID: auth.ID, Provider: auth.Provider, Model: model, Success: true})
+		return
+	}
+	// Only failures that say something about the credential feed its model state; a provider
+	// rejecting the probe payload itself (400, 422, ...) or a probe timeout does not.
+	if probeFailureCounts(result.StatusCode) {
+		markErr := &Error{Message: result.Error, HTTPStatus: result.StatusCode}
+		m.MarkResult(ctx, Result{AuthID: auth.ID, Provider: auth.Provider, Model: model, Error: markErr, RetryAfter: retryAfterFromError(err)})
+	} else {
+		log.Debugf("health probe of %s (%s) failed: %v", auth.ID, model, err)
+	}
+}
+
+// countsTokensLocally reports whether CountTokens never reaches the upstream API, in which case
+// count-tokens probes fall back to a generate request.
+func countsTokensLocally(executor ProviderExecutor) bool {
+	local, ok := executor.(LocalTokenCounter)
+	return ok && local.CountsTokensLocally()
+}
+
+func probeFailureCounts(status int) bool {
+	switch status {
+	case 401, 402, 403, 404, 429:
+		return true
+	default:
+		return status >= 500
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManagerProbeAllMarksModelState(t *testing.T) {
+	m, primary, _ := newFallbackTestManager(t, fallbackStatusError{code: http.StatusUnauthorized})
+	m.SetHealthProbeConfig(internalconfig.HealthProbeConfig{Enable: true})
+
+	m.ProbeAll(context.Background())
+
+	bad, ok := m.LastProbe("fallback-primary-auth")
+	if !ok || bad.Success || bad.StatusCode != http.StatusUnauthorized || bad.Model != "fallback-primary-model" {
+		t.Fatalf("primary probe = %+v, want failed 401 on fallback-primary-model", bad)
+	}
+	if len(primary.models) != 1 {
+		t.Fatalf("primary executor calls = %v, want one probe", primary.models)
+	}
+	auth, _ := m.GetByID("fallback-primary-auth")
+	state := auth.ModelStates["fallback-primary-model"]
+	if state == nil || !state.Unavailable || state.NextRetryAfter.IsZero() {
+		t.Fatalf("model state = %+v, want unavailable with a retry time", state)
+	}
+
+	good, ok := m.LastProbe("fallback-secondary-auth")
+	if !ok || !good.Success {
+		t.Fatalf("secondary probe = %+v, want success", good)
+	}
+
+	// A credential cooling down is not probed again until its retry time.
+	m.ProbeAll(context.Background())
+	if len(primary.models) != 1 {
+		t.Fatalf("primary executor calls = %v, want cooled-down model skipped", primary.models)
+	}
+}
+
+// localCountingExecutor counts tokens without calling upstream, like the tiktoken-based executors.
+type localCountingExecutor struct {
+	*fallbackTestExecutor
+}
+
+func (e localCountingExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (localCountingExecutor) CountsTokensLocally() bool { return true }
+
+func TestManagerProbeAllCountTokensFallsBackForLocalCounters(t *testing.T) {
+	m, primary, _ := newFallbackTestManager(t, fallbackStatusError{code: http.StatusUnauthorized})
+	m.RegisterExecutor(localCountingExecutor{primary})
+	m.SetHealthProbeConfig(internalconfig.HealthProbeConfig{Enable: true, Mode: "count-tokens"})
+
+	m.ProbeAll(context.Background())
+
+	if result, _ := m.LastProbe("fallback-primary-auth"); result.Success || len(primary.models) != 1 {
+		t.Fatalf("probe = %+v after %d generate calls, want a failed generate probe", result, len(primary.models))
+	}
+
+	secondary, _ := m.GetByID("fallback-secondary-auth")
+	secondary.Disabled = true
+	if _, err := m.Update(context.Background(), secondary); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	m.ProbeAll(context.Background())
+	if _, ok := m.LastProbe("fallback-secondary-auth"); ok {
+		t.Fatalf("disabled credential kept its probe result")
+	}
+}
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ServedModelHeader is the response header carrying the model that actually served a request
+// whose model has a fallback chain configured.
+const ServedModelHeader = "X-CLIProxy-Served-Model"
+
+type modelFallbackTable struct {
+	// chains maps the requested model (lower) -> ordered fallback models.
+	chains map[string][]string
+}
+
+func compileModelFallbackTable(entries []internalconfig.ModelFallback) *modelFallbackTable {
+	out := &modelFallbackTable{}
+	for _, entry := range entries {
+		model := strings.TrimSpace(entry.Model)
+		if model == "" {
+			continue
+		}
+		key := strings.ToLower(model)
+		if out.chains != nil {
+			if _, exists := out.chains[key]; exists {
+				continue
+			}
+		}
+		seen := map[string]struct{}{key: {}}
+		chain := make([]string, 0, len(entry.Fallbacks))
+		for _, fallback := range entry.Fallbacks {
+			fallback = strings.TrimSpace(fallback)
+			fallbackKey := strings.ToLower(fallback)
+			if fallback == "" {
+				continue
+			}
+			if _, dup := seen[fallbackKey]; dup {
+				continue
+			}
+			seen[fallbackKey] = struct{}{}
+			chain = append(chain, fallback)
+		}
+		if len(chain) == 0 {
+			continue
+		}
+		if out.chains == nil {
+			out.chains = make(map[string][]string)
+		}
+		out.chains[key] = chain
+	}
+	return out
+}
+
+// SetModelFallbacks updates the cross-provider fallback chains applied by Execute, ExecuteStream
+// and ExecuteCount. A chain is only walked when the requested model is not found upstream or all
+// of its credentials are cooling down; the inbound request format is preserved so responses are
+// still translated back to the caller's schema. Streams fall back only while opening, before the
+// first chunk is sent: an error delivered inside an open stream reaches the client unchanged.
+func (m *Manager) SetModelFallbacks(entries []internalconfig.ModelFallback) {
+	if m == nil {
+		return
+	}
+	m.modelFallbacks.Store(compileModelFallbackTable(entries))
+}
+
+// modelFallbackChain returns the configured fallback models for model, if any.
+func (m *Manager) modelFallbackChain(model string) []string {
+	if m == nil {
+		return nil
+	}
+	table, _ := m.modelFallbacks.Load().(*modelFallbackTable)
+	if table == nil || table.chains == nil {
+		return nil
+	}
+	return table.chains[strings.ToLower(strings.TrimSpace(model))]
+}
+
+// executeWithModelFallbacks runs the request for the requested model and, when it cannot be served,
+// for each configured fallback model in turn. The error of the requested model is returned when the
+// whole chain fails so clients still see why their model was unavailable.
+func executeWithModelFallbacks[T any](ctx context.Context, m *Manager, providers []string, req cliproxyexecutor.Request, run func([]string, cliproxyexecutor.Request) (T, error)) (T, error) {
+	chain := m.modelFallbackChain(req.Model)
+	result, err := run(providers, req)
+	if len(chain) == 0 {
+		return result, err
+	}
+	if err == nil {
+		reportServedModel(ctx, req.Model)
+		return result, nil
+	}
+
+	lastErr := err
+	policy := sdkaccess.ClientPolicyFromExecution(req.Metadata)
+	for _, fallback := range chain {
+		if !isModelUnavailableError(lastErr) || ctx.Err() != nil {
+			break
+		}
+		if !policy.AllowsModel(fallback) {
+			log.Debugf("model fallback %s -> %s skipped: not allowed for the client key", req.Model, fallback)
+			continue
+		}
+		fallbackProviders := policy.FilterProviders(registry.GetGlobalRegistry().GetModelProviders(fallback))
+		if len(fallbackProviders) == 0 {
+			log.Debugf("model fallback %s -> %s skipped: no provider serves the fallback model", req.Model, fallback)
+			continue
+		}
+		logEntryWithRequestID(ctx).Infof("model %s unavailable, falling back to %s", req.Model, fallback)
+		next, errNext := run(fallbackProviders, fallbackRequest(req, fallback))
+		if errNext == nil {
+			reportServedModel(ctx, fallback)
+			return next, nil
+		}
+		log.Debugf("model fallback %s -> %s failed: %v", req.Model, fallback, errNext)
+		lastErr = errNext
+	}
+	return result, err
+}
+
+// isModelUnavailableError reports whether err means the model itself could not be served: the
+// upstream does not know it, the manager has no credential for it, or every credential for it
+// is cooling down. A 429 counts as the latter because the manager only returns it once each
+// credential has been tried and cooled. Request, authentication and server errors do not
+// trigger a fallback.
+func isModelUnavailableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var cooldownErr *modelCooldownError
+	if errors.As(err, &cooldownErr) {
+		return true
+	}
+	var authErr *Error
+	if errors.As(err, &authErr) && (authErr.Code == "auth_not_found" || authErr.Code == "auth_unavailable") {
+		return true
+	}
+	switch statusCodeFromError(err) {
+	case http.StatusNotFound, http.StatusTooManyRequests:
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "model_not_found") || strings.Contains(message, "model not found")
+}
+
+// fallbackRequest retargets req at model, dropping metadata that only describes the original model.
+func fallbackRequest(req cliproxyexecutor.Request, model string) cliproxyexecutor.Request {
+	out := req
+	out.Model = model
+	if len(req.Metadata) > 0 {
+		out.Metadata = make(map[string]any, len(req.Metadata))
+		for k, v := range req.Metadata {
+			if k == util.ThinkingOriginalModelMetadataKey || k == util.ModelMappingOriginalModelMetadataKey {
+				continue
+			}
+			out.Metadata[k] = v
+		}
+	}
+	if gjson.GetBytes(req.Payload, "model").Type == gjson.String {
+		if updated, errSet := sjson.SetBytes(req.Payload, "model", model); errSet == nil {
+			out.Payload = updated
+		}
+	}
+	return out
+}
+
+// reportServedModel exposes the model that served the request on the inbound HTTP response.
+func reportServedModel(ctx context.Context, model string) {
+	if ctx == nil || model == "" {
+		return
+	}
+	if writer, ok := ctx.Value("gin").(interface{ Header(key, value string) }); ok {
+		writer.Header(ServedModelHeader, model)
+	}
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type fallbackStatusError struct{ code int }
+
+func (e fallbackStatusError) Error() string   { return http.StatusText(e.code) }
+func (e fallbackStatusError) StatusCode() int { return e.code }
+
+type fallbackTestExecutor struct {
+	provider  string
+	err       error
+	streamErr error
+
+	mu     sync.Mutex
+	models []string
+}
+
+func (e *fallbackTestExecutor) Identifier() string { return e.provider }
+
+func (e *fallbackTestExecutor) Execute(_ context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.mu.Lock()
+	e.models = append(e.models, req.Model)
+	e.mu.Unlock()
+	if e.err != nil {
+		return cliproxyexecutor.Response{}, e.err
+	}
+	return cliproxyexecutor.Response{Payload: req.Payload}, nil
+}
+
+func (e *fallbackTestExecutor) ExecuteStream(_ context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	e.mu.Lock()
+	e.models = append(e.models, req.Model)
+	e.mu.Unlock()
+	if e.err != nil {
+		return nil, e.err
+	}
+	chunks := make(chan cliproxyexecutor.StreamChunk, 2)
+	chunks <- cliproxyexecutor.StreamChunk{Payload: req.Payload}
+	if e.streamErr != nil {
+		chunks <- cliproxyexecutor.StreamChunk{Err: e.streamErr}
+	}
+	close(chunks)
+	return chunks, nil
+}
+
+func (e *fallbackTestExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	return auth, nil
+}
+
+func (e *fallbackTestExecutor) CountTokens(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return e.Execute(ctx, auth, req, opts)
+}
+
+type headerRecorder map[string]string
+
+func (h headerRecorder) Header(key, value string) { h[key] = value }
+
+func newFallbackTestManager(t *testing.T, primaryErr error) (*Manager, *fallbackTestExecutor, *fallbackTestExecutor) {
+	t.Helper()
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("fallback-primary-auth", "fallback-primary", []*registry.ModelInfo{{ID: "fallback-primary-model"}})
+	reg.RegisterClient("fallback-secondary-auth", "fallback-secondary", []*registry.ModelInfo{{ID: "fallback-secondary-model"}})
+	t.Cleanup(func() {
+		reg.UnregisterClient("fallback-primary-auth")
+		reg.UnregisterClient("fallback-secondary-auth")
+	})
+
+	primary := &fallbackTestExecutor{provider: "fallback-primary", err: primaryErr}
+	secondary := &fallbackTestExecutor{provider: "fallback-secondary"}
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(primary)
+	m.RegisterExecutor(secondary)
+	for _, auth := range []*Auth{
+		{ID: "fallback-primary-auth", Provider: "fallback-primary"},
+		{ID: "fallback-secondary-auth", Provider: "fallback-secondary"},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("Register(%s) error = %v", auth.ID, err)
+		}
+	}
+	m.SetModelFallbacks([]internalconfig.ModelFallback{{
+		Model:     "fallback-primary-model",
+		Fallbacks: []string{"fallback-unknown-model", "fallback-secondary-model"},
+	}})
+	return m, primary, secondary
+}
+
+func TestManagerExecuteFallsBackWhenModelUnavailable(t *testing.T) {
+	m, _, secondary := newFallbackTestManager(t, fallbackStatusError{code: http.StatusTooManyRequests})
+
+	headers := headerRecorder{}
+	ctx := context.WithValue(context.Background(), "gin", headers)
+	req := cliproxyexecutor.Request{Model: "fallback-primary-model", Payload: []byte(`{"model":"fallback-primary-model"}`)}
+	resp, err := m.Execute(ctx, []string{"fallback-primary"}, req, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := string(resp.Payload); got != `{"model":"fallback-secondary-model"}` {
+		t.Fatalf("payload = %s, want model rewritten to the fallback", got)
+	}
+	if len(secondary.models) != 1 || secondary.models[0] != "fallback-secondary-model" {
+		t.Fatalf("secondary models = %v, want [fallback-secondary-model]", secondary.models)
+	}
+	if got := headers[ServedModelHeader]; got != "fallback-secondary-model" {
+		t.Fatalf("%s = %q, want fallback-secondary-model", ServedModelHeader, got)
+	}
+}
+
+func TestManagerExecuteDoesNotFallBackOnRequestErrors(t *testing.T) {
+	for _, code := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError} {
+		m, _, secondary := newFallbackTestManager(t, fallbackStatusError{code: code})
+
+		req := cliproxyexecutor.Request{Model: "fallback-primary-model"}
+		_, err := m.Execute(context.Background(), []string{"fallback-primary"}, req, cliproxyexecutor.Options{})
+		if err == nil || statusCodeFromError(err) != code {
+			t.Fatalf("Execute() error = %v, want the primary %d error", err, code)
+		}
+		if len(secondary.models) != 0 {
+			t.Fatalf("%d: secondary models = %v, want no fallback attempt", code, secondary.models)
+		}
+	}
+}
+
+func TestManagerExecuteStreamFallsBackOnlyBeforeFirstChunk(t *testing.T) {
+	req := cliproxyexecutor.Request{Model: "fallback-primary-model", Payload: []byte(`{"model":"fallback-primary-model"}`)}
+	collect := func(m *Manager) []cliproxyexecutor.StreamChunk {
+		t.Helper()
+		chunks, err := m.ExecuteStream(context.Background(), []string{"fallback-primary"}, req, cliproxyexecutor.Options{})
+		if err != nil {
+			t.Fatalf("ExecuteStream() error = %v", err)
+		}
+		var got []cliproxyexecutor.StreamChunk
+		for chunk := range chunks {
+			got = append(got, chunk)
+		}
+		return got
+	}
+
+	// The primary fails to open the stream, so the fallback model serves it.
+	m, _, secondary := newFallbackTestManager(t, fallbackStatusError{code: http.StatusNotFound})
+	chunks := collect(m)
+	if len(chunks) != 1 || string(chunks[0].Payload) != `{"model":"fallback-secondary-model"}` {
+		t.Fatalf("chunks = %+v, want the fallback model's stream", chunks)
+	}
+	if len(secondary.models) != 1 {
+		t.Fatalf("secondary models = %v, want one fallback attempt", secondary.models)
+	}
+
+	// Once the first chunk is out, a failure is the client's to see.
+	m, primary, secondary := newFallbackTestManager(t, nil)
+	primary.streamErr = fallbackStatusError{code: http.StatusTooManyRequests}
+	chunks = collect(m)
+	if len(chunks) != 2 || string(chunks[0].Payload) != string(req.Payload) || statusCodeFromError(chunks[1].Err) != http.StatusTooManyRequests {
+		t.Fatalf("chunks = %+v, want the primary chunk followed by its error", chunks)
+	}
+	if len(secondary.models) != 0 {
+		t.Fatalf("secondary models = %v, want no fallback after the first chunk", secondary.models)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -142,7 +143,63 @@ func getAvailableAuths(auths []*Auth, provider, model string, now time.Time) ([]
 		return nil, &Error{Code: "auth_unavailable", Message: "no auth available"}
 	}
 
-	return available, nil
+	return highestPriority(available), nil
+}
+
+// AuthPriority returns the routing priority of auth from the "priority" attribute or metadata
+// entry. Missing or invalid priorities default to 0.
+func AuthPriority(auth *Auth) int {
+	if auth == nil {
+		return 0
+	}
+	if auth.Attributes != nil {
+		if raw := strings.TrimSpace(auth.Attributes["priority"]); raw != "" {
+			if v, err := strconv.Atoi(raw); err == nil {
+				return v
+			}
+		}
+	}
+	if auth.Metadata != nil {
+		switch v := auth.Metadata["priority"].(type) {
+		case float64:
+			return int(v)
+		case int:
+			return v
+		case string:
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// highestPriority keeps only the auths sharing the highest priority so lower priority
+// credentials are used only when every higher priority one is unavailable.
+func highestPriority(available []*Auth) []*Auth {
+	if len(available) < 2 {
+		return available
+	}
+	best := AuthPriority(available[0])
+	mixed := false
+	for _, candidate := range available[1:] {
+		if p := AuthPriority(candidate); p != best {
+			mixed = true
+			if p > best {
+				best = p
+			}
+		}
+	}
+	if !mixed {
+		return available
+	}
+	out := make([]*Auth, 0, len(available))
+	for _, candidate := range available {
+		if AuthPriority(candidate) == best {
+			out = append(out, candidate)
+		}
+	}
+	return out
 }
 
 // Pick selects the next available auth for the provider in a round-robin manner.
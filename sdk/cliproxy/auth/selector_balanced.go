@@ -0,0 +1,333 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ExecutionObserver is an optional Selector extension notified when the manager starts and
+// finishes serving a request with a picked auth. Load- and latency-aware selectors use it.
+type ExecutionObserver interface {
+	// ExecutionStarted fires right before the executor is invoked.
+	ExecutionStarted(authID string)
+	// ExecutionFinished fires once the execution (or stream) completes. latency is the time to
+	// the complete response, or to the first chunk for streams.
+	ExecutionFinished(authID string, latency time.Duration, success bool)
+}
+
+// ProviderOrderer is an optional Selector extension that decides the order in which providers
+// are tried for a model, replacing the default per-model provider rotation.
+type ProviderOrderer interface {
+	OrderProviders(model string, providers []string, auths []*Auth) []string
+}
+
+// ProviderPreferrer is an optional Selector extension naming the provider a request should try
+// first, such as the provider its conversation is bound to. The named provider is moved to the
+// front of the rotation or ProviderOrderer order; "" keeps that order.
+type ProviderPreferrer interface {
+	PreferredProvider(ctx context.Context, model string, opts cliproxyexecutor.Options) string
+}
+
+// AuthWeight returns the routing weight of auth from the "weight" attribute or metadata entry.
+// Missing or non-positive weights default to 1.
+func AuthWeight(auth *Auth) int {
+	if auth == nil {
+		return 1
+	}
+	if auth.Attributes != nil {
+		if raw := strings.TrimSpace(auth.Attributes["weight"]); raw != "" {
+			if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+				return v
+			}
+		}
+	}
+	if auth.Metadata != nil {
+		switch v := auth.Metadata["weight"].(type) {
+		case float64:
+			if v >= 1 {
+				return int(v)
+			}
+		case int:
+			if v > 0 {
+				return v
+			}
+		case string:
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 1
+}
+
+// WeightedRoundRobinSelector distributes requests proportionally to each auth's weight using
+// smooth weighted round-robin, so heavier credentials are interleaved rather than burst.
+// It also orders providers by their combined weight so a provider with heavier credentials
+// is tried first proportionally more often.
+type WeightedRoundRobinSelector struct {
+	mu      sync.Mutex
+	current map[string]map[string]int
+}
+
+// Pick selects the next available auth according to its weight.
+func (s *WeightedRoundRobinSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	available, err := getAvailableAuths(auths, provider, model, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(available))
+	weights := make([]int, len(available))
+	for i, candidate := range available {
+		ids[i] = candidate.ID
+		weights[i] = AuthWeight(candidate)
+	}
+	return available[s.next(provider+":"+model, ids, weights)], nil
+}
+
+// OrderProviders implements ProviderOrderer using the summed weight of available auths per provider.
+func (s *WeightedRoundRobinSelector) OrderProviders(model string, providers []string, auths []*Auth) []string {
+	if len(providers) < 2 {
+		return providers
+	}
+	now := time.Now()
+	totals := make(map[string]int, len(providers))
+	for _, candidate := range auths {
+		if blocked, _, _ := isAuthBlockedForModel(candidate, model, now); blocked {
+			continue
+		}
+		totals[candidate.Provider] += AuthWeight(candidate)
+	}
+	weights := make([]int, len(providers))
+	for i, provider := range providers {
+		weights[i] = totals[provider]
+	}
+	first := s.next("providers:"+model, providers, weights)
+
+	ordered := make([]string, 0, len(providers))
+	ordered = append(ordered, providers[first])
+	rest := make([]int, 0, len(providers)-1)
+	for i := range providers {
+		if i != first {
+			rest = append(rest, i)
+		}
+	}
+	sort.SliceStable(rest, func(a, b int) bool { return weights[rest[a]] > weights[rest[b]] })
+	for _, i := range rest {
+		ordered = append(ordered, providers[i])
+	}
+	return ordered
+}
+
+// next runs one smooth weighted round-robin step for key and returns the chosen index.
+func (s *WeightedRoundRobinSelector) next(key string, ids []string, weights []int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		s.current = make(map[string]map[string]int)
+	}
+	state := s.current[key]
+	if state == nil {
+		state = make(map[string]int, len(ids))
+		s.current[key] = state
+	}
+	present := make(map[string]struct{}, len(ids))
+	total := 0
+	best := 0
+	for i, id := range ids {
+		present[id] = struct{}{}
+		w := max(weights[i], 0)
+		total += w
+		state[id] += w
+		if state[id] > state[ids[best]] {
+			best = i
+		}
+	}
+	state[ids[best]] -= total
+	for id := range state {
+		if _, ok := present[id]; !ok {
+			delete(state, id)
+		}
+	}
+	return best
+}
+
+// LeastInFlightSelector picks the available auth with the fewest concurrent executions,
+// rotating between equally loaded auths.
+type LeastInFlightSelector struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+	cursors  map[string]int
+}
+
+// Pick selects the least loaded available auth.
+func (s *LeastInFlightSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	available, err := getAvailableAuths(auths, provider, model, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lowest := -1
+	var idle []*Auth
+	for _, candidate := range available {
+		load := s.inFlight[candidate.ID]
+		switch {
+		case lowest < 0 || load < lowest:
+			lowest = load
+			idle = append(idle[:0], candidate)
+		case load == lowest:
+			idle = append(idle, candidate)
+		}
+	}
+	if s.cursors == nil {
+		s.cursors = make(map[string]int)
+	}
+	key := provider + ":" + model
+	index := s.cursors[key]
+	if index >= 2_147_483_640 {
+		index = 0
+	}
+	s.cursors[key] = index + 1
+	return idle[index%len(idle)], nil
+}
+
+// InFlight returns the number of executions currently tracked for authID.
+func (s *LeastInFlightSelector) InFlight(authID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inFlight[authID]
+}
+
+// ExecutionStarted implements ExecutionObserver.
+func (s *LeastInFlightSelector) ExecutionStarted(authID string) {
+	s.mu.Lock()
+	if s.inFlight == nil {
+		s.inFlight = make(map[string]int)
+	}
+	s.inFlight[authID]++
+	s.mu.Unlock()
+}
+
+// ExecutionFinished implements ExecutionObserver.
+func (s *LeastInFlightSelector) ExecutionFinished(authID string, _ time.Duration, _ bool) {
+	s.mu.Lock()
+	if s.inFlight[authID] <= 1 {
+		delete(s.inFlight, authID)
+	} else {
+		s.inFlight[authID]--
+	}
+	s.mu.Unlock()
+}
+
+const (
+	// latencySmoothing is the weight of the newest sample in the latency moving average.
+	latencySmoothing = 0.3
+	// latencySampleTTL bounds how long a latency estimate is trusted before the auth is re-probed.
+	latencySampleTTL = 5 * time.Minute
+)
+
+type latencyEstimate struct {
+	average time.Duration
+	updated time.Time
+}
+
+// LatencyAwareSelector prefers the available auth with the lowest recent success latency.
+// Auths without a fresh estimate are tried first so every credential keeps being measured.
+type LatencyAwareSelector struct {
+	mu        sync.Mutex
+	estimates map[string]latencyEstimate
+	cursors   map[string]int
+	now       func() time.Time
+}
+
+// Pick selects the fastest available auth.
+func (s *LatencyAwareSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	now := s.clock()
+	available, err := getAvailableAuths(auths, provider, model, now)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var (
+		unmeasured []*Auth
+		fastest    *Auth
+		best       time.Duration
+	)
+	for _, candidate := range available {
+		estimate, ok := s.estimates[candidate.ID]
+		if !ok || now.Sub(estimate.updated) > latencySampleTTL {
+			unmeasured = append(unmeasured, candidate)
+			continue
+		}
+		if fastest == nil || estimate.average < best {
+			fastest = candidate
+			best = estimate.average
+		}
+	}
+	if len(unmeasured) == 0 {
+		return fastest, nil
+	}
+	if s.cursors == nil {
+		s.cursors = make(map[string]int)
+	}
+	key := provider + ":" + model
+	index := s.cursors[key]
+	if index >= 2_147_483_640 {
+		index = 0
+	}
+	s.cursors[key] = index + 1
+	return unmeasured[index%len(unmeasured)], nil
+}
+
+// Latency returns the current latency estimate for authID.
+func (s *LatencyAwareSelector) Latency(authID string) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	estimate, ok := s.estimates[authID]
+	return estimate.average, ok
+}
+
+// ExecutionStarted implements ExecutionObserver.
+func (s *LatencyAwareSelector) ExecutionStarted(string) {}
+
+// ExecutionFinished implements ExecutionObserver. Only successful executions update the estimate;
+// failures are handled by the manager's cooldown logic.
+func (s *LatencyAwareSelector) ExecutionFinished(authID string, latency time.Duration, success bool) {
+	if !success || latency <= 0 {
+		return
+	}
+	now := s.clock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.estimates == nil {
+		s.estimates = make(map[string]latencyEstimate)
+	}
+	estimate, ok := s.estimates[authID]
+	if ok && now.Sub(estimate.updated) <= latencySampleTTL {
+		estimate.average = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(estimate.average))
+	} else {
+		estimate.average = latency
+	}
+	estimate.updated = now
+	s.estimates[authID] = estimate
+}
+
+func (s *LatencyAwareSelector) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
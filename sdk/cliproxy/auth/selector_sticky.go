@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// DefaultAffinityHeader is the client header read for an explicit conversation key.
+	DefaultAffinityHeader = "X-Session-Id"
+	// DefaultAffinityMaxEntries bounds the conversations remembered by a StickySelector.
+	DefaultAffinityMaxEntries = 10000
+)
+
+// StickySelector routes every turn of a conversation to the same credential so upstream prompt
+// caches, which are scoped to an account, keep hitting. Bindings are keyed on model and
+// conversation, and the bound provider is tried first when several providers serve the model.
+// New conversations and conversations whose credential is cooling down or already tried are
+// placed with the fallback selector and re-bound to the credential it picks.
+type StickySelector struct {
+	header     string
+	maxEntries int
+	fallback   Selector
+
+	mu       sync.Mutex
+	order    *list.List
+	bindings map[string]*list.Element
+}
+
+type stickyBinding struct {
+	key      string
+	provider string
+	authID   string
+}
+
+// NewStickySelector constructs a conversation-affinity selector. An empty header defaults to
+// DefaultAffinityHeader, a non-positive maxEntries to DefaultAffinityMaxEntries and a nil
+// fallback to round-robin.
+func NewStickySelector(header string, maxEntries int, fallback Selector) *StickySelector {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		header = DefaultAffinityHeader
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultAffinityMaxEntries
+	}
+	if fallback == nil {
+		fallback = &RoundRobinSelector{}
+	}
+	return &StickySelector{
+		header:     header,
+		maxEntries: maxEntries,
+		fallback:   fallback,
+		order:      list.New(),
+		bindings:   make(map[string]*list.Element),
+	}
+}
+
+// Pick selects the credential bound to the request's conversation, or binds a new one.
+func (s *StickySelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	conversation := ConversationKey(ctx, s.header, opts.OriginalRequest)
+	if conversation == "" {
+		return s.fallback.Pick(ctx, provider, model, opts, auths)
+	}
+	available, err := getAvailableAuths(auths, provider, model, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	key := model + ":" + conversation
+	if binding, ok := s.lookup(key); ok && binding.provider == provider {
+		for _, candidate := range available {
+			if candidate.ID == binding.authID {
+				return candidate, nil
+			}
+		}
+	}
+	selected, err := s.fallback.Pick(ctx, provider, model, opts, available)
+	if err != nil || selected == nil {
+		return selected, err
+	}
+	s.bind(key, provider, selected.ID)
+	return selected, nil
+}
+
+// PreferredProvider implements ProviderPreferrer with the provider the request's conversation is
+// bound to.
+func (s *StickySelector) PreferredProvider(ctx context.Context, model string, opts cliproxyexecutor.Options) string {
+	conversation := ConversationKey(ctx, s.header, opts.OriginalRequest)
+	if conversation == "" {
+		return ""
+	}
+	binding, _ := s.lookup(model + ":" + conversation)
+	return binding.provider
+}
+
+// Len returns the number of remembered conversations.
+func (s *StickySelector) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *StickySelector) lookup(key string) (stickyBinding, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.bindings[key]
+	if !ok {
+		return stickyBinding{}, false
+	}
+	s.order.MoveToFront(elem)
+	return *elem.Value.(*stickyBinding), true
+}
+
+func (s *StickySelector) bind(key, provider, authID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.bindings[key]; ok {
+		binding := elem.Value.(*stickyBinding)
+		binding.provider = provider
+		binding.authID = authID
+		s.order.MoveToFront(elem)
+		return
+	}
+	s.bindings[key] = s.order.PushFront(&stickyBinding{key: key, provider: provider, authID: authID})
+	for s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.bindings, oldest.Value.(*stickyBinding).key)
+	}
+}
+
+// conversationPrefix returns the raw messages up to and including the first user message. A
+// plain string (the Responses API "input" shorthand) is its own prefix.
+func conversationPrefix(messages gjson.Result) []string {
+	if messages.Type == gjson.String {
+		return []string{messages.Raw}
+	}
+	if !messages.IsArray() {
+		return nil
+	}
+	var prefix []string
+	for _, message := range messages.Array() {
+		prefix = append(prefix, message.Raw)
+		if role := message.Get("role").String(); role == "" || role == "user" {
+			return prefix
+		}
+	}
+	return nil
+}
+
+// ConversationKey derives a stable key identifying the conversation a request belongs to. It
+// prefers the explicit header, then the client supplied "metadata.user_id" and
+// "prompt_cache_key" fields, and finally hashes the system prompt and the messages up to the
+// first user message, which stay the same on every turn. It returns "" when the request carries
+// nothing to key on.
+func ConversationKey(ctx context.Context, header string, rawJSON []byte) string {
+	if header != "" && ctx != nil {
+		if headers, ok := ctx.Value("gin").(interface{ GetHeader(string) string }); ok && headers != nil {
+			if value := strings.TrimSpace(headers.GetHeader(header)); value != "" {
+				return "h:" + value
+			}
+		}
+	}
+	if len(rawJSON) == 0 || !gjson.ValidBytes(rawJSON) {
+		return ""
+	}
+	root := gjson.ParseBytes(rawJSON)
+	if userID := strings.TrimSpace(root.Get("metadata.user_id").String()); userID != "" {
+		return "u:" + userID
+	}
+	if cacheKey := strings.TrimSpace(root.Get("prompt_cache_key").String()); cacheKey != "" {
+		return "c:" + cacheKey
+	}
+	var prefix []string
+	for _, path := range []string{"messages", "contents", "input"} {
+		if prefix = conversationPrefix(root.Get(path)); len(prefix) > 0 {
+			break
+		}
+	}
+	if len(prefix) == 0 {
+		return ""
+	}
+	hash := sha256.New()
+	for _, path := range []string{"system", "instructions", "systemInstruction", "system_instruction"} {
+		if value := root.Get(path); value.Exists() {
+			hash.Write([]byte(value.Raw))
+		}
+	}
+	for _, raw := range prefix {
+		hash.Write([]byte{0})
+		hash.Write([]byte(raw))
+	}
+	return "p:" + hex.EncodeToString(hash.Sum(nil)[:16])
+}
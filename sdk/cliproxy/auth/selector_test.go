@@ -3,9 +3,12 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 )
 
@@ -111,3 +114,264 @@ func TestRoundRobinSelectorPick_Concurrent(t *testing.T) {
 	default:
 	}
 }
+
+func TestWeightedRoundRobinSelectorPick_Proportional(t *testing.T) {
+	t.Parallel()
+
+	selector := &WeightedRoundRobinSelector{}
+	auths := []*Auth{
+		{ID: "paid", Attributes: map[string]string{"weight": "3"}},
+		{ID: "free", Metadata: map[string]any{"weight": float64(1)}},
+	}
+
+	counts := make(map[string]int)
+	var sequence []string
+	for i := 0; i < 8; i++ {
+		got, err := selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() #%d error = %v", i, err)
+		}
+		counts[got.ID]++
+		sequence = append(sequence, got.ID)
+	}
+	if counts["paid"] != 6 || counts["free"] != 2 {
+		t.Fatalf("counts = %v, want paid=6 free=2", counts)
+	}
+	// Smooth weighting interleaves the lighter credential instead of bursting.
+	if sequence[0] != "paid" {
+		t.Fatalf("sequence = %v, want the heavier credential first", sequence)
+	}
+	for i := 1; i < len(sequence); i++ {
+		if sequence[i] == "free" && sequence[i-1] == "free" {
+			t.Fatalf("sequence = %v, want the lighter credential interleaved", sequence)
+		}
+	}
+}
+
+func TestRoundRobinSelectorPick_PrefersHighestPriority(t *testing.T) {
+	t.Parallel()
+
+	selector := &RoundRobinSelector{}
+	auths := []*Auth{
+		{ID: "backup", Metadata: map[string]any{"priority": float64(-1)}},
+		{ID: "primary-a", Metadata: map[string]any{"priority": float64(5)}},
+		{ID: "primary-b", Attributes: map[string]string{"priority": "5"}},
+	}
+
+	for i := 0; i < 4; i++ {
+		got, err := selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() #%d error = %v", i, err)
+		}
+		if got.ID == "backup" {
+			t.Fatalf("Pick() #%d chose the lower priority credential", i)
+		}
+	}
+
+	auths[1].Disabled = true
+	auths[2].Disabled = true
+	got, err := selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+	if err != nil || got.ID != "backup" {
+		t.Fatalf("Pick() = %v, %v; want backup once higher priorities are unavailable", got, err)
+	}
+}
+
+func TestWeightedRoundRobinSelectorOrderProviders(t *testing.T) {
+	t.Parallel()
+
+	selector := &WeightedRoundRobinSelector{}
+	auths := []*Auth{
+		{ID: "k1", Provider: "gemini", Attributes: map[string]string{"weight": "4"}},
+		{ID: "o1", Provider: "gemini-cli"},
+	}
+	firsts := make(map[string]int)
+	for i := 0; i < 5; i++ {
+		order := selector.OrderProviders("m", []string{"gemini-cli", "gemini"}, auths)
+		if len(order) != 2 {
+			t.Fatalf("OrderProviders() = %v, want 2 providers", order)
+		}
+		firsts[order[0]]++
+	}
+	if firsts["gemini"] != 4 || firsts["gemini-cli"] != 1 {
+		t.Fatalf("first provider counts = %v, want gemini=4 gemini-cli=1", firsts)
+	}
+}
+
+func TestLeastInFlightSelectorPick(t *testing.T) {
+	t.Parallel()
+
+	selector := &LeastInFlightSelector{}
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+
+	selector.ExecutionStarted("a")
+	got, err := selector.Pick(context.Background(), "claude", "", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "b" {
+		t.Fatalf("Pick() auth.ID = %q, want %q", got.ID, "b")
+	}
+	selector.ExecutionFinished("a", 0, true)
+	if n := selector.InFlight("a"); n != 0 {
+		t.Fatalf("InFlight(a) = %d, want 0", n)
+	}
+}
+
+func TestLatencyAwareSelectorPick(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	selector := &LatencyAwareSelector{now: func() time.Time { return now }}
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+
+	selector.ExecutionFinished("a", 900*time.Millisecond, true)
+	// b has no estimate yet and is probed first.
+	got, err := selector.Pick(context.Background(), "codex", "", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "b" {
+		t.Fatalf("Pick() auth.ID = %q, want unmeasured %q", got.ID, "b")
+	}
+	selector.ExecutionFinished("b", 200*time.Millisecond, true)
+	selector.ExecutionFinished("b", 5*time.Second, false)
+	for i := 0; i < 3; i++ {
+		got, err = selector.Pick(context.Background(), "codex", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() #%d error = %v", i, err)
+		}
+		if got.ID != "b" {
+			t.Fatalf("Pick() #%d auth.ID = %q, want fastest %q", i, got.ID, "b")
+		}
+	}
+	// Stale estimates are re-probed.
+	now = now.Add(latencySampleTTL + time.Second)
+	selector.ExecutionFinished("b", 300*time.Millisecond, true)
+	got, _ = selector.Pick(context.Background(), "codex", "", cliproxyexecutor.Options{}, auths)
+	if got.ID != "a" {
+		t.Fatalf("Pick() auth.ID = %q, want stale %q", got.ID, "a")
+	}
+}
+
+type stickyTestHeaders map[string]string
+
+func (h stickyTestHeaders) GetHeader(key string) string { return h[key] }
+
+func TestStickySelectorPick_KeepsConversationOnOneAuth(t *testing.T) {
+	t.Parallel()
+
+	selector := NewStickySelector("", 2, nil)
+	auths := []*Auth{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	pick := func(ctx context.Context, body string, candidates []*Auth) string {
+		t.Helper()
+		got, err := selector.Pick(ctx, "claude", "", cliproxyexecutor.Options{OriginalRequest: []byte(body)}, candidates)
+		if err != nil {
+			t.Fatalf("Pick() error = %v", err)
+		}
+		return got.ID
+	}
+
+	turn1 := `{"system":"be brief","messages":[{"role":"user","content":"hi"}]}`
+	turn2 := `{"system":"be brief","messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"},{"role":"user","content":"more"}]}`
+	first := pick(context.Background(), turn1, auths)
+	for i := 0; i < 3; i++ {
+		if got := pick(context.Background(), turn2, auths); got != first {
+			t.Fatalf("turn %d auth = %q, want sticky %q", i+2, got, first)
+		}
+	}
+
+	// A cooling-down credential is replaced and the conversation re-bound to the new one.
+	cooling := make([]*Auth, 0, len(auths))
+	for _, auth := range auths {
+		clone := *auth
+		if clone.ID == first {
+			clone.Unavailable = true
+			clone.NextRetryAfter = time.Now().Add(time.Minute)
+		}
+		cooling = append(cooling, &clone)
+	}
+	moved := pick(context.Background(), turn2, cooling)
+	if moved == first {
+		t.Fatalf("Pick() kept cooling auth %q", first)
+	}
+	if got := pick(context.Background(), turn2, auths); got != moved {
+		t.Fatalf("auth after cooldown = %q, want re-bound %q", got, moved)
+	}
+
+	// The explicit header wins over the body, and the binding table stays bounded.
+	ctx := context.WithValue(context.Background(), "gin", stickyTestHeaders{DefaultAffinityHeader: "session-1"})
+	pick(ctx, turn1, auths)
+	pick(context.Background(), `{"messages":[{"role":"user","content":"other"}]}`, auths)
+	if n := selector.Len(); n != 2 {
+		t.Fatalf("Len() = %d, want 2", n)
+	}
+	if key := ConversationKey(ctx, DefaultAffinityHeader, []byte(turn1)); key != "h:session-1" {
+		t.Fatalf("ConversationKey() = %q, want the header value", key)
+	}
+}
+
+type stickyTestExecutor struct {
+	provider string
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (e *stickyTestExecutor) Identifier() string { return e.provider }
+
+func (e *stickyTestExecutor) Execute(_ context.Context, auth *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, auth.ID)
+	e.mu.Unlock()
+	return cliproxyexecutor.Response{Payload: []byte(auth.ID)}, nil
+}
+
+func (e *stickyTestExecutor) ExecuteStream(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e *stickyTestExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	return auth, nil
+}
+
+func (e *stickyTestExecutor) CountTokens(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return e.Execute(ctx, auth, req, opts)
+}
+
+func TestStickySelectorKeepsConversationOnOneProvider(t *testing.T) {
+	const model = "sticky-shared-model"
+	reg := registry.GetGlobalRegistry()
+	m := NewManager(nil, NewStickySelector("", 0, nil), nil)
+	for _, provider := range []string{"sticky-a", "sticky-b"} {
+		m.RegisterExecutor(&stickyTestExecutor{provider: provider})
+		for _, suffix := range []string{"-1", "-2"} {
+			id := provider + suffix
+			reg.RegisterClient(id, provider, []*registry.ModelInfo{{ID: model}})
+			t.Cleanup(func() { reg.UnregisterClient(id) })
+			if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: provider}); err != nil {
+				t.Fatalf("Register(%s) error = %v", id, err)
+			}
+		}
+	}
+
+	execute := func(body string) string {
+		t.Helper()
+		req := cliproxyexecutor.Request{Model: model}
+		opts := cliproxyexecutor.Options{OriginalRequest: []byte(body)}
+		resp, err := m.Execute(context.Background(), []string{"sticky-a", "sticky-b"}, req, opts)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return string(resp.Payload)
+	}
+
+	conversation := `{"messages":[{"role":"user","content":"hi"}]}`
+	first := execute(conversation)
+	for i := 0; i < 4; i++ {
+		if got := execute(conversation); got != first {
+			t.Fatalf("turn %d served by %q, want sticky %q", i+2, got, first)
+		}
+		// Other conversations keep advancing the provider rotation in between.
+		execute(fmt.Sprintf(`{"messages":[{"role":"user","content":"other %d"}]}`, i))
+	}
+}
@@ -180,6 +180,30 @@ func (m *ModelState) Clone() *ModelState {
 	return &copyState
 }
 
+// ApplyMetadataSettings copies operator settings persisted in Metadata by the management API
+// ("disabled", "label", "prefix" and "proxy_url") onto the auth so they survive reloads.
+func (a *Auth) ApplyMetadataSettings() {
+	if a == nil || a.Metadata == nil {
+		return
+	}
+	if disabled, ok := a.Metadata["disabled"].(bool); ok && disabled {
+		a.Disabled = true
+		a.Status = StatusDisabled
+	}
+	if label, ok := a.Metadata["label"].(string); ok && strings.TrimSpace(label) != "" {
+		a.Label = strings.TrimSpace(label)
+	}
+	if prefix, ok := a.Metadata["prefix"].(string); ok {
+		prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+		if prefix != "" && !strings.Contains(prefix, "/") {
+			a.Prefix = prefix
+		}
+	}
+	if proxyURL, ok := a.Metadata["proxy_url"].(string); ok && strings.TrimSpace(proxyURL) != "" {
+		a.ProxyURL = strings.TrimSpace(proxyURL)
+	}
+}
+
 func (a *Auth) ProxyInfo() string {
 	if a == nil {
 		return ""
@@ -8,10 +8,14 @@ import (
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/metrics"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/notify"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/pipeline"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 )
 
 // Builder constructs a Service instance with customizable providers.
@@ -47,6 +51,13 @@ type Builder struct {
 
 	// serverOptions contains additional server configuration options.
 	serverOptions []api.ServerOption
+
+	// pipelineHooks run around every executor call made by the core manager.
+	pipelineHooks []pipeline.Hook
+
+	// requestMiddleware and responseMiddleware decorate every payload translation.
+	requestMiddleware  []sdktranslator.RequestMiddleware
+	responseMiddleware []sdktranslator.ResponseMiddleware
 }
 
 // Hooks allows callers to plug into service lifecycle stages.
@@ -143,6 +154,35 @@ func (b *Builder) WithServerOptions(opts ...api.ServerOption) *Builder {
 	return b
 }
 
+// WithPipelineHook registers a hook invoked before and after every executor attempt and for
+// each streamed chunk. Hooks may rewrite the request, options and HTTP transport of an attempt.
+func (b *Builder) WithPipelineHook(hook pipeline.Hook) *Builder {
+	if hook != nil {
+		b.pipelineHooks = append(b.pipelineHooks, hook)
+	}
+	return b
+}
+
+// WithRequestTranslatorMiddleware registers middleware around every request translation.
+// Middleware applies only to the built service, which then translates with its own pipeline
+// over the default registry instead of sdktranslator.DefaultPipeline.
+func (b *Builder) WithRequestTranslatorMiddleware(mw sdktranslator.RequestMiddleware) *Builder {
+	if mw != nil {
+		b.requestMiddleware = append(b.requestMiddleware, mw)
+	}
+	return b
+}
+
+// WithResponseTranslatorMiddleware registers middleware around every response translation,
+// including each translated stream chunk. Like request middleware it applies only to the
+// built service.
+func (b *Builder) WithResponseTranslatorMiddleware(mw sdktranslator.ResponseMiddleware) *Builder {
+	if mw != nil {
+		b.responseMiddleware = append(b.responseMiddleware, mw)
+	}
+	return b
+}
+
 // WithLocalManagementPassword configures a password that is only accepted from localhost management requests.
 func (b *Builder) WithLocalManagementPassword(password string) *Builder {
 	if password == "" {
@@ -199,23 +239,37 @@ func (b *Builder) Build() (*Service, error) {
 			dirSetter.SetBaseDir(b.cfg.AuthDir)
 		}
 
-		strategy := ""
+		var routing config.RoutingConfig
 		if b.cfg != nil {
-			strategy = strings.ToLower(strings.TrimSpace(b.cfg.Routing.Strategy))
-		}
-		var selector coreauth.Selector
-		switch strategy {
-		case "fill-first", "fillfirst", "ff":
-			selector = &coreauth.FillFirstSelector{}
-		default:
-			selector = &coreauth.RoundRobinSelector{}
+			routing = b.cfg.Routing
 		}
-
-		coreManager = coreauth.NewManager(tokenStore, selector, nil)
+		coreManager = coreauth.NewManager(tokenStore, newRoutingSelector(normalizeRoutingStrategy(routing.Strategy), routing.Affinity), nil)
 	}
 	// Attach a default RoundTripper provider so providers can opt-in per-auth transports.
 	coreManager.SetRoundTripperProvider(newDefaultRoundTripperProvider())
 	coreManager.SetOAuthModelMappings(b.cfg.OAuthModelMappings)
+	coreManager.SetModelFallbacks(b.cfg.ModelFallbacks)
+	coreManager.SetHealthProbeConfig(b.cfg.HealthProbe)
+	// Feed auth lifecycle and result events into the Prometheus collector.
+	coreManager.AddHook(metrics.DefaultCollector())
+	// Turn credential and quota state transitions into webhook notifications.
+	coreManager.AddHook(notify.DefaultNotifier())
+	// Translator middleware goes into a pipeline owned by this service so building several
+	// services, or building twice, never stacks middleware on the shared default pipeline.
+	translatorPipeline := sdktranslator.DefaultPipeline()
+	if len(b.requestMiddleware) > 0 || len(b.responseMiddleware) > 0 {
+		translatorPipeline = sdktranslator.NewPipeline(sdktranslator.Default())
+		for _, mw := range b.requestMiddleware {
+			translatorPipeline.UseRequest(mw)
+		}
+		for _, mw := range b.responseMiddleware {
+			translatorPipeline.UseResponse(mw)
+		}
+		coreManager.SetTranslatorPipeline(translatorPipeline)
+	}
+	for _, hook := range b.pipelineHooks {
+		coreManager.AddExecutionHook(newPipelineExecutionHook(hook, translatorPipeline))
+	}
 
 	service := &Service{
 		cfg:            b.cfg,
@@ -231,3 +285,40 @@ func (b *Builder) Build() (*Service, error) {
 	}
 	return service, nil
 }
+
+// normalizeRoutingStrategy maps routing.strategy values and their aliases to canonical names.
+func normalizeRoutingStrategy(strategy string) string {
+	switch strings.ToLower(strings.TrimSpace(strategy)) {
+	case "fill-first", "fillfirst", "ff":
+		return "fill-first"
+	case "weighted", "weighted-round-robin", "wrr":
+		return "weighted"
+	case "least-in-flight", "least-inflight", "least-loaded", "lif":
+		return "least-in-flight"
+	case "latency", "latency-aware", "fastest":
+		return "latency"
+	case "sticky", "affinity", "session-affinity", "conversation-affinity":
+		return "sticky"
+	default:
+		return "round-robin"
+	}
+}
+
+// newRoutingSelector constructs the credential selector for a canonical strategy name.
+// affinity only applies to the sticky strategy.
+func newRoutingSelector(strategy string, affinity config.RoutingAffinityConfig) coreauth.Selector {
+	switch strategy {
+	case "fill-first":
+		return &coreauth.FillFirstSelector{}
+	case "weighted":
+		return &coreauth.WeightedRoundRobinSelector{}
+	case "least-in-flight":
+		return &coreauth.LeastInFlightSelector{}
+	case "latency":
+		return &coreauth.LatencyAwareSelector{}
+	case "sticky":
+		return coreauth.NewStickySelector(affinity.Header, affinity.MaxEntries, nil)
+	default:
+		return &coreauth.RoundRobinSelector{}
+	}
+}
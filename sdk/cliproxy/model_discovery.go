@@ -0,0 +1,290 @@
+package cliproxy
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultModelDiscoveryInterval = time.Hour
+	minModelDiscoveryInterval     = time.Minute
+	modelDiscoveryTimeout         = 45 * time.Second
+)
+
+// modelDiscovery keeps the models discovered upstream for each API-key credential. The results
+// are merged into the registry by registerModelsForAuth.
+type modelDiscovery struct {
+	mu      sync.RWMutex
+	cfg     config.ModelDiscoveryConfig
+	running bool
+	cancel  context.CancelFunc
+	models  map[string][]*ModelInfo
+}
+
+// configureModelDiscovery starts, restarts or stops the discovery loop to match cfg.
+func (s *Service) configureModelDiscovery(cfg config.ModelDiscoveryConfig) {
+	d := &s.discovery
+	d.mu.Lock()
+	if d.running == cfg.Enable && reflect.DeepEqual(d.cfg, cfg) {
+		d.mu.Unlock()
+		return
+	}
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+	d.cfg = cfg
+	d.running = cfg.Enable
+	var stale []string
+	if !cfg.Enable {
+		for id := range d.models {
+			stale = append(stale, id)
+		}
+		d.models = nil
+	}
+	var ctx context.Context
+	if cfg.Enable {
+		ctx, d.cancel = context.WithCancel(context.Background())
+	}
+	d.mu.Unlock()
+
+	s.reregisterModels(stale)
+	if !cfg.Enable {
+		return
+	}
+	interval := time.Duration(cfg.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultModelDiscoveryInterval
+	}
+	interval = max(interval, minModelDiscoveryInterval)
+	log.Infof("model discovery started (interval=%s)", interval)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.discoverModels(ctx, cfg)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopModelDiscovery stops the discovery loop without touching registered models.
+func (s *Service) stopModelDiscovery() {
+	d := &s.discovery
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+	d.running = false
+}
+
+// discoverModels runs one discovery round over every eligible credential. A failed listing keeps
+// the previous result so a transient upstream error does not drop models.
+func (s *Service) discoverModels(ctx context.Context, cfg config.ModelDiscoveryConfig) {
+	if s.coreManager == nil {
+		return
+	}
+	s.cfgMu.RLock()
+	appCfg := s.cfg
+	s.cfgMu.RUnlock()
+
+	seen := make(map[string]struct{})
+	for _, auth := range s.coreManager.List() {
+		if ctx.Err() != nil {
+			return
+		}
+		provider := modelDiscoveryProvider(auth, cfg.Providers)
+		if provider == "" || auth.Disabled {
+			continue
+		}
+		seen[auth.ID] = struct{}{}
+		fetchCtx, cancel := context.WithTimeout(ctx, modelDiscoveryTimeout)
+		models, err := executor.FetchUpstreamModels(fetchCtx, auth, appCfg, provider)
+		cancel()
+		if err != nil {
+			log.Debugf("model discovery: listing models for %s (%s) failed: %v", auth.ID, provider, err)
+			continue
+		}
+		models = filterDiscoveredModels(models, cfg.Include, cfg.Exclude)
+		if !s.storeDiscoveredModels(auth.ID, models) {
+			continue
+		}
+		log.Debugf("model discovery: %s (%s) lists %d models", auth.ID, provider, len(models))
+		s.registerModelsForAuth(auth)
+	}
+
+	d := &s.discovery
+	var stale []string
+	d.mu.Lock()
+	for id := range d.models {
+		if _, ok := seen[id]; !ok {
+			delete(d.models, id)
+			stale = append(stale, id)
+		}
+	}
+	d.mu.Unlock()
+	s.reregisterModels(stale)
+}
+
+// storeDiscoveredModels records models for authID and reports whether the set of IDs changed.
+func (s *Service) storeDiscoveredModels(authID string, models []*ModelInfo) bool {
+	d := &s.discovery
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	previous, ok := d.models[authID]
+	if ok && sameModelIDs(previous, models) {
+		return false
+	}
+	if d.models == nil {
+		d.models = make(map[string][]*ModelInfo)
+	}
+	d.models[authID] = models
+	return true
+}
+
+// discoveredModels returns the models last discovered for authID.
+func (s *Service) discoveredModels(authID string) []*ModelInfo {
+	d := &s.discovery
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.models[authID]
+}
+
+// forgetDiscoveredModels drops the discovery result of a removed credential.
+func (s *Service) forgetDiscoveredModels(authID string) {
+	d := &s.discovery
+	d.mu.Lock()
+	delete(d.models, authID)
+	d.mu.Unlock()
+}
+
+func (s *Service) reregisterModels(authIDs []string) {
+	if s.coreManager == nil {
+		return
+	}
+	for _, id := range authIDs {
+		if auth, ok := s.coreManager.GetByID(id); ok {
+			s.registerModelsForAuth(auth)
+		}
+	}
+}
+
+// modelDiscoveryProvider returns the list-models dialect for auth, or "" when the credential is
+// not eligible. Only API-key credentials are discovered; OAuth catalogs stay built in.
+func modelDiscoveryProvider(auth *coreauth.Auth, allowed []string) string {
+	if auth == nil || auth.Attributes == nil {
+		return ""
+	}
+	provider := strings.ToLower(strings.TrimSpace(auth.Provider))
+	hasKey := strings.TrimSpace(auth.Attributes["api_key"]) != ""
+	baseURL := strings.TrimSpace(auth.Attributes["base_url"])
+	if _, _, isCompat := openAICompatInfoFromAuth(auth); isCompat {
+		if baseURL == "" {
+			return ""
+		}
+		provider = "openai-compatibility"
+	} else {
+		switch provider {
+		case "claude", "gemini":
+			if !hasKey {
+				return ""
+			}
+		case "codex":
+			if !hasKey || baseURL == "" {
+				return ""
+			}
+		default:
+			return ""
+		}
+	}
+	if len(allowed) > 0 {
+		for _, name := range allowed {
+			if strings.EqualFold(strings.TrimSpace(name), provider) {
+				return provider
+			}
+		}
+		return ""
+	}
+	return provider
+}
+
+// filterDiscoveredModels applies the include and exclude wildcard patterns.
+func filterDiscoveredModels(models []*ModelInfo, include, exclude []string) []*ModelInfo {
+	models = applyExcludedModels(models, exclude)
+	patterns := make([]string, 0, len(include))
+	for _, item := range include {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			patterns = append(patterns, strings.ToLower(trimmed))
+		}
+	}
+	if len(patterns) == 0 {
+		return models
+	}
+	filtered := make([]*ModelInfo, 0, len(models))
+	for _, model := range models {
+		id := strings.ToLower(strings.TrimSpace(model.ID))
+		for _, pattern := range patterns {
+			if matchWildcard(pattern, id) {
+				filtered = append(filtered, model)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// mergeDiscoveredModels appends discovered models whose IDs are not already listed, so built-in
+// and configured entries keep their richer metadata.
+func mergeDiscoveredModels(models, discovered []*ModelInfo) []*ModelInfo {
+	if len(discovered) == 0 {
+		return models
+	}
+	seen := make(map[string]struct{}, len(models)+len(discovered))
+	out := make([]*ModelInfo, 0, len(models)+len(discovered))
+	for _, model := range models {
+		if model == nil {
+			continue
+		}
+		seen[strings.ToLower(model.ID)] = struct{}{}
+		out = append(out, model)
+	}
+	for _, model := range discovered {
+		if model == nil {
+			continue
+		}
+		key := strings.ToLower(model.ID)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		clone := *model
+		out = append(out, &clone)
+	}
+	return out
+}
+
+func sameModelIDs(a, b []*ModelInfo) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+	return true
+}
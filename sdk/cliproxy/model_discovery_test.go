@@ -0,0 +1,124 @@
+package cliproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestFilterDiscoveredModels(t *testing.T) {
+	models := []*ModelInfo{{ID: "gpt-5"}, {ID: "gpt-5-mini"}, {ID: "o3"}, {ID: "text-embedding-3"}}
+	tests := []struct {
+		name             string
+		include, exclude []string
+		want             []string
+	}{
+		{name: "no filters", want: []string{"gpt-5", "gpt-5-mini", "o3", "text-embedding-3"}},
+		{name: "include", include: []string{"GPT-*", " o3 "}, want: []string{"gpt-5", "gpt-5-mini", "o3"}},
+		{name: "exclude", exclude: []string{"*-mini", "text-*"}, want: []string{"gpt-5", "o3"}},
+		{name: "exclude wins over include", include: []string{"gpt-*"}, exclude: []string{"*-mini"}, want: []string{"gpt-5"}},
+		{name: "blank include keeps all", include: []string{" "}, want: []string{"gpt-5", "gpt-5-mini", "o3", "text-embedding-3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterDiscoveredModels(models, tt.include, tt.exclude)
+			if len(got) != len(tt.want) {
+				t.Fatalf("filterDiscoveredModels() = %d models, want %v", len(got), tt.want)
+			}
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Fatalf("model %d = %q, want %q", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestDiscoverModelsKeepsPreviousOnErrorAndPrunesStale(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"id":"discovered-a"},{"id":"discovered-b"}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	manager := coreauth.NewManager(nil, nil, nil)
+	auth := &coreauth.Auth{ID: "discovery-codex-key", Provider: "codex", Attributes: map[string]string{
+		"api_key":   "sk-test",
+		"base_url":  server.URL,
+		"auth_kind": "apikey",
+	}}
+	if _, err := manager.Register(ctx, auth); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	defer GlobalModelRegistry().UnregisterClient(auth.ID)
+
+	s := &Service{cfg: &config.Config{}, coreManager: manager}
+	cfg := config.ModelDiscoveryConfig{Enable: true, Exclude: []string{"*-b"}}
+
+	s.discoverModels(ctx, cfg)
+	if got := s.discoveredModels(auth.ID); len(got) != 1 || got[0].ID != "discovered-a" {
+		t.Fatalf("discovered models = %+v, want discovered-a", got)
+	}
+
+	failing.Store(true)
+	s.discoverModels(ctx, cfg)
+	if got := s.discoveredModels(auth.ID); len(got) != 1 || got[0].ID != "discovered-a" {
+		t.Fatalf("discovered models after a failed listing = %+v, want the previous result", got)
+	}
+
+	disabled := auth.Clone()
+	disabled.Disabled = true
+	if _, err := manager.Update(ctx, disabled); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	s.discoverModels(ctx, cfg)
+	if got := s.discoveredModels(auth.ID); got != nil {
+		t.Fatalf("discovered models of a disabled credential = %+v, want none", got)
+	}
+}
+
+func TestRegisterModelsForAuthAppliesCatalogToDiscoveredModels(t *testing.T) {
+	catalog, err := registry.ParseModelCatalog([]byte(`models:
+  - id: discovered-a
+    display-name: Discovered A
+    context-length: 64000
+`))
+	if err != nil {
+		t.Fatalf("ParseModelCatalog() error = %v", err)
+	}
+	registry.SetModelCatalog(catalog)
+	defer registry.SetModelCatalog(nil)
+
+	auth := &coreauth.Auth{ID: "catalog-codex-key", Provider: "codex", Attributes: map[string]string{
+		"api_key":   "sk-test",
+		"auth_kind": "apikey",
+	}}
+	defer GlobalModelRegistry().UnregisterClient(auth.ID)
+
+	s := &Service{cfg: &config.Config{}}
+	s.storeDiscoveredModels(auth.ID, []*ModelInfo{{ID: "discovered-a", Object: "model", Type: "codex", DisplayName: "discovered-a"}})
+	s.registerModelsForAuth(auth)
+
+	for _, model := range registry.GetGlobalRegistry().GetModelsForClient(auth.ID) {
+		if model.ID != "discovered-a" {
+			continue
+		}
+		if model.DisplayName != "Discovered A" || model.ContextLength != 64000 {
+			t.Fatalf("discovered model = %+v, want catalog metadata applied", model)
+		}
+		return
+	}
+	t.Fatalf("discovered-a is not registered")
+}
@@ -0,0 +1,63 @@
+package cliproxy
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/pipeline"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// pipelineExecutionHook adapts a pipeline.Hook to the core manager's execution hooks. Each
+// attempt gets its own pipeline.Context so state set in BeforeExecute is visible to the
+// stream and completion callbacks of the same attempt.
+type pipelineExecutionHook struct {
+	hook       pipeline.Hook
+	translator *sdktranslator.Pipeline
+	contexts   sync.Map // *coreauth.ExecutionAttempt -> *pipeline.Context
+}
+
+func newPipelineExecutionHook(hook pipeline.Hook, translator *sdktranslator.Pipeline) *pipelineExecutionHook {
+	return &pipelineExecutionHook{hook: hook, translator: translator}
+}
+
+// BeforeExecute implements coreauth.ExecutionHook.
+func (h *pipelineExecutionHook) BeforeExecute(ctx context.Context, attempt *coreauth.ExecutionAttempt) {
+	execCtx := &pipeline.Context{
+		Request:    attempt.Request,
+		Options:    attempt.Options,
+		Auth:       attempt.Auth,
+		Translator: h.translator,
+	}
+	if attempt.RoundTripper != nil {
+		execCtx.HTTPClient = &http.Client{Transport: attempt.RoundTripper}
+	}
+	h.hook.BeforeExecute(ctx, execCtx)
+	attempt.Request = execCtx.Request
+	attempt.Options = execCtx.Options
+	if execCtx.HTTPClient != nil && execCtx.HTTPClient.Transport != nil {
+		attempt.RoundTripper = execCtx.HTTPClient.Transport
+	}
+	h.contexts.Store(attempt, execCtx)
+}
+
+// AfterExecute implements coreauth.ExecutionHook.
+func (h *pipelineExecutionHook) AfterExecute(ctx context.Context, attempt *coreauth.ExecutionAttempt, resp cliproxyexecutor.Response, err error) {
+	value, ok := h.contexts.LoadAndDelete(attempt)
+	if !ok {
+		return
+	}
+	h.hook.AfterExecute(ctx, value.(*pipeline.Context), resp, err)
+}
+
+// OnStreamChunk implements coreauth.ExecutionHook.
+func (h *pipelineExecutionHook) OnStreamChunk(ctx context.Context, attempt *coreauth.ExecutionAttempt, chunk cliproxyexecutor.StreamChunk) {
+	value, ok := h.contexts.Load(attempt)
+	if !ok {
+		return
+	}
+	h.hook.OnStreamChunk(ctx, value.(*pipeline.Context), chunk)
+}
@@ -86,6 +86,9 @@ type Service struct {
 
 	// wsGateway manages websocket Gemini providers.
 	wsGateway *wsrelay.Manager
+
+	// discovery caches models listed by upstream providers for API-key credentials.
+	discovery modelDiscovery
 }
 
 // RegisterUsagePlugin registers a usage plugin on the global usage manager.
@@ -300,6 +303,7 @@ func (s *Service) applyCoreAuthRemoval(ctx context.Context, id string) {
 		return
 	}
 	GlobalModelRegistry().UnregisterClient(id)
+	s.forgetDiscoveredModels(id)
 	if existing, ok := s.coreManager.GetByID(id); ok && existing != nil {
 		existing.Disabled = true
 		existing.Status = coreauth.StatusDisabled
@@ -506,10 +510,10 @@ func (s *Service) Run(ctx context.Context) error {
 
 	var watcherWrapper *WatcherWrapper
 	reloadCallback := func(newCfg *config.Config) {
-		previousStrategy := ""
+		var previousRouting config.RoutingConfig
 		s.cfgMu.RLock()
 		if s.cfg != nil {
-			previousStrategy = strings.ToLower(strings.TrimSpace(s.cfg.Routing.Strategy))
+			previousRouting = s.cfg.Routing
 		}
 		s.cfgMu.RUnlock()
 
@@ -522,26 +526,11 @@ func (s *Service) Run(ctx context.Context) error {
 			return
 		}
 
-		nextStrategy := strings.ToLower(strings.TrimSpace(newCfg.Routing.Strategy))
-		normalizeStrategy := func(strategy string) string {
-			switch strategy {
-			case "fill-first", "fillfirst", "ff":
-				return "fill-first"
-			default:
-				return "round-robin"
-			}
-		}
-		previousStrategy = normalizeStrategy(previousStrategy)
-		nextStrategy = normalizeStrategy(nextStrategy)
-		if s.coreManager != nil && previousStrategy != nextStrategy {
-			var selector coreauth.Selector
-			switch nextStrategy {
-			case "fill-first":
-				selector = &coreauth.FillFirstSelector{}
-			default:
-				selector = &coreauth.RoundRobinSelector{}
-			}
-			s.coreManager.SetSelector(selector)
+		previousStrategy := normalizeRoutingStrategy(previousRouting.Strategy)
+		nextStrategy := normalizeRoutingStrategy(newCfg.Routing.Strategy)
+		affinityChanged := nextStrategy == "sticky" && previousRouting.Affinity != newCfg.Routing.Affinity
+		if s.coreManager != nil && (previousStrategy != nextStrategy || affinityChanged) {
+			s.coreManager.SetSelector(newRoutingSelector(nextStrategy, newCfg.Routing.Affinity))
 			log.Infof("routing strategy updated to %s", nextStrategy)
 		}
 
@@ -554,8 +543,11 @@ func (s *Service) Run(ctx context.Context) error {
 		s.cfgMu.Unlock()
 		if s.coreManager != nil {
 			s.coreManager.SetOAuthModelMappings(newCfg.OAuthModelMappings)
+			s.coreManager.SetModelFallbacks(newCfg.ModelFallbacks)
+			s.coreManager.SetHealthProbeConfig(newCfg.HealthProbe)
 		}
 		s.rebindExecutors()
+		s.configureModelDiscovery(newCfg.ModelDiscovery)
 	}
 
 	watcherWrapper, err = s.watcherFactory(s.configPath, s.cfg.AuthDir, reloadCallback)
@@ -581,6 +573,8 @@ func (s *Service) Run(ctx context.Context) error {
 		interval := 15 * time.Minute
 		s.coreManager.StartAutoRefresh(context.Background(), interval)
 		log.Infof("core auth auto-refresh started (interval=%s)", interval)
+		s.coreManager.StartHealthProbe(context.Background())
+		s.configureModelDiscovery(s.cfg.ModelDiscovery)
 	}
 
 	select {
@@ -618,7 +612,9 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		}
 		if s.coreManager != nil {
 			s.coreManager.StopAutoRefresh()
+			s.coreManager.StopHealthProbe()
 		}
+		s.stopModelDiscovery()
 		if s.watcher != nil {
 			if err := s.watcher.Stop(); err != nil {
 				log.Errorf("failed to stop file watcher: %v", err)
@@ -827,6 +823,12 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 							DisplayName: modelID,
 						})
 					}
+					ms = mergeDiscoveredModels(ms, s.discoveredModels(a.ID))
+					ms = registry.ApplyModelCatalog(strings.ToLower(compat.Name), ms)
+					for _, m := range ms {
+						// Catalog-added entries default their type to the provider key.
+						m.Type = "openai-compatibility"
+					}
 					// Register and return
 					if len(ms) > 0 {
 						if providerKey == "" {
@@ -847,6 +849,9 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 			}
 		}
 	}
+	// The catalog applies after discovery so its metadata also covers discovered models.
+	models = registry.ApplyModelCatalog(provider, mergeDiscoveredModels(models, s.discoveredModels(a.ID)))
+	models = applyExcludedModels(models, excluded)
 	models = applyOAuthModelMappings(s.cfg, provider, authKind, models)
 	if len(models) > 0 {
 		key := provider
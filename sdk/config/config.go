@@ -9,14 +9,24 @@ import internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 type SDKConfig = internalconfig.SDKConfig
 type AccessConfig = internalconfig.AccessConfig
 type AccessProvider = internalconfig.AccessProvider
+type APIKeyLimit = internalconfig.APIKeyLimit
+type ClientKey = internalconfig.ClientKey
 
 type Config = internalconfig.Config
 
 type StreamingConfig = internalconfig.StreamingConfig
+type ResponseCacheConfig = internalconfig.ResponseCacheConfig
 type TLSConfig = internalconfig.TLSConfig
+type MetricsConfig = internalconfig.MetricsConfig
+type UsagePersistenceConfig = internalconfig.UsagePersistenceConfig
+type ModelPrice = internalconfig.ModelPrice
 type RemoteManagement = internalconfig.RemoteManagement
 type AmpCode = internalconfig.AmpCode
 type ModelNameMapping = internalconfig.ModelNameMapping
+type ModelFallback = internalconfig.ModelFallback
+type RoutingConfig = internalconfig.RoutingConfig
+type RoutingAffinityConfig = internalconfig.RoutingAffinityConfig
+type ModelDiscoveryConfig = internalconfig.ModelDiscoveryConfig
 type PayloadConfig = internalconfig.PayloadConfig
 type PayloadRule = internalconfig.PayloadRule
 type PayloadModelRule = internalconfig.PayloadModelRule
@@ -42,6 +52,10 @@ func MakeInlineAPIKeyProvider(keys []string) *AccessProvider {
 	return internalconfig.MakeInlineAPIKeyProvider(keys)
 }
 
+func MakeInlineAccessProvider(cfg *SDKConfig) *AccessProvider {
+	return internalconfig.MakeInlineAccessProvider(cfg)
+}
+
 func LoadConfig(configFile string) (*Config, error) { return internalconfig.LoadConfig(configFile) }
 
 func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
@@ -16,3 +16,6 @@ type FileRequestLogger = internallogging.FileRequestLogger
 func NewFileRequestLogger(enabled bool, logsDir string, configDir string) *FileRequestLogger {
 	return internallogging.NewFileRequestLogger(enabled, logsDir, configDir)
 }
+
+// RequestLogMetadata carries request details recorded in the request log index.
+type RequestLogMetadata = internallogging.RequestLogMetadata
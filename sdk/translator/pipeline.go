@@ -1,6 +1,9 @@
 package translator
 
-import "context"
+import (
+	"context"
+	"sync"
+)
 
 // RequestEnvelope represents a request in the translation pipeline.
 type RequestEnvelope struct {
@@ -33,6 +36,7 @@ type ResponseHandler func(ctx context.Context, resp ResponseEnvelope) (ResponseE
 
 // Pipeline orchestrates request/response transformation with middleware support.
 type Pipeline struct {
+	mu                 sync.RWMutex
 	registry           *Registry
 	requestMiddleware  []RequestMiddleware
 	responseMiddleware []ResponseMiddleware
@@ -46,17 +50,48 @@ func NewPipeline(registry *Registry) *Pipeline {
 	return &Pipeline{registry: registry}
 }
 
+var defaultPipeline = NewPipeline(defaultRegistry)
+
+// DefaultPipeline exposes the pipeline over the default registry used by the built-in executors.
+func DefaultPipeline() *Pipeline {
+	return defaultPipeline
+}
+
+type pipelineContextKey struct{}
+
+// WithPipeline returns a context whose translations run through p instead of the default pipeline.
+func WithPipeline(ctx context.Context, p *Pipeline) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, pipelineContextKey{}, p)
+}
+
+// PipelineFromContext returns the pipeline attached with WithPipeline, or the default pipeline.
+func PipelineFromContext(ctx context.Context) *Pipeline {
+	if ctx != nil {
+		if p, ok := ctx.Value(pipelineContextKey{}).(*Pipeline); ok && p != nil {
+			return p
+		}
+	}
+	return defaultPipeline
+}
+
 // UseRequest adds request middleware executed in registration order.
 func (p *Pipeline) UseRequest(mw RequestMiddleware) {
 	if mw != nil {
+		p.mu.Lock()
 		p.requestMiddleware = append(p.requestMiddleware, mw)
+		p.mu.Unlock()
 	}
 }
 
 // UseResponse adds response middleware executed in registration order.
 func (p *Pipeline) UseResponse(mw ResponseMiddleware) {
 	if mw != nil {
+		p.mu.Lock()
 		p.responseMiddleware = append(p.responseMiddleware, mw)
+		p.mu.Unlock()
 	}
 }
 
@@ -69,9 +104,13 @@ func (p *Pipeline) TranslateRequest(ctx context.Context, from, to Format, req Re
 		return input, nil
 	}
 
+	p.mu.RLock()
+	middleware := p.requestMiddleware
+	p.mu.RUnlock()
+
 	handler := terminal
-	for i := len(p.requestMiddleware) - 1; i >= 0; i-- {
-		mw := p.requestMiddleware[i]
+	for i := len(middleware) - 1; i >= 0; i-- {
+		mw := middleware[i]
 		next := handler
 		handler = func(ctx context.Context, r RequestEnvelope) (RequestEnvelope, error) {
 			return mw(ctx, r, next)
@@ -93,9 +132,13 @@ func (p *Pipeline) TranslateResponse(ctx context.Context, from, to Format, resp
 		return input, nil
 	}
 
+	p.mu.RLock()
+	middleware := p.responseMiddleware
+	p.mu.RUnlock()
+
 	handler := terminal
-	for i := len(p.responseMiddleware) - 1; i >= 0; i-- {
-		mw := p.responseMiddleware[i]
+	for i := len(middleware) - 1; i >= 0; i-- {
+		mw := middleware[i]
 		next := handler
 		handler = func(ctx context.Context, r ResponseEnvelope) (ResponseEnvelope, error) {
 			return mw(ctx, r, next)